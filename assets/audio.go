@@ -0,0 +1,9 @@
+package assets
+
+//AudioAsset represents an audio clip loaded from the manifest
+type AudioAsset struct {
+	ID   uint64
+	Name string
+	Path string
+	Hash string
+}
@@ -0,0 +1,31 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+
+	"github.com/go-errors/errors"
+	"github.com/golang/protobuf/proto"
+)
+
+func hashBytes(b []byte) string {
+	s := sha256.Sum256(b)
+	return hex.EncodeToString(s[:])
+}
+
+func hashFile(p string) (string, error) {
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return "", err
+	}
+	return hashBytes(b), nil
+}
+
+func hashMessage(m proto.Message) (string, error) {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return "", errors.Wrap(err, 0)
+	}
+	return hashBytes(b), nil
+}
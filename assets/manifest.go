@@ -0,0 +1,109 @@
+package assets
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-errors/errors"
+)
+
+type textureEntry struct {
+	Name    string   `json:"name"`
+	File    string   `json:"file"`
+	Recipe  string   `json:"recipe"`
+	Pattern string   `json:"pattern"`
+	Size    int      `json:"size"`
+	Colors  []string `json:"colors"`
+	Scale   float64  `json:"scale"`
+	Octaves int      `json:"octaves"`
+	Seed    int64    `json:"seed"`
+}
+
+type atlasEntry struct {
+	Name     string   `json:"name"`
+	Textures []string `json:"textures"`
+	Padding  int      `json:"padding"`
+}
+
+type materialEntry struct {
+	Name       string  `json:"name"`
+	Texture    string  `json:"texture"`
+	Color      string  `json:"color"`
+	Emissive   string  `json:"emissive"`
+	Roughness  float32 `json:"roughness"`
+	Metalness  float32 `json:"metalness"`
+	Scale      float32 `json:"scale"`
+	DashSize   float32 `json:"dashSize"`
+	GapSize    float32 `json:"gapSize"`
+	Type       string  `json:"type"`
+	Side       string  `json:"side"`
+	Wireframe  bool    `json:"wireframe"`
+	FlatShaded bool    `json:"flatShaded"`
+	Density    float64 `json:"density"`
+}
+
+type meshEntry struct {
+	Name      string       `json:"name"`
+	File      string       `json:"file"`
+	Primitive string       `json:"primitive"`
+	Size      []float64    `json:"size"`
+	Radius    float64      `json:"radius"`
+	Height    float64      `json:"height"`
+	Length    float64      `json:"length"`
+	Tube      float64      `json:"tube"`
+	Depth     float64      `json:"depth"`
+	Segments  int          `json:"segments"`
+	Rings     int          `json:"rings"`
+	Detail    int          `json:"detail"`
+	Points    [][2]float64 `json:"points"`
+	Plant     string       `json:"plant"`
+	Building  string       `json:"building"`
+	Seed      int64        `json:"seed"`
+	Operation string       `json:"operation"`
+	Operands  []string     `json:"operands"`
+	Offsets   [][3]float64 `json:"offsets"`
+	NoNormals bool         `json:"noNormals"`
+	LODs      int          `json:"lods"`
+	Material  string       `json:"material"`
+}
+
+type audioEntry struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+}
+
+type manifest struct {
+	Textures  []textureEntry  `json:"textures"`
+	Atlases   []atlasEntry    `json:"atlases"`
+	Materials []materialEntry `json:"materials"`
+	Meshes    []meshEntry     `json:"meshes"`
+	Audio     []audioEntry    `json:"audio"`
+}
+
+//readManifest merges every manifest file in dir, in file name order
+func readManifest(dir string) (*manifest, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	sort.Strings(files)
+	m := new(manifest)
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+		k := new(manifest)
+		if err := json.Unmarshal(b, k); err != nil {
+			return nil, errors.Errorf("%s: %v", f, err)
+		}
+		m.Textures = append(m.Textures, k.Textures...)
+		m.Atlases = append(m.Atlases, k.Atlases...)
+		m.Materials = append(m.Materials, k.Materials...)
+		m.Meshes = append(m.Meshes, k.Meshes...)
+		m.Audio = append(m.Audio, k.Audio...)
+	}
+	return m, nil
+}
@@ -2,6 +2,8 @@ package assets
 
 import (
 	"goworld/pb"
+
+	"github.com/go-errors/errors"
 )
 
 //MaterialPhysicalProperties contains the physical properties of a material
@@ -9,27 +11,42 @@ type MaterialPhysicalProperties struct {
 	Density float64
 }
 
-var physicalPropertiesIDMap = map[uint64]MaterialPhysicalProperties{
-	1: MaterialPhysicalProperties{
-		Density: 1,
-	},
-}
-
-var materialIDMap = map[uint64]*pb.Material{
-	1: &pb.Material{
-		FlatShaded: true,
-		Side:       pb.Material_DOUBLE_SIDE,
-		TextureID:  1,
-	},
+//MaterialAsset represents a material loaded from the manifest
+type MaterialAsset struct {
+	ID       uint64
+	Name     string
+	Material *pb.Material
+	Physical MaterialPhysicalProperties
+	Hash     string
+	Texture  *TextureAsset
 }
 
-//Material represents the material identifiers
-var Material = struct {
-	Stone uint64
-	ByID  func(uint64) *pb.Material
-}{
-	Stone: 1,
-	ByID: func(id uint64) *pb.Material {
-		return materialIDMap[id]
-	},
+func buildMaterial(e materialEntry, textureID uint64) (*pb.Material, error) {
+	m := &pb.Material{
+		Color:      e.Color,
+		Emissive:   e.Emissive,
+		Roughness:  e.Roughness,
+		Metalness:  e.Metalness,
+		Scale:      e.Scale,
+		DashSize:   e.DashSize,
+		GapSize:    e.GapSize,
+		TextureID:  textureID,
+		Wireframe:  e.Wireframe,
+		FlatShaded: e.FlatShaded,
+	}
+	if e.Type != "" {
+		t, ok := pb.Material_Type_value[e.Type]
+		if !ok {
+			return nil, errors.Errorf("material %s: unknown type %q", e.Name, e.Type)
+		}
+		m.Type = pb.Material_Type(t)
+	}
+	if e.Side != "" {
+		s, ok := pb.Material_Side_value[e.Side]
+		if !ok {
+			return nil, errors.Errorf("material %s: unknown side %q", e.Name, e.Side)
+		}
+		m.Side = pb.Material_Side(s)
+	}
+	return m, nil
 }
@@ -0,0 +1,228 @@
+package assets
+
+import (
+	"goworld/gen"
+	"goworld/pb"
+	"path/filepath"
+
+	"github.com/go-errors/errors"
+)
+
+//MeshAsset represents a mesh loaded from the manifest.
+//Path is empty for procedurally generated meshes.
+type MeshAsset struct {
+	ID    uint64
+	Name  string
+	Path  string
+	Mesh  *pb.Mesh
+	Shape model.Shape
+	Hash  string
+	LODs  []MeshLevel
+}
+
+//MeshLevel is a simplified version of a mesh, LODs[0] being level 1
+type MeshLevel struct {
+	Mesh *pb.Mesh
+	Hash string
+}
+
+//Level returns the mesh and hash for a level of detail, where 0 is the full mesh.
+//Levels beyond the coarsest available return the coarsest one.
+func (a *MeshAsset) Level(lod uint32) (*pb.Mesh, string) {
+	if lod == 0 || len(a.LODs) == 0 {
+		return a.Mesh, a.Hash
+	}
+	l := a.LODs[min(int(lod), len(a.LODs))-1]
+	return l.Mesh, l.Hash
+}
+
+func (a *MeshAsset) buildLODs(count int) error {
+	return a.setLODs(model.LODs(a.Mesh, count))
+}
+
+func (a *MeshAsset) setLODs(levels []*pb.Mesh) error {
+	for _, l := range levels {
+		h, err := hashMessage(l)
+		if err != nil {
+			return err
+		}
+		a.LODs = append(a.LODs, MeshLevel{Mesh: l, Hash: h})
+	}
+	return nil
+}
+
+func min(a, b int) int {
+	if a <= b {
+		return a
+	}
+	return b
+}
+
+func orDefault(v int, d int) int {
+	if v <= 0 {
+		return d
+	}
+	return v
+}
+
+var csgOperations = map[string]func(a, b *pb.Mesh) (*pb.Mesh, model.Shape){
+	"union":        model.Union,
+	"difference":   model.Difference,
+	"intersection": model.Intersection,
+}
+
+//buildCSG folds the operation over the operands, which must be meshes defined earlier in the manifest
+func buildCSG(e meshEntry, meshes func(string) *MeshAsset) (*pb.Mesh, error) {
+	op, ok := csgOperations[e.Operation]
+	if !ok {
+		return nil, errors.Errorf("mesh %s: unknown operation %q", e.Name, e.Operation)
+	}
+	if len(e.Operands) < 2 {
+		return nil, errors.Errorf("mesh %s: %s needs at least 2 operands", e.Name, e.Operation)
+	}
+	if len(e.Offsets) != 0 && len(e.Offsets) != len(e.Operands) {
+		return nil, errors.Errorf("mesh %s: expected an offset for each operand", e.Name)
+	}
+	var m *pb.Mesh
+	for i, name := range e.Operands {
+		o := meshes(name)
+		if o == nil {
+			return nil, errors.Errorf("mesh %s: unknown operand %q", e.Name, name)
+		}
+		k := o.Mesh
+		if len(e.Offsets) > 0 {
+			k = model.Translate(k, e.Offsets[i][0], e.Offsets[i][1], e.Offsets[i][2])
+		}
+		if m == nil {
+			m = k
+			continue
+		}
+		m, _ = op(m, k)
+	}
+	return m, nil
+}
+
+//primitiveDimensions names the dimensions each primitive needs positive
+var primitiveDimensions = map[string][]string{
+	"box":       {"size"},
+	"sphere":    {"radius"},
+	"icosphere": {"radius"},
+	"cylinder":  {"radius", "height"},
+	"cone":      {"radius", "height"},
+	"capsule":   {"radius"},
+	"torus":     {"radius", "tube"},
+	"grid":      {"size"},
+	"extrude":   {"depth"},
+}
+
+//checkPrimitive rejects the dimensions a primitive would come out degenerate with. Segments,
+//rings and detail left out take their defaults, but given ones must be enough for a solid.
+func checkPrimitive(e meshEntry) error {
+	values := map[string][]float64{
+		"size":   e.Size,
+		"radius": {e.Radius},
+		"height": {e.Height},
+		"tube":   {e.Tube},
+		"depth":  {e.Depth},
+	}
+	for _, d := range primitiveDimensions[e.Primitive] {
+		for _, v := range values[d] {
+			if !(v > 0) {
+				return errors.Errorf("mesh %s: %s %s must be positive, got %v", e.Name, e.Primitive, d, v)
+			}
+		}
+	}
+	if e.Length < 0 {
+		return errors.Errorf("mesh %s: negative length %v", e.Name, e.Length)
+	}
+	//a grid is flat, one cell across is enough
+	minSegments, minRings := 3, 2
+	if e.Primitive == "grid" {
+		minSegments, minRings = 1, 1
+	}
+	if e.Segments < 0 || e.Segments > 0 && e.Segments < minSegments {
+		return errors.Errorf("mesh %s: at least %d segments are needed, got %d", e.Name, minSegments, e.Segments)
+	}
+	if e.Rings < 0 || e.Rings > 0 && e.Rings < minRings {
+		return errors.Errorf("mesh %s: at least %d rings are needed, got %d", e.Name, minRings, e.Rings)
+	}
+	if e.Detail < 0 {
+		return errors.Errorf("mesh %s: negative detail %d", e.Name, e.Detail)
+	}
+	return nil
+}
+
+func buildMesh(e meshEntry, root string, meshes func(string) *MeshAsset) (*MeshAsset, error) {
+	a := &MeshAsset{Name: e.Name, Shape: model.Shape{Type: pb.Body_MESH}}
+	if e.File == "" && e.Plant == "" && e.Building == "" && e.Operation == "" {
+		if err := checkPrimitive(e); err != nil {
+			return nil, err
+		}
+	}
+	segments, rings := orDefault(e.Segments, 16), orDefault(e.Rings, 8)
+	switch {
+	case e.File != "":
+		a.Path = filepath.Join(root, e.File)
+		m, err := model.Load(a.Path)
+		if err != nil {
+			return nil, errors.Errorf("mesh %s: %v", e.Name, err)
+		}
+		a.Mesh = m
+	case e.Plant != "":
+		l, ok := model.Plants[e.Plant]
+		if !ok {
+			return nil, errors.Errorf("mesh %s: unknown plant %q", e.Name, e.Plant)
+		}
+		a.Mesh, a.Shape = model.Plant(l, e.Seed)
+	case e.Building != "":
+		b, ok := model.Buildings[e.Building]
+		if !ok {
+			return nil, errors.Errorf("mesh %s: unknown building %q", e.Name, e.Building)
+		}
+		a.Mesh, a.Shape = model.Building(b, e.Seed)
+	case e.Operation != "":
+		m, err := buildCSG(e, meshes)
+		if err != nil {
+			return nil, err
+		}
+		a.Mesh = m
+	case e.Primitive == "box":
+		if len(e.Size) != 3 {
+			return nil, errors.Errorf("mesh %s: box needs a size of 3 components", e.Name)
+		}
+		a.Mesh, a.Shape = model.Box(e.Size[0], e.Size[1], e.Size[2])
+	case e.Primitive == "sphere":
+		a.Mesh, a.Shape = model.Sphere(e.Radius, segments, rings)
+	case e.Primitive == "icosphere":
+		a.Mesh, a.Shape = model.Icosphere(e.Radius, orDefault(e.Detail, 2))
+	case e.Primitive == "cylinder":
+		a.Mesh, a.Shape = model.Cylinder(e.Radius, e.Height, segments)
+	case e.Primitive == "cone":
+		a.Mesh, a.Shape = model.Cone(e.Radius, e.Height, segments)
+	case e.Primitive == "capsule":
+		a.Mesh, a.Shape = model.Capsule(e.Radius, e.Length, segments, rings)
+	case e.Primitive == "torus":
+		a.Mesh, a.Shape = model.Torus(e.Radius, e.Tube, segments, rings)
+	case e.Primitive == "grid":
+		if len(e.Size) != 2 {
+			return nil, errors.Errorf("mesh %s: grid needs a size of 2 components", e.Name)
+		}
+		a.Mesh, a.Shape = model.Grid(e.Size[0], e.Size[1], segments, orDefault(e.Rings, segments))
+	case e.Primitive == "extrude":
+		if len(e.Points) < 3 {
+			return nil, errors.Errorf("mesh %s: extrude needs at least 3 points", e.Name)
+		}
+		a.Mesh, a.Shape = model.Extrude(e.Points, e.Depth)
+	case e.Primitive == "lathe":
+		if len(e.Points) < 2 {
+			return nil, errors.Errorf("mesh %s: lathe needs at least 2 points", e.Name)
+		}
+		a.Mesh, a.Shape = model.Lathe(e.Points, segments)
+	default:
+		return nil, errors.Errorf("mesh %s: unknown primitive %q", e.Name, e.Primitive)
+	}
+	if e.NoNormals {
+		a.Mesh.Normals = nil
+	}
+	return a, nil
+}
@@ -0,0 +1,461 @@
+package assets
+
+import (
+	"goworld/gen"
+	"goworld/pb"
+	"image"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-errors/errors"
+)
+
+//Registry holds every asset described by the manifest directory.
+//IDs start at 1 and are assigned per asset kind in manifest order.
+//An asset keeps its ID across reloads for as long as its name is unchanged.
+type Registry struct {
+	Root      string
+	textures  map[uint64]*TextureAsset
+	materials map[uint64]*MaterialAsset
+	meshes    map[uint64]*MeshAsset
+	audio     map[uint64]*AudioAsset
+	names     map[pb.Request_Type]map[string]uint64
+	last      map[pb.Request_Type]uint64
+	generated map[uint64]bool
+	mutex     *sync.RWMutex
+}
+
+//Change identifies an asset that was added or modified
+type Change struct {
+	Kind pb.Request_Type
+	ID   uint64
+}
+
+func newRegistry(root string) *Registry {
+	r := new(Registry)
+	r.Root = root
+	r.textures = make(map[uint64]*TextureAsset)
+	r.materials = make(map[uint64]*MaterialAsset)
+	r.meshes = make(map[uint64]*MeshAsset)
+	r.audio = make(map[uint64]*AudioAsset)
+	r.names = map[pb.Request_Type]map[string]uint64{
+		pb.Request_TEXTURE:  make(map[string]uint64),
+		pb.Request_MATERIAL: make(map[string]uint64),
+		pb.Request_MESH:     make(map[string]uint64),
+		pb.Request_AUDIO:    make(map[string]uint64),
+	}
+	r.last = make(map[pb.Request_Type]uint64)
+	r.generated = make(map[uint64]bool)
+	r.mutex = new(sync.RWMutex)
+	return r
+}
+
+//Load reads the manifest files in root/manifest and validates them
+func Load(root string) (*Registry, error) {
+	r := newRegistry(root)
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+//Reload rereads the manifest and returns the assets that were added or modified.
+//The registry is left untouched if the new manifest is invalid.
+func (r *Registry) Reload() ([]Change, error) {
+	m, err := readManifest(filepath.Join(r.Root, "manifest"))
+	if err != nil {
+		return nil, err
+	}
+	n := newRegistry(r.Root)
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for k, v := range r.last {
+		n.last[k] = v
+	}
+	if err := n.build(m, r); err != nil {
+		return nil, err
+	}
+	for id := range r.generated {
+		a := r.meshes[id]
+		if _, ok := n.names[pb.Request_MESH][a.Name]; ok {
+			continue
+		}
+		n.meshes[id] = a
+		n.names[pb.Request_MESH][a.Name] = id
+		n.generated[id] = true
+	}
+	changes := []Change{}
+	for id, t := range n.textures {
+		if o, ok := r.textures[id]; !ok || o.Hash != t.Hash {
+			changes = append(changes, Change{Kind: pb.Request_TEXTURE, ID: id})
+		}
+	}
+	for id, a := range n.audio {
+		if o, ok := r.audio[id]; !ok || o.Hash != a.Hash {
+			changes = append(changes, Change{Kind: pb.Request_AUDIO, ID: id})
+		}
+	}
+	for id, a := range n.materials {
+		if o, ok := r.materials[id]; !ok || o.Hash != a.Hash || o.Physical != a.Physical {
+			changes = append(changes, Change{Kind: pb.Request_MATERIAL, ID: id})
+		}
+	}
+	for id, a := range n.meshes {
+		if o, ok := r.meshes[id]; !ok || o.Hash != a.Hash {
+			changes = append(changes, Change{Kind: pb.Request_MESH, ID: id})
+		}
+	}
+	r.textures = n.textures
+	r.materials = n.materials
+	r.meshes = n.meshes
+	r.audio = n.audio
+	r.names = n.names
+	r.last = n.last
+	r.generated = n.generated
+	return changes, nil
+}
+
+//Generate registers a procedurally generated mesh under a name, replacing any
+//previous mesh generated under it, along with lods simplified levels of detail.
+//Generated meshes are kept across reloads unless the manifest takes their name.
+func (r *Registry) Generate(name string, m *pb.Mesh, shape model.Shape, lods int) (*MeshAsset, error) {
+	return r.Restore(name, m, shape, model.LODs(m, lods))
+}
+
+//Restore registers a generated mesh like Generate does, with levels of detail that were
+//simplified beforehand, such as those of a chunk read back from disk
+func (r *Registry) Restore(name string, m *pb.Mesh, shape model.Shape, levels []*pb.Mesh) (*MeshAsset, error) {
+	a := &MeshAsset{Name: name, Mesh: m, Shape: shape}
+	var err error
+	if a.Hash, err = hashMessage(m); err != nil {
+		return nil, err
+	}
+	if err := a.setLODs(levels); err != nil {
+		return nil, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	id, ok := r.names[pb.Request_MESH][name]
+	if ok && !r.generated[id] {
+		return nil, errors.Errorf("duplicate mesh %q", name)
+	}
+	if !ok {
+		r.last[pb.Request_MESH]++
+		id = r.last[pb.Request_MESH]
+		r.names[pb.Request_MESH][name] = id
+	}
+	a.ID = id
+	r.meshes[id] = a
+	r.generated[id] = true
+	return a, nil
+}
+
+//Evict drops the mesh generated under a name, such as the terrain of a chunk that was
+//unloaded. Its ID is not reused; generating the mesh again assigns it a new one.
+func (r *Registry) Evict(name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	id, ok := r.names[pb.Request_MESH][name]
+	if !ok || !r.generated[id] {
+		return
+	}
+	delete(r.names[pb.Request_MESH], name)
+	delete(r.meshes, id)
+	delete(r.generated, id)
+}
+
+func (r *Registry) build(m *manifest, previous *Registry) error {
+	for _, e := range m.Textures {
+		t, err := r.texture(e)
+		if err != nil {
+			return err
+		}
+		t.ID = r.assign(pb.Request_TEXTURE, e.Name, previous)
+		r.textures[t.ID] = t
+	}
+	for _, e := range m.Atlases {
+		if err := r.atlas(e, previous); err != nil {
+			return err
+		}
+	}
+	for _, e := range m.Audio {
+		p, h, err := r.file(pb.Request_AUDIO, e.Name, e.File)
+		if err != nil {
+			return err
+		}
+		a := &AudioAsset{ID: r.assign(pb.Request_AUDIO, e.Name, previous), Name: e.Name, Path: p, Hash: h}
+		r.audio[a.ID] = a
+	}
+	for _, e := range m.Materials {
+		if err := r.checkName(pb.Request_MATERIAL, e.Name); err != nil {
+			return err
+		}
+		tid := uint64(0)
+		var texture *TextureAsset
+		if e.Texture != "" {
+			id, ok := r.names[pb.Request_TEXTURE][e.Texture]
+			if !ok {
+				return errors.Errorf("material %s: unknown texture %q", e.Name, e.Texture)
+			}
+			tid = id
+			texture = r.textures[id]
+			if texture.Atlas != 0 {
+				tid = texture.Atlas
+			}
+		}
+		pm, err := buildMaterial(e, tid)
+		if err != nil {
+			return err
+		}
+		h, err := hashMessage(pm)
+		if err != nil {
+			return err
+		}
+		a := &MaterialAsset{
+			ID:       r.assign(pb.Request_MATERIAL, e.Name, previous),
+			Name:     e.Name,
+			Material: pm,
+			Physical: MaterialPhysicalProperties{Density: e.Density},
+			Hash:     h,
+			Texture:  texture,
+		}
+		r.materials[a.ID] = a
+	}
+	for _, e := range m.Meshes {
+		if err := r.checkName(pb.Request_MESH, e.Name); err != nil {
+			return err
+		}
+		a, err := buildMesh(e, r.Root, func(name string) *MeshAsset {
+			return r.meshes[r.names[pb.Request_MESH][name]]
+		})
+		if err != nil {
+			return err
+		}
+		if err := r.packMesh(e, a); err != nil {
+			return err
+		}
+		if a.Hash, err = hashMessage(a.Mesh); err != nil {
+			return err
+		}
+		if err := a.buildLODs(e.LODs); err != nil {
+			return err
+		}
+		a.ID = r.assign(pb.Request_MESH, e.Name, previous)
+		r.meshes[a.ID] = a
+	}
+	return nil
+}
+
+//atlas packs textures defined earlier in the manifest into a new texture
+func (r *Registry) atlas(e atlasEntry, previous *Registry) error {
+	if err := r.checkName(pb.Request_TEXTURE, e.Name); err != nil {
+		return err
+	}
+	if len(e.Textures) == 0 {
+		return errors.Errorf("atlas %s: no textures", e.Name)
+	}
+	members := make([]*TextureAsset, len(e.Textures))
+	images := make([]image.Image, len(e.Textures))
+	for i, name := range e.Textures {
+		id, ok := r.names[pb.Request_TEXTURE][name]
+		if !ok {
+			return errors.Errorf("atlas %s: unknown texture %q", e.Name, name)
+		}
+		t := r.textures[id]
+		if t.Atlas != 0 || t.data != nil {
+			return errors.Errorf("atlas %s: texture %q is already packed", e.Name, name)
+		}
+		img, err := t.Image()
+		if err != nil {
+			return errors.Errorf("atlas %s: %v", e.Name, err)
+		}
+		members[i], images[i] = t, img
+	}
+	img, rects, err := model.PackAtlas(images, orDefault(e.Padding, 2))
+	if err != nil {
+		return errors.Errorf("atlas %s: %v", e.Name, err)
+	}
+	b, err := model.EncodePNG(img)
+	if err != nil {
+		return err
+	}
+	a := &TextureAsset{Name: e.Name, Hash: hashBytes(b), data: b}
+	a.Path = "atlas/" + e.Name + "/" + a.Hash
+	a.ID = r.assign(pb.Request_TEXTURE, e.Name, previous)
+	r.textures[a.ID] = a
+	for i, t := range members {
+		t.Atlas, t.Rect = a.ID, rects[i]
+	}
+	return nil
+}
+
+//packMesh moves the UVs of a mesh into the atlas rectangle of the texture of its material
+func (r *Registry) packMesh(e meshEntry, a *MeshAsset) error {
+	if e.Material == "" {
+		return nil
+	}
+	id, ok := r.names[pb.Request_MATERIAL][e.Material]
+	if !ok {
+		return errors.Errorf("mesh %s: unknown material %q", e.Name, e.Material)
+	}
+	t := r.materials[id].Texture
+	if t == nil || t.Atlas == 0 {
+		return nil
+	}
+	m, err := model.RemapUVs(a.Mesh, t.Rect)
+	if err != nil {
+		return errors.Errorf("mesh %s: %v", e.Name, err)
+	}
+	a.Mesh = m
+	return nil
+}
+
+//texture reads a texture file, or prepares a recipe to be rendered when the texture is first requested
+func (r *Registry) texture(e textureEntry) (*TextureAsset, error) {
+	if e.File != "" {
+		p, h, err := r.file(pb.Request_TEXTURE, e.Name, e.File)
+		if err != nil {
+			return nil, err
+		}
+		return &TextureAsset{Name: e.Name, Path: p, Hash: h}, nil
+	}
+	if err := r.checkName(pb.Request_TEXTURE, e.Name); err != nil {
+		return nil, err
+	}
+	rc, err := buildRecipe(e)
+	if err != nil {
+		return nil, err
+	}
+	h, err := hashRecipe(rc)
+	if err != nil {
+		return nil, err
+	}
+	return &TextureAsset{Name: e.Name, Path: "generated/" + e.Name + "/" + h, Hash: h, Recipe: rc}, nil
+}
+
+func (r *Registry) assign(kind pb.Request_Type, name string, previous *Registry) uint64 {
+	id, ok := previous.names[kind][name]
+	if !ok {
+		r.last[kind]++
+		id = r.last[kind]
+	}
+	r.names[kind][name] = id
+	return id
+}
+
+func (r *Registry) checkName(kind pb.Request_Type, name string) error {
+	k := kindName(kind)
+	if name == "" {
+		return errors.Errorf("%s without a name", k)
+	}
+	if _, ok := r.names[kind][name]; ok {
+		return errors.Errorf("duplicate %s %q", k, name)
+	}
+	return nil
+}
+
+func (r *Registry) file(kind pb.Request_Type, name string, file string) (string, string, error) {
+	if err := r.checkName(kind, name); err != nil {
+		return "", "", err
+	}
+	p := filepath.Join(r.Root, file)
+	h, err := hashFile(p)
+	if err != nil {
+		return "", "", errors.Errorf("%s %s: %v", kindName(kind), name, err)
+	}
+	return p, h, nil
+}
+
+func kindName(kind pb.Request_Type) string {
+	switch kind {
+	case pb.Request_TEXTURE:
+		return "texture"
+	case pb.Request_MATERIAL:
+		return "material"
+	case pb.Request_MESH:
+		return "mesh"
+	}
+	return "audio"
+}
+
+//Hash returns the content hash of an asset at a level of detail, or an empty string if it does not exist
+func (r *Registry) Hash(kind pb.Request_Type, id uint64, lod uint32) string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	switch kind {
+	case pb.Request_TEXTURE:
+		if a, ok := r.textures[id]; ok {
+			return a.LevelHash(lod)
+		}
+	case pb.Request_MATERIAL:
+		if a, ok := r.materials[id]; ok {
+			return a.Hash
+		}
+	case pb.Request_MESH:
+		if a, ok := r.meshes[id]; ok {
+			_, h := a.Level(lod)
+			return h
+		}
+	case pb.Request_AUDIO:
+		if a, ok := r.audio[id]; ok {
+			return a.Hash
+		}
+	}
+	return ""
+}
+
+//Texture returns the texture with the given ID
+func (r *Registry) Texture(id uint64) *TextureAsset {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.textures[id]
+}
+
+//TextureByName returns the texture with the given name
+func (r *Registry) TextureByName(name string) *TextureAsset {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.textures[r.names[pb.Request_TEXTURE][name]]
+}
+
+//Material returns the material with the given ID
+func (r *Registry) Material(id uint64) *MaterialAsset {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.materials[id]
+}
+
+//MaterialByName returns the material with the given name
+func (r *Registry) MaterialByName(name string) *MaterialAsset {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.materials[r.names[pb.Request_MATERIAL][name]]
+}
+
+//Mesh returns the mesh with the given ID
+func (r *Registry) Mesh(id uint64) *MeshAsset {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.meshes[id]
+}
+
+//MeshByName returns the mesh with the given name
+func (r *Registry) MeshByName(name string) *MeshAsset {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.meshes[r.names[pb.Request_MESH][name]]
+}
+
+//Audio returns the audio clip with the given ID
+func (r *Registry) Audio(id uint64) *AudioAsset {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.audio[id]
+}
+
+//AudioByName returns the audio clip with the given name
+func (r *Registry) AudioByName(name string) *AudioAsset {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.audio[r.names[pb.Request_AUDIO][name]]
+}
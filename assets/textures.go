@@ -1,16 +1,134 @@
 package assets
 
-var textureIDMap = map[uint64]string{
-	1: "stone",
-}
-
-//Texture represents the texture identifiers
-var Texture = struct {
-	Stone uint64
-	ByID  func(uint64) string
-}{
-	Stone: 1,
-	ByID: func(id uint64) string {
-		return textureIDMap[id]
-	},
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"goworld/gen"
+	"image"
+	"image/png"
+	"io/ioutil"
+
+	"github.com/go-errors/errors"
+)
+
+//TextureAsset represents a texture loaded from the manifest.
+//Generated textures and atlases have a Path that only names them in caches.
+//A texture packed into an atlas keeps its own ID, and Atlas and Rect tell
+//where materials and meshes using it are redirected to.
+type TextureAsset struct {
+	ID     uint64
+	Name   string
+	Path   string
+	Hash   string
+	Recipe *model.TextureRecipe
+	Atlas  uint64
+	Rect   [4]float64
+	data   []byte
+}
+
+//Load reads the PNG data of a texture, rendering it if it is generated
+func (t *TextureAsset) Load() ([]byte, error) {
+	b, _, err := t.load(false)
+	return b, err
+}
+
+//Image decodes a texture
+func (t *TextureAsset) Image() (image.Image, error) {
+	_, img, err := t.load(true)
+	return img, err
+}
+
+//Levels returns the PNG data of a texture followed by that of each of its mipmaps,
+//which clients can also request on their own as downscaled variants
+func (t *TextureAsset) Levels() ([][]byte, error) {
+	b, img, err := t.load(true)
+	if err != nil {
+		return nil, err
+	}
+	levels := [][]byte{b}
+	for _, m := range model.Mipmaps(img) {
+		p, err := model.EncodePNG(m)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, p)
+	}
+	return levels, nil
+}
+
+//LevelHash returns the content hash of a level of a texture
+func (t *TextureAsset) LevelHash(lod uint32) string {
+	if lod == 0 {
+		return t.Hash
+	}
+	return hashBytes([]byte(fmt.Sprintf("%s/%d", t.Hash, lod)))
+}
+
+func (t *TextureAsset) load(decode bool) ([]byte, image.Image, error) {
+	if t.Recipe != nil {
+		img, err := model.RenderTexture(*t.Recipe)
+		if err != nil {
+			return nil, nil, err
+		}
+		b, err := model.EncodePNG(img)
+		return b, img, err
+	}
+	b := t.data
+	if b == nil {
+		var err error
+		if b, err = ioutil.ReadFile(t.Path); err != nil {
+			return nil, nil, errors.Wrap(err, 0)
+		}
+	}
+	if !decode {
+		return b, nil, nil
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		return nil, nil, errors.Errorf("texture %s: %v", t.Name, err)
+	}
+	return b, img, nil
+}
+
+//buildRecipe starts from the named recipe, if any, and overrides it with the fields set in the entry
+func buildRecipe(e textureEntry) (*model.TextureRecipe, error) {
+	r := model.TextureRecipe{}
+	if e.Recipe != "" {
+		k, ok := model.TextureRecipes[e.Recipe]
+		if !ok {
+			return nil, errors.Errorf("texture %s: unknown recipe %q", e.Name, e.Recipe)
+		}
+		r = k
+	}
+	if e.Pattern != "" {
+		r.Pattern = e.Pattern
+	}
+	if e.Size != 0 {
+		r.Size = e.Size
+	}
+	if len(e.Colors) != 0 {
+		r.Colors = e.Colors
+	}
+	if e.Scale != 0 {
+		r.Scale = e.Scale
+	}
+	if e.Octaves != 0 {
+		r.Octaves = e.Octaves
+	}
+	if e.Seed != 0 {
+		r.Seed = e.Seed
+	}
+	if err := r.Check(); err != nil {
+		return nil, errors.Errorf("texture %s: %v", e.Name, err)
+	}
+	return &r, nil
+}
+
+func hashRecipe(r *model.TextureRecipe) (string, error) {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return "", errors.Wrap(err, 0)
+	}
+	return hashBytes(b), nil
 }
@@ -0,0 +1,143 @@
+package assets
+
+import (
+	"goworld/logging"
+	"goworld/pb"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-errors/errors"
+)
+
+var watchDelay = time.Millisecond * 250
+
+//Watch reloads the registry whenever a file below Root changes. The callback
+//receives the files that changed on disk and the assets affected by them.
+func (r *Registry) Watch(callback func(paths []string, changes []Change)) (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	err = filepath.Walk(r.Root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return watcher.Add(p)
+		}
+		return nil
+	})
+	if err != nil {
+		watcher.Close()
+		return nil, errors.Wrap(err, 0)
+	}
+	go func() {
+		pending := map[string]bool{}
+		timer := time.NewTimer(watchDelay)
+		timer.Stop()
+		for {
+			select {
+			case e, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if e.Op&fsnotify.Create != 0 {
+					if info, err := os.Stat(e.Name); err == nil && info.IsDir() {
+						watcher.Add(e.Name)
+					}
+				}
+				pending[filepath.Clean(e.Name)] = true
+				timer.Reset(watchDelay)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logging.Red(err)
+			case <-timer.C:
+				paths := []string{}
+				for p := range pending {
+					paths = append(paths, p)
+				}
+				pending = map[string]bool{}
+				callback(paths, r.changed(paths))
+			}
+		}
+	}()
+	return watcher, nil
+}
+
+//changed reloads the manifest when it, an imported mesh or a texture packed into an atlas
+//changed, as those are built into other assets, and rehashes the other assets backed by paths
+func (r *Registry) changed(paths []string) []Change {
+	changes := []Change{}
+	manifest := filepath.Clean(filepath.Join(r.Root, "manifest")) + string(filepath.Separator)
+	built := map[string]bool{}
+	r.mutex.RLock()
+	for _, m := range r.meshes {
+		if m.Path != "" {
+			built[filepath.Clean(m.Path)] = true
+		}
+	}
+	for _, t := range r.textures {
+		if t.Atlas != 0 {
+			built[filepath.Clean(t.Path)] = true
+		}
+	}
+	r.mutex.RUnlock()
+	for _, p := range paths {
+		if strings.HasPrefix(p, manifest) || built[p] {
+			c, err := r.Reload()
+			if err != nil {
+				logging.Error(err)
+				return changes
+			}
+			changes = c
+			break
+		}
+	}
+	seen := map[Change]bool{}
+	for _, c := range changes {
+		seen[c] = true
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, p := range paths {
+		h, err := hashFile(p)
+		if err != nil {
+			continue
+		}
+		for id, t := range r.textures {
+			c := Change{Kind: pb.Request_TEXTURE, ID: id}
+			if filepath.Clean(t.Path) == p && t.Hash != h {
+				n := *t
+				n.Hash = h
+				r.textures[id] = &n
+				for mid, m := range r.materials {
+					if m.Texture == t {
+						k := *m
+						k.Texture = &n
+						r.materials[mid] = &k
+					}
+				}
+				if !seen[c] {
+					seen[c] = true
+					changes = append(changes, c)
+				}
+			}
+		}
+		for id, a := range r.audio {
+			c := Change{Kind: pb.Request_AUDIO, ID: id}
+			if filepath.Clean(a.Path) == p && a.Hash != h {
+				r.audio[id] = &AudioAsset{ID: id, Name: a.Name, Path: a.Path, Hash: h}
+				if !seen[c] {
+					seen[c] = true
+					changes = append(changes, c)
+				}
+			}
+		}
+	}
+	return changes
+}
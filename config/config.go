@@ -0,0 +1,189 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+//DefaultPath is the config file read when none is given
+const DefaultPath = "./goworld.json"
+
+//Config is the configuration of the server, read from a JSON file and overridden by flags
+type Config struct {
+	//Listen is the address the player provision endpoint is served on
+	Listen string `json:"listen"`
+	//ICEServers are the STUN and TURN URLs offered to players connecting
+	ICEServers []string `json:"iceServers"`
+	//TickRate is how many times a second the simulation steps and SendRate how many times updates are sent
+	TickRate float64 `json:"tickRate"`
+	SendRate float64 `json:"sendRate"`
+	//CacheAge is how many minutes resources stay cached unread, checked every CacheInterval minutes
+	CacheAge      float64 `json:"cacheAge"`
+	CacheInterval float64 `json:"cacheInterval"`
+	//CacheSize caps the bytes of cached resources, the least recently read going first, unlimited when 0
+	CacheSize       int64   `json:"cacheSize"`
+	Seed            int64   `json:"seed"`
+	ChunkSize       float64 `json:"chunkSize"`
+	ChunkResolution int     `json:"chunkResolution"`
+	//Assets is the directory holding the asset manifest and Save the one edits and generated chunks are kept in
+	Assets string `json:"assets"`
+	Save   string `json:"save"`
+	//TokenSecret signs the tokens players present when they connect. The server only runs
+	//without one when started with -anonymous, letting anyone connect under an ID that lasts
+	//as long as their connection.
+	TokenSecret string `json:"tokenSecret"`
+	//Banned lists the IDs of the players turned away even with a valid token
+	Banned []string `json:"banned"`
+	//ReconnectGrace is how many seconds a player who disconnected may take to resume their session
+	ReconnectGrace float64 `json:"reconnectGrace"`
+}
+
+//Default returns the configuration used for anything the file and flags leave out
+func Default() *Config {
+	return &Config{
+		Listen:          ":8081",
+		ICEServers:      []string{"stun:stun.l.google.com:19302"},
+		TickRate:        60,
+		SendRate:        60,
+		CacheAge:        30,
+		CacheInterval:   5,
+		Seed:            1,
+		ChunkSize:       32,
+		ChunkResolution: 32,
+		Assets:          "./assets",
+		Save:            "./save",
+		ReconnectGrace:  60,
+	}
+}
+
+//list is a flag of comma separated values
+type list struct {
+	values *[]string
+}
+
+func (l list) String() string {
+	if l.values == nil {
+		return ""
+	}
+	return strings.Join(*l.values, ",")
+}
+
+func (l list) Set(s string) error {
+	*l.values = []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l.values = append(*l.values, v)
+		}
+	}
+	return nil
+}
+
+//bind defines a flag for each setting of a config on a flag set
+func (c *Config) bind(f *flag.FlagSet) {
+	f.StringVar(&c.Listen, "listen", c.Listen, "address the player provision endpoint listens on")
+	f.Var(list{&c.ICEServers}, "ice", "comma separated STUN and TURN server URLs")
+	f.Float64Var(&c.TickRate, "tick", c.TickRate, "simulation steps per second")
+	f.Float64Var(&c.SendRate, "send", c.SendRate, "updates sent to players per second")
+	f.Float64Var(&c.CacheAge, "cache-age", c.CacheAge, "minutes a cached resource is kept unread")
+	f.Float64Var(&c.CacheInterval, "cache-interval", c.CacheInterval, "minutes between cache prunings")
+	f.Int64Var(&c.CacheSize, "cache-size", c.CacheSize, "bytes of cached resources kept at most, 0 for no limit")
+	f.Int64Var(&c.Seed, "seed", c.Seed, "seed of the world")
+	f.Float64Var(&c.ChunkSize, "chunk-size", c.ChunkSize, "side of a chunk")
+	f.IntVar(&c.ChunkResolution, "chunk-resolution", c.ChunkResolution, "voxels along the side of a chunk")
+	f.StringVar(&c.Assets, "assets", c.Assets, "directory of the asset manifest")
+	f.StringVar(&c.Save, "save", c.Save, "directory edits and generated chunks are saved in")
+	f.StringVar(&c.TokenSecret, "token-secret", c.TokenSecret, "secret player tokens are signed with, required unless the server runs with -anonymous")
+	f.Var(list{&c.Banned}, "banned", "comma separated IDs of banned players")
+	f.Float64Var(&c.ReconnectGrace, "reconnect-grace", c.ReconnectGrace, "seconds a disconnected player may take to resume their session")
+}
+
+//Parse defines the settings as flags on a flag set, alongside any the caller defined, and
+//parses the arguments. Settings are read from the file named by -config over the defaults,
+//the flags given override them and the result is validated. The default file may be missing.
+func Parse(f *flag.FlagSet, args []string) (*Config, error) {
+	c := Default()
+	path := f.String("config", DefaultPath, "JSON config file")
+	c.bind(f)
+	if err := f.Parse(args); err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	set := []*flag.Flag{}
+	f.Visit(func(g *flag.Flag) {
+		set = append(set, g)
+	})
+	c = Default()
+	b, err := ioutil.ReadFile(*path)
+	switch {
+	case os.IsNotExist(err) && *path == DefaultPath:
+	case err != nil:
+		return nil, errors.Wrap(err, 0)
+	default:
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.DisallowUnknownFields()
+		if err := d.Decode(c); err != nil {
+			return nil, errors.Errorf("config %s: %v", *path, err)
+		}
+	}
+	overrides := flag.NewFlagSet(f.Name(), flag.ContinueOnError)
+	c.bind(overrides)
+	for _, g := range set {
+		if overrides.Lookup(g.Name) != nil {
+			if err := overrides.Set(g.Name, g.Value.String()); err != nil {
+				return nil, errors.Wrap(err, 0)
+			}
+		}
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+//Validate checks that every setting is usable
+func (c *Config) Validate() error {
+	if c.Listen == "" {
+		return errors.Errorf("config: no listen address")
+	}
+	for _, s := range c.ICEServers {
+		if !strings.HasPrefix(s, "stun:") && !strings.HasPrefix(s, "turn:") && !strings.HasPrefix(s, "turns:") {
+			return errors.Errorf("config: ICE server %q is not a stun:, turn: or turns: URL", s)
+		}
+	}
+	if !(c.TickRate > 0 && c.TickRate <= 1000) {
+		return errors.Errorf("config: tick rate %v out of range", c.TickRate)
+	}
+	if !(c.SendRate > 0 && c.SendRate <= c.TickRate) {
+		return errors.Errorf("config: send rate %v must be positive and at most the tick rate", c.SendRate)
+	}
+	if !(c.CacheAge > 0) || !(c.CacheInterval > 0) {
+		return errors.Errorf("config: cache age and interval must be positive")
+	}
+	if c.CacheSize < 0 {
+		return errors.Errorf("config: negative cache size")
+	}
+	if !(c.ChunkSize > 0) {
+		return errors.Errorf("config: chunk size %v must be positive", c.ChunkSize)
+	}
+	if c.ChunkResolution < 2 || c.ChunkResolution > 256 {
+		return errors.Errorf("config: chunk resolution %d out of range", c.ChunkResolution)
+	}
+	if c.Assets == "" || c.Save == "" {
+		return errors.Errorf("config: no assets or save directory")
+	}
+	if !(c.ReconnectGrace >= 0) {
+		return errors.Errorf("config: negative reconnection grace period")
+	}
+	if c.TokenSecret != "" && len(c.TokenSecret) < 16 {
+		return errors.Errorf("config: token secret shorter than 16 characters")
+	}
+	if s, err := os.Stat(c.Assets); err != nil || !s.IsDir() {
+		return errors.Errorf("config: assets directory %q not found", c.Assets)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package connector
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+//Verifier tells which player a token presented at connect time identifies. Any error
+//turns the player away.
+type Verifier interface {
+	Verify(token string) (player string, err error)
+}
+
+var (
+	//ErrBanned turns away a player whatever their token
+	ErrBanned       = errors.New("banned")
+	errNoToken      = errors.New("no token")
+	errInvalidToken = errors.New("invalid token")
+	errExpiredToken = errors.New("expired token")
+)
+
+//Tokens verifies tokens signed with a secret shared with whatever issues them. A token is
+//the base64 of a JSON claim naming a player and when the token expires, a dot and the
+//base64 of the HMAC-SHA256 of the claim.
+type Tokens struct {
+	secret []byte
+}
+
+type claim struct {
+	Player  string `json:"sub"`
+	Expires int64  `json:"exp"`
+}
+
+//NewTokens returns a verifier of the tokens signed with a secret
+func NewTokens(secret string) *Tokens {
+	return &Tokens{[]byte(secret)}
+}
+
+//Sign issues a token for a player valid for a while
+func (t *Tokens) Sign(player string, ttl time.Duration) string {
+	b, _ := json.Marshal(claim{player, time.Now().Add(ttl).Unix()})
+	c := base64.RawURLEncoding.EncodeToString(b)
+	return c + "." + base64.RawURLEncoding.EncodeToString(t.mac(c))
+}
+
+//Verify returns the player a token was signed for, unless it is forged or expired
+func (t *Tokens) Verify(token string) (string, error) {
+	if token == "" {
+		return "", errNoToken
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return "", errInvalidToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(mac, t.mac(parts[0])) {
+		return "", errInvalidToken
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", errInvalidToken
+	}
+	var c claim
+	if err := json.Unmarshal(b, &c); err != nil || c.Player == "" {
+		return "", errInvalidToken
+	}
+	if time.Now().Unix() >= c.Expires {
+		return "", errExpiredToken
+	}
+	return c.Player, nil
+}
+
+func (t *Tokens) mac(claim string) []byte {
+	h := hmac.New(sha256.New, t.secret)
+	h.Write([]byte(claim))
+	return h.Sum(nil)
+}
+
+//AnonymousPrefix starts the IDs Anonymous makes up
+const AnonymousPrefix = "anonymous-"
+
+//Anonymous lets anyone in under a random ID, which lasts as long as their connection
+type Anonymous struct{}
+
+//Verify ignores the token and makes up a player
+func (Anonymous) Verify(string) (string, error) {
+	return AnonymousPrefix + randomID(), nil
+}
+
+type banning struct {
+	verifier Verifier
+	banned   map[string]bool
+}
+
+//Banning turns the players of a list away with ErrBanned, whatever a verifier says of their tokens
+func Banning(v Verifier, players []string) Verifier {
+	b := &banning{v, map[string]bool{}}
+	for _, p := range players {
+		b.banned[p] = true
+	}
+	return b
+}
+
+func (b *banning) Verify(token string) (string, error) {
+	p, err := b.verifier.Verify(token)
+	if err != nil {
+		return "", err
+	}
+	if b.banned[p] {
+		return "", ErrBanned
+	}
+	return p, nil
+}
+
+//randomID returns 128 random bits in hex
+func randomID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -1,8 +1,7 @@
 package connector
 
 import (
-	"encoding/base64"
-	"fmt"
+	"net"
 	"net/http"
 	"sync"
 
@@ -10,58 +9,46 @@ import (
 	"github.com/pions/webrtc/pkg/datachannel"
 )
 
-//Peer represents a connection peer
-type Peer struct {
-	channelPtr        *webrtc.RTCDataChannel
-	updateChannelPtr  *webrtc.RTCDataChannel
-	onMessageCallback func([]byte)
-	onUpdateCallback  func([]byte)
-}
-
 //Connector represents the client connector
 type Connector struct {
 	shutdown             chan interface{}
-	peers                map[*webrtc.RTCDataChannel]*Peer
-	connectedCallback    func(*Peer)
-	disconnectedCallback func(*Peer)
+	peers                map[*webrtc.RTCDataChannel]*peer
+	connectedCallback    func(Peer)
+	disconnectedCallback func(Peer)
 	peersMutex           *sync.Mutex
-}
-
-type httpHandler struct {
-	connector *Connector
-}
-
-func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	if len(r.URL.Path) < 2 {
-		fmt.Fprint(w, "error")
-		return
-	}
-	s, err := base64.URLEncoding.DecodeString(r.URL.Path[1:])
-	if err != nil {
-		fmt.Fprint(w, "error")
-		return
-	}
-	code, err := rtcConnect(string(s), h.connector.connected, h.connector.disconnected, h.connector.updateChannelConnected)
+	iceServers           []string
+	verifier             Verifier
+	listener             net.Listener
+	closeOnce            *sync.Once
+	sessions             map[string]*session
+	sessionsMutex        *sync.Mutex
+	sockets              map[*peer]*socketTransport
+}
+
+//Init serves the signaling API on an address and waits for connections, offering players
+//the given STUN and TURN servers and letting in those whose token a verifier accepts
+func Init(listen string, iceServers []string, verifier Verifier) (*Connector, error) {
+	l, err := net.Listen("tcp", listen)
 	if err != nil {
-		fmt.Fprint(w, "error")
-		return
+		return nil, err
 	}
-	fmt.Fprint(w, code)
-}
-
-//Init serves the player provision HTTP endpoint and waits for connections
-func Init() *Connector {
 	c := new(Connector)
-	c.peers = make(map[*webrtc.RTCDataChannel]*Peer)
+	c.iceServers = iceServers
+	c.verifier = verifier
+	c.listener = l
+	c.closeOnce = new(sync.Once)
+	c.peers = make(map[*webrtc.RTCDataChannel]*peer)
 	c.shutdown = make(chan interface{})
-	c.connectedCallback = func(*Peer) {}
-	c.disconnectedCallback = func(*Peer) {}
+	c.connectedCallback = func(Peer) {}
+	c.disconnectedCallback = func(Peer) {}
 	c.peersMutex = new(sync.Mutex)
+	c.sessions = make(map[string]*session)
+	c.sessionsMutex = new(sync.Mutex)
+	c.sockets = make(map[*peer]*socketTransport)
 	h := new(httpHandler)
 	h.connector = c
-	go http.ListenAndServe(":8081", h)
-	return c
+	go http.Serve(l, h)
+	return c, nil
 }
 
 //Wait waits on the connector
@@ -69,98 +56,99 @@ func (c *Connector) Wait() {
 	<-c.shutdown
 }
 
-//Close closes the connector
+//Stop stops accepting new peers
+func (c *Connector) Stop() {
+	c.listener.Close()
+}
+
+//Close stops accepting new peers, closes the connections of every session and socket and releases Wait
 func (c *Connector) Close() {
-	c.shutdown <- struct{}{}
+	c.closeOnce.Do(func() {
+		c.Stop()
+		c.sessionsMutex.Lock()
+		sessions := []*session{}
+		for _, s := range c.sessions {
+			sessions = append(sessions, s)
+		}
+		c.sessionsMutex.Unlock()
+		for _, s := range sessions {
+			c.endSession(s)
+		}
+		c.peersMutex.Lock()
+		for _, t := range c.sockets {
+			t.close()
+		}
+		c.peersMutex.Unlock()
+		close(c.shutdown)
+	})
 }
 
-func (c *Connector) connected(channel *webrtc.RTCDataChannel) {
+func (c *Connector) connected(channel *webrtc.RTCDataChannel, player string) {
 	channel.Lock()
 	defer channel.Unlock()
 
 	channel.OnOpen(func() {
 		c.peersMutex.Lock()
-		m, ok := c.peers[channel]
-		if !ok {
-			m = new(Peer)
-			c.peers[channel] = m
-		}
-		m.onMessageCallback = func([]byte) {}
-		m.channelPtr = channel
-		c.connectedCallback(c.peers[channel])
+		p, t := c.rtcPeer(channel, player)
+		t.channel = channel
 		c.peersMutex.Unlock()
+		c.connectedCallback(p)
 	})
 
 	channel.Onmessage(func(payload datachannel.Payload) {
 		switch p := payload.(type) {
 		case *datachannel.PayloadBinary:
-			c.peers[channel].onMessageCallback(p.Data)
+			c.peers[channel].message(p.Data)
 		}
 	})
 }
 
-func (c *Connector) updateChannelConnected(peerChannel *webrtc.RTCDataChannel, updateChannel *webrtc.RTCDataChannel) {
+func (c *Connector) updateChannelConnected(peerChannel *webrtc.RTCDataChannel, updateChannel *webrtc.RTCDataChannel, player string) {
 	updateChannel.Lock()
 	defer updateChannel.Unlock()
 	updateChannel.OnOpen(func() {
 		go func() {
 			c.peersMutex.Lock()
 			defer c.peersMutex.Unlock()
-			_, ok := c.peers[peerChannel]
-			if !ok {
-				c.peers[peerChannel] = new(Peer)
-			}
-			c.peers[peerChannel].updateChannelPtr = updateChannel
-			c.peers[peerChannel].onUpdateCallback = func([]byte) {}
+			_, t := c.rtcPeer(peerChannel, player)
+			t.updateChannel = updateChannel
 		}()
 	})
 	updateChannel.OnMessage(func(payload datachannel.Payload) {
 		switch p := payload.(type) {
 		case *datachannel.PayloadBinary:
-			c.peers[peerChannel].onUpdateCallback(p.Data)
+			c.peers[peerChannel].update(p.Data)
 		}
 	})
 }
 
+//rtcPeer returns the peer of a data channel, creating it for a player when the first of
+//its channels opens. peersMutex must be held.
+func (c *Connector) rtcPeer(channel *webrtc.RTCDataChannel, player string) (*peer, *rtcTransport) {
+	p, ok := c.peers[channel]
+	if !ok {
+		p = newPeer(new(rtcTransport), player)
+		c.peers[channel] = p
+	}
+	return p, p.transport.(*rtcTransport)
+}
+
 func (c *Connector) disconnected(channel *webrtc.RTCDataChannel) {
-	c.disconnectedCallback(c.peers[channel])
+	//a channel that never opened has no peer, and a nil *peer would not be a nil Peer
+	if p, ok := c.peers[channel]; ok {
+		c.disconnectedCallback(p)
+	}
 	channel.Lock()
 	defer channel.Unlock()
 	delete(c.peers, channel)
 }
 
 //OnPeerConnected registers a peer connection callback
-func (c *Connector) OnPeerConnected(callback func(*Peer)) {
+func (c *Connector) OnPeerConnected(callback func(Peer)) {
 	c.connectedCallback = callback
 }
 
 //OnPeerDisconnected registers a peer disconnection callback
-func (c *Connector) OnPeerDisconnected(callback func(*Peer)) {
+func (c *Connector) OnPeerDisconnected(callback func(Peer)) {
 	c.disconnectedCallback = callback
 }
-
-//OnMessage registers a peer message callback
-func (p *Peer) OnMessage(callback func([]byte)) {
-	p.onMessageCallback = callback
-}
-
-//OnUpdate registers a peer update callback
-func (p *Peer) OnUpdate(callback func([]byte)) {
-	p.onUpdateCallback = callback
-}
-
-//SendMessage sends a message to a peer
-func (p *Peer) SendMessage(data []byte) {
-	p.channelPtr.Send(datachannel.PayloadBinary{
-		Data: data,
-	})
-}
-
-//SendUpdate sends an update to a peer
-func (p *Peer) SendUpdate(data []byte) {
-	if p.updateChannelPtr != nil {
-		p.updateChannelPtr.Send(datachannel.PayloadBinary{
-			Data: data,
-		})
-	}
-}
@@ -0,0 +1,106 @@
+package connector
+
+import "sync"
+
+//Loopback connects a peer to a client in the same process without a network, so that
+//tests and bots can play: Server is handed to the world like any connected peer while
+//Client sends it requests and receives its messages. Each way messages and updates
+//arrive in order and none are lost. They are delivered by a goroutine per direction so
+//that callbacks may send in turn.
+type Loopback struct {
+	Server   Peer
+	Client   Peer
+	toServer *loopbackQueue
+	toClient *loopbackQueue
+}
+
+//NewLoopback connects a server peer to a client peer, both with the ID of a player
+func NewLoopback(player string) *Loopback {
+	server, client := newPeer(nil, player), newPeer(nil, player)
+	l := &Loopback{Server: server, Client: client}
+	l.toServer = newLoopbackQueue(server)
+	l.toClient = newLoopbackQueue(client)
+	server.transport = l.toClient
+	client.transport = l.toServer
+	go l.toServer.deliver()
+	go l.toClient.deliver()
+	return l
+}
+
+//Close stops delivering, dropping whatever is still on its way
+func (l *Loopback) Close() {
+	l.toServer.close()
+	l.toClient.close()
+}
+
+type loopbackFrame struct {
+	update bool
+	data   []byte
+}
+
+//loopbackQueue holds the frames on their way to a peer. It never fills up, so senders
+//never wait on receivers.
+type loopbackQueue struct {
+	to     *peer
+	frames []loopbackFrame
+	closed bool
+	mutex  *sync.Mutex
+	cond   *sync.Cond
+}
+
+func newLoopbackQueue(to *peer) *loopbackQueue {
+	q := &loopbackQueue{to: to, mutex: new(sync.Mutex)}
+	q.cond = sync.NewCond(q.mutex)
+	return q
+}
+
+func (q *loopbackQueue) sendMessage(data []byte) {
+	q.push(loopbackFrame{false, data})
+}
+
+func (q *loopbackQueue) sendUpdate(data []byte) {
+	q.push(loopbackFrame{true, data})
+}
+
+//push queues a copy of a frame, as senders may reuse their buffers
+func (q *loopbackQueue) push(f loopbackFrame) {
+	f.data = append([]byte(nil), f.data...)
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return
+	}
+	q.frames = append(q.frames, f)
+	q.cond.Signal()
+}
+
+func (q *loopbackQueue) close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.closed = true
+	q.frames = nil
+	q.cond.Signal()
+}
+
+//deliver hands queued frames to the callbacks of the peer until the queue is closed
+func (q *loopbackQueue) deliver() {
+	for {
+		q.mutex.Lock()
+		for len(q.frames) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if q.closed {
+			q.mutex.Unlock()
+			return
+		}
+		f := q.frames[0]
+		q.frames[0] = loopbackFrame{}
+		q.frames = q.frames[1:]
+		q.mutex.Unlock()
+		if f.update {
+			q.to.update(f.data)
+		} else {
+			q.to.message(f.data)
+		}
+	}
+}
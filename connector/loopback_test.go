@@ -0,0 +1,70 @@
+package connector
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, c chan []byte) []byte {
+	select {
+	case d := <-c:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("nothing received")
+	}
+	return nil
+}
+
+func TestLoopbackOrder(t *testing.T) {
+	l := NewLoopback("alice")
+	defer l.Close()
+	if l.Server.ID() != "alice" || l.Client.ID() != "alice" {
+		t.Fatalf("IDs %q and %q", l.Server.ID(), l.Client.ID())
+	}
+	messages, updates := make(chan []byte, 1000), make(chan []byte, 1000)
+	l.Server.OnMessage(func(d []byte) { messages <- d })
+	l.Server.OnUpdate(func(d []byte) { updates <- d })
+	b := make([]byte, 1)
+	for i := 0; i < 500; i++ {
+		//the buffer is reused, frames must be copied
+		b[0] = byte(i)
+		l.Client.SendMessage(b)
+		l.Client.SendUpdate(b)
+	}
+	for i := 0; i < 500; i++ {
+		if d := receive(t, messages); d[0] != byte(i) {
+			t.Fatalf("message %d received as %d", i, d[0])
+		}
+		if d := receive(t, updates); d[0] != byte(i) {
+			t.Fatalf("update %d received as %d", i, d[0])
+		}
+	}
+}
+
+func TestLoopbackBothWays(t *testing.T) {
+	l := NewLoopback("alice")
+	defer l.Close()
+	replies := make(chan []byte, 1)
+	//callbacks may send in turn
+	l.Server.OnMessage(func(d []byte) { l.Server.SendMessage(append([]byte("re:"), d...)) })
+	l.Client.OnMessage(func(d []byte) { replies <- d })
+	l.Client.SendMessage([]byte("hello"))
+	if d := receive(t, replies); !bytes.Equal(d, []byte("re:hello")) {
+		t.Fatalf("received %q", d)
+	}
+}
+
+func TestLoopbackClose(t *testing.T) {
+	l := NewLoopback("alice")
+	received := make(chan []byte, 10)
+	l.Server.OnMessage(func(d []byte) { received <- d })
+	l.Close()
+	l.Client.SendMessage([]byte("lost"))
+	l.Close()
+	select {
+	case d := <-received:
+		t.Fatalf("received %q after close", d)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,87 @@
+package connector
+
+import "sync"
+
+//Peer is a player connected to the server, whatever carries its messages
+type Peer interface {
+	//ID is the persistent ID of the player, as verified when they connected
+	ID() string
+	//SendMessage sends a message to the peer reliably and in order
+	SendMessage(data []byte)
+	//SendUpdate sends an update to the peer, which may be lost
+	SendUpdate(data []byte)
+	//OnMessage registers a callback for the messages of the peer
+	OnMessage(callback func([]byte))
+	//OnUpdate registers a callback for the updates of the peer
+	OnUpdate(callback func([]byte))
+}
+
+//transport carries the messages and updates of a peer
+type transport interface {
+	sendMessage(data []byte)
+	sendUpdate(data []byte)
+}
+
+//peer is a Peer over a transport
+type peer struct {
+	id                string
+	transport         transport
+	onMessageCallback func([]byte)
+	onUpdateCallback  func([]byte)
+	callbackMutex     *sync.RWMutex
+}
+
+func newPeer(t transport, id string) *peer {
+	p := new(peer)
+	p.id = id
+	p.transport = t
+	p.onMessageCallback = func([]byte) {}
+	p.onUpdateCallback = func([]byte) {}
+	p.callbackMutex = new(sync.RWMutex)
+	return p
+}
+
+//ID returns the ID of the player
+func (p *peer) ID() string {
+	return p.id
+}
+
+//OnMessage registers a peer message callback
+func (p *peer) OnMessage(callback func([]byte)) {
+	p.callbackMutex.Lock()
+	defer p.callbackMutex.Unlock()
+	p.onMessageCallback = callback
+}
+
+//OnUpdate registers a peer update callback
+func (p *peer) OnUpdate(callback func([]byte)) {
+	p.callbackMutex.Lock()
+	defer p.callbackMutex.Unlock()
+	p.onUpdateCallback = callback
+}
+
+//SendMessage sends a message to a peer
+func (p *peer) SendMessage(data []byte) {
+	p.transport.sendMessage(data)
+}
+
+//SendUpdate sends an update to a peer
+func (p *peer) SendUpdate(data []byte) {
+	p.transport.sendUpdate(data)
+}
+
+//message hands a message received from the peer to its callback
+func (p *peer) message(data []byte) {
+	p.callbackMutex.RLock()
+	callback := p.onMessageCallback
+	p.callbackMutex.RUnlock()
+	callback(data)
+}
+
+//update hands an update received from the peer to its callback
+func (p *peer) update(data []byte) {
+	p.callbackMutex.RLock()
+	callback := p.onUpdateCallback
+	p.callbackMutex.RUnlock()
+	callback(data)
+}
@@ -1,28 +1,48 @@
 package connector
 
 import (
-	"encoding/base64"
-
 	"github.com/pions/webrtc/pkg/ice"
 
 	"github.com/pions/webrtc"
+	"github.com/pions/webrtc/pkg/datachannel"
 )
 
 //MaxStreamChunkSize is the maximum size of a stream chunk
 const MaxStreamChunkSize = 16384
 
-func rtcConnect(descriptor string, connected func(*webrtc.RTCDataChannel), disconnected func(*webrtc.RTCDataChannel), updateChannelConnected func(*webrtc.RTCDataChannel, *webrtc.RTCDataChannel)) (string, error) {
+//rtcTransport sends messages over a reliable data channel and updates over an unreliable
+//one, once it is open
+type rtcTransport struct {
+	channel       *webrtc.RTCDataChannel
+	updateChannel *webrtc.RTCDataChannel
+}
+
+func (t *rtcTransport) sendMessage(data []byte) {
+	t.channel.Send(datachannel.PayloadBinary{
+		Data: data,
+	})
+}
+
+func (t *rtcTransport) sendUpdate(data []byte) {
+	if t.updateChannel != nil {
+		t.updateChannel.Send(datachannel.PayloadBinary{
+			Data: data,
+		})
+	}
+}
+
+//rtcConnect answers an SDP offer with a new peer connection, returning the connection and the SDP answer
+func rtcConnect(descriptor string, iceServers []string, connected func(*webrtc.RTCDataChannel), disconnected func(*webrtc.RTCDataChannel), updateChannelConnected func(*webrtc.RTCDataChannel, *webrtc.RTCDataChannel)) (*webrtc.RTCPeerConnection, string, error) {
 	config := webrtc.RTCConfiguration{
-		IceServers: []webrtc.RTCIceServer{
-			{
-				URLs: []string{"stun:stun.l.google.com:19302"},
-			},
-		},
+		IceServers: []webrtc.RTCIceServer{},
+	}
+	if len(iceServers) > 0 {
+		config.IceServers = append(config.IceServers, webrtc.RTCIceServer{URLs: iceServers})
 	}
 	channel := &webrtc.RTCDataChannel{}
 	peerConnection, err := webrtc.New(config)
 	if err != nil {
-		return "", err
+		return nil, "", err
 	}
 	peerConnection.OnICEConnectionStateChange(func(i ice.ConnectionState) {
 		if i == ice.ConnectionStateClosed || i == ice.ConnectionStateDisconnected {
@@ -46,14 +66,16 @@ func rtcConnect(descriptor string, connected func(*webrtc.RTCDataChannel), disco
 
 	err = peerConnection.SetRemoteDescription(offer)
 	if err != nil {
-		return "", err
+		peerConnection.Close()
+		return nil, "", err
 	}
 
 	answer, err := peerConnection.CreateAnswer(nil)
 	if err != nil {
-		return "", err
+		peerConnection.Close()
+		return nil, "", err
 	}
 
-	return base64.StdEncoding.EncodeToString([]byte(answer.Sdp)), nil
+	return peerConnection, answer.Sdp, nil
 
 }
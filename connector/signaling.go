@@ -0,0 +1,268 @@
+package connector
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pions/webrtc"
+)
+
+const (
+	//maxRequestSize bounds the body of a signaling request, enough for an SDP offer
+	maxRequestSize = 64 << 10
+	//sessionTimeout is how long a session may take to open its data channel before it is dropped
+	sessionTimeout = 30 * time.Second
+)
+
+//session is a peer connection negotiated through the signaling API, named by a random ID
+//so that candidates can be trickled to it after the offer is answered
+type session struct {
+	ID         string
+	player     string
+	connection *webrtc.RTCPeerConnection
+	open       bool
+	ended      bool
+}
+
+//offerRequest starts a session
+type offerRequest struct {
+	Offer string `json:"offer"`
+}
+
+//answerResponse carries the answer to an offer and the ID of the session it started.
+//The candidates of the server are part of the answer.
+type answerResponse struct {
+	ID     string `json:"id"`
+	Answer string `json:"answer"`
+}
+
+//candidateRequest trickles an ICE candidate of a player, shaped like RTCIceCandidateInit.
+//An empty candidate marks the end of the candidates.
+type candidateRequest struct {
+	Candidate     string `json:"candidate"`
+	SDPMid        string `json:"sdpMid"`
+	SDPMLineIndex int    `json:"sdpMLineIndex"`
+}
+
+//apiError is the body of every failed signaling request
+type apiError struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *apiError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+type httpHandler struct {
+	connector *Connector
+}
+
+//ServeHTTP routes the signaling API:
+//POST /sessions with an offer and a bearer token answers it and starts a session,
+//POST /sessions/{id}/candidates adds an ICE candidate of the player to a session,
+//which only goes from the player to the server: the server gathers its candidates
+//before answering and sends them all in the answer rather than trickling them,
+//DELETE /sessions/{id} ends a session and
+//GET /socket connects a player over a WebSocket instead, the token being its first frame.
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Access-Control-Allow-Methods", "POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	var err *apiError
+	switch {
+	case len(parts) == 1 && parts[0] == "sessions":
+		err = allow(r, http.MethodPost)
+		if err == nil {
+			err = h.createSession(w, r)
+		}
+	case len(parts) == 2 && parts[0] == "sessions":
+		err = allow(r, http.MethodDelete)
+		if err == nil {
+			err = h.deleteSession(w, parts[1])
+		}
+	case len(parts) == 3 && parts[0] == "sessions" && parts[2] == "candidates":
+		err = allow(r, http.MethodPost)
+		if err == nil {
+			err = h.addCandidate(w, r, parts[1])
+		}
+	case len(parts) == 1 && parts[0] == "socket":
+		err = allow(r, http.MethodGet)
+		if err == nil {
+			h.connector.serveSocket(w, r)
+		}
+	default:
+		err = &apiError{http.StatusNotFound, "not_found", "no such endpoint"}
+	}
+	if err != nil {
+		writeJSON(w, err.Status, map[string]*apiError{"error": err})
+	}
+}
+
+func (h *httpHandler) createSession(w http.ResponseWriter, r *http.Request) *apiError {
+	c := h.connector
+	player, aerr := c.authenticate(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if aerr != nil {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		return aerr
+	}
+	var o offerRequest
+	if err := decode(w, r, &o); err != nil {
+		return err
+	}
+	if o.Offer == "" {
+		return &apiError{http.StatusBadRequest, "invalid_offer", "no offer"}
+	}
+	s := &session{ID: randomID(), player: player}
+	connected := func(channel *webrtc.RTCDataChannel) {
+		c.sessionsMutex.Lock()
+		s.open = true
+		c.sessionsMutex.Unlock()
+		c.connected(channel, s.player)
+	}
+	updateConnected := func(channel *webrtc.RTCDataChannel, updateChannel *webrtc.RTCDataChannel) {
+		c.updateChannelConnected(channel, updateChannel, s.player)
+	}
+	disconnected := func(channel *webrtc.RTCDataChannel) {
+		c.forgetSession(s)
+		c.disconnected(channel)
+	}
+	//the session is registered first so that a disconnection during setup forgets it
+	c.sessionsMutex.Lock()
+	c.sessions[s.ID] = s
+	c.sessionsMutex.Unlock()
+	connection, answer, err := rtcConnect(o.Offer, c.iceServers, connected, disconnected, updateConnected)
+	if err != nil {
+		c.forgetSession(s)
+		return &apiError{http.StatusBadRequest, "invalid_offer", err.Error()}
+	}
+	c.sessionsMutex.Lock()
+	s.connection = connection
+	ended := s.ended
+	c.sessionsMutex.Unlock()
+	if ended {
+		connection.Close()
+		return &apiError{http.StatusBadRequest, "connection_failed", "the connection closed while it was set up"}
+	}
+	time.AfterFunc(sessionTimeout, func() {
+		c.sessionsMutex.Lock()
+		open := s.open
+		c.sessionsMutex.Unlock()
+		if !open {
+			c.endSession(s)
+		}
+	})
+	writeJSON(w, http.StatusCreated, answerResponse{ID: s.ID, Answer: answer})
+	return nil
+}
+
+func (h *httpHandler) addCandidate(w http.ResponseWriter, r *http.Request, id string) *apiError {
+	var cr candidateRequest
+	if err := decode(w, r, &cr); err != nil {
+		return err
+	}
+	s := h.connector.session(id)
+	if s == nil {
+		return &apiError{http.StatusNotFound, "unknown_session", "no session " + id}
+	}
+	if cr.Candidate != "" {
+		if err := s.connection.AddIceCandidate(cr.Candidate); err != nil {
+			return &apiError{http.StatusBadRequest, "invalid_candidate", err.Error()}
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (h *httpHandler) deleteSession(w http.ResponseWriter, id string) *apiError {
+	s := h.connector.session(id)
+	if s == nil {
+		return &apiError{http.StatusNotFound, "unknown_session", "no session " + id}
+	}
+	h.connector.endSession(s)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+//authenticate returns the player a token identifies
+func (c *Connector) authenticate(token string) (string, *apiError) {
+	player, err := c.verifier.Verify(token)
+	if err == ErrBanned {
+		return "", &apiError{http.StatusForbidden, "banned", "this player is banned"}
+	}
+	if err != nil {
+		return "", &apiError{http.StatusUnauthorized, "unauthenticated", err.Error()}
+	}
+	return player, nil
+}
+
+//session returns a session once its connection is set up, or nil
+func (c *Connector) session(id string) *session {
+	c.sessionsMutex.Lock()
+	defer c.sessionsMutex.Unlock()
+	s := c.sessions[id]
+	if s == nil || s.connection == nil {
+		return nil
+	}
+	return s
+}
+
+//forgetSession removes a session, telling whether it was still there
+func (c *Connector) forgetSession(s *session) bool {
+	c.sessionsMutex.Lock()
+	defer c.sessionsMutex.Unlock()
+	if s.ended {
+		return false
+	}
+	s.ended = true
+	delete(c.sessions, s.ID)
+	return true
+}
+
+//endSession removes a session and closes its connection
+func (c *Connector) endSession(s *session) {
+	if c.forgetSession(s) {
+		s.connection.Close()
+	}
+}
+
+//allow rejects requests of any other method
+func allow(r *http.Request, method string) *apiError {
+	if r.Method != method {
+		return &apiError{http.StatusMethodNotAllowed, "method_not_allowed", r.Method + " not allowed, expected " + method}
+	}
+	return nil
+}
+
+//decode reads a JSON request body of at most maxRequestSize bytes into v
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) *apiError {
+	if t, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || t != "application/json" {
+		return &apiError{http.StatusUnsupportedMediaType, "unsupported_media_type", "expected application/json"}
+	}
+	d := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	d.DisallowUnknownFields()
+	if err := d.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return &apiError{http.StatusRequestEntityTooLarge, "request_too_large", "requests are limited to 64 KiB"}
+		}
+		return &apiError{http.StatusBadRequest, "invalid_json", err.Error()}
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,120 @@
+package connector
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pions/webrtc"
+)
+
+const testSecret = "a secret of at least 16 characters"
+
+func newTestConnector(t *testing.T) (*Connector, string) {
+	c, err := Init("127.0.0.1:0", nil, Banning(NewTokens(testSecret), []string{"mallory"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	return c, "http://" + c.listener.Addr().String()
+}
+
+//newOffer returns the SDP offer of a client peer connection opening a data channel
+func newOffer(t *testing.T) string {
+	pc, err := webrtc.New(webrtc.RTCConfiguration{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	if _, err := pc.CreateDataChannel("data", nil); err != nil {
+		t.Fatal(err)
+	}
+	offer, err := pc.CreateOffer(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return offer.Sdp
+}
+
+func post(t *testing.T, url string, contentType string, token string, body interface{}) *http.Response {
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", contentType)
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestCreateSession(t *testing.T) {
+	_, api := newTestConnector(t)
+	token := NewTokens(testSecret).Sign("alice", time.Hour)
+	resp := post(t, api+"/sessions", "application/json; charset=utf-8", token, offerRequest{newOffer(t)})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	var a answerResponse
+	if err := json.NewDecoder(resp.Body).Decode(&a); err != nil {
+		t.Fatal(err)
+	}
+	if a.ID == "" || !strings.HasPrefix(a.Answer, "v=0") {
+		t.Fatalf("session %q answered with %q", a.ID, a.Answer)
+	}
+	resp = post(t, api+"/sessions/"+a.ID+"/candidates", "application/json", "", candidateRequest{})
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("candidate status %d", resp.StatusCode)
+	}
+	r, _ := http.NewRequest(http.MethodDelete, api+"/sessions/"+a.ID, nil)
+	d, err := http.DefaultClient.Do(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Body.Close()
+	if d.StatusCode != http.StatusNoContent {
+		t.Fatalf("delete status %d", d.StatusCode)
+	}
+}
+
+func TestCreateSessionRejected(t *testing.T) {
+	_, api := newTestConnector(t)
+	tokens := NewTokens(testSecret)
+	alice, mallory := tokens.Sign("alice", time.Hour), tokens.Sign("mallory", time.Hour)
+	for _, c := range []struct {
+		name        string
+		contentType string
+		token       string
+		offer       string
+		status      int
+		code        string
+	}{
+		{"no token", "application/json", "", "offer", http.StatusUnauthorized, "unauthenticated"},
+		{"forged token", "application/json", NewTokens("another secret entirely").Sign("alice", time.Hour), "offer", http.StatusUnauthorized, "unauthenticated"},
+		{"banned", "application/json", mallory, "offer", http.StatusForbidden, "banned"},
+		{"not JSON", "text/plain", alice, "offer", http.StatusUnsupportedMediaType, "unsupported_media_type"},
+		{"no offer", "application/json", alice, "", http.StatusBadRequest, "invalid_offer"},
+		{"invalid offer", "application/json", alice, "not SDP", http.StatusBadRequest, "invalid_offer"},
+	} {
+		resp := post(t, api+"/sessions", c.contentType, c.token, offerRequest{c.offer})
+		var body struct {
+			Error apiError `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		if resp.StatusCode != c.status || body.Error.Code != c.code {
+			t.Errorf("%s: got %d %q, expected %d %q", c.name, resp.StatusCode, body.Error.Code, c.status, c.code)
+		}
+	}
+}
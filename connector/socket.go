@@ -0,0 +1,184 @@
+package connector
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	//socketQueue is how many frames may wait to be written to a socket, a peer whose
+	//messages overflow it being dropped
+	socketQueue = 256
+	//socketWriteWait bounds how long writing a frame may take before the socket is dropped
+	socketWriteWait = 10 * time.Second
+	//socketPongWait is how long a socket may stay silent, pings included, before it is dropped
+	socketPongWait   = 60 * time.Second
+	socketPingPeriod = socketPongWait * 9 / 10
+	//socketAuthWait is how long a socket may take to send its token
+	socketAuthWait = 10 * time.Second
+)
+
+//Each frame on a socket starts with a byte telling the stream it belongs to
+const (
+	messageFrame byte = iota
+	updateFrame
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: MaxStreamChunkSize,
+	CheckOrigin:     func(*http.Request) bool { return true },
+}
+
+//socketTransport carries both streams of a peer over one WebSocket, for players whose
+//network blocks WebRTC. Frames are queued and written by a goroutine of their own.
+type socketTransport struct {
+	conn   *websocket.Conn
+	frames chan []byte
+	mutex  *sync.Mutex
+	closed bool
+}
+
+func (t *socketTransport) sendMessage(data []byte) {
+	t.send(messageFrame, data, true)
+}
+
+func (t *socketTransport) sendUpdate(data []byte) {
+	t.send(updateFrame, data, false)
+}
+
+//send queues a frame. When the queue is full updates are dropped, as they may be over
+//WebRTC, while a message drops the peer, which cannot be kept up to date any more and
+//must not hold up the sender.
+func (t *socketTransport) send(stream byte, data []byte, reliable bool) {
+	f := make([]byte, len(data)+1)
+	f[0] = stream
+	copy(f[1:], data)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.closed {
+		return
+	}
+	select {
+	case t.frames <- f:
+	default:
+		if reliable {
+			//closing the connection ends the read loop of serveSocket, which disconnects the peer
+			t.closed = true
+			close(t.frames)
+			t.conn.Close()
+		}
+	}
+}
+
+//close ends the socket once the frames queued so far are written
+func (t *socketTransport) close() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if !t.closed {
+		t.closed = true
+		close(t.frames)
+	}
+}
+
+//write writes queued frames and pings until the socket is closed. After a failed write
+//frames are still taken from the queue so senders never block on a dead socket.
+func (t *socketTransport) write() {
+	ticker := time.NewTicker(socketPingPeriod)
+	defer ticker.Stop()
+	failed := false
+	fail := func() {
+		failed = true
+		t.conn.Close()
+	}
+	for {
+		select {
+		case f, ok := <-t.frames:
+			if !ok {
+				t.conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(socketWriteWait))
+				t.conn.Close()
+				return
+			}
+			if failed {
+				continue
+			}
+			t.conn.SetWriteDeadline(time.Now().Add(socketWriteWait))
+			if err := t.conn.WriteMessage(websocket.BinaryMessage, f); err != nil {
+				fail()
+			}
+		case <-ticker.C:
+			if failed {
+				continue
+			}
+			if err := t.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(socketWriteWait)); err != nil {
+				fail()
+			}
+		}
+	}
+}
+
+//serveSocket upgrades a request to a WebSocket and serves a peer over it until it
+//disconnects. Its first frame must be the token of the player, as browsers cannot set
+//headers on WebSockets; a player turned away is told why in the close frame, with the
+//HTTP status plus 4000 as its code.
+func (c *Connector) serveSocket(w http.ResponseWriter, r *http.Request) {
+	//Upgrade replies with an error itself
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn.SetReadLimit(maxRequestSize)
+	conn.SetReadDeadline(time.Now().Add(socketAuthWait))
+	kind, token, err := conn.ReadMessage()
+	if err != nil {
+		conn.Close()
+		return
+	}
+	if kind != websocket.TextMessage {
+		token = nil
+	}
+	player, aerr := c.authenticate(string(token))
+	if aerr != nil {
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(4000+aerr.Status, aerr.Message), time.Now().Add(socketWriteWait))
+		conn.Close()
+		return
+	}
+	t := &socketTransport{conn: conn, frames: make(chan []byte, socketQueue), mutex: new(sync.Mutex)}
+	p := newPeer(t, player)
+	go t.write()
+	c.peersMutex.Lock()
+	c.sockets[p] = t
+	c.peersMutex.Unlock()
+	c.connectedCallback(p)
+
+	conn.SetReadDeadline(time.Now().Add(socketPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(socketPongWait))
+	})
+	for {
+		kind, b, err := conn.ReadMessage()
+		if err != nil {
+			break
+		}
+		if kind != websocket.BinaryMessage || len(b) == 0 {
+			continue
+		}
+		switch b[0] {
+		case messageFrame:
+			p.message(b[1:])
+		case updateFrame:
+			p.update(b[1:])
+		}
+	}
+
+	c.peersMutex.Lock()
+	delete(c.sockets, p)
+	c.peersMutex.Unlock()
+	t.close()
+	c.disconnectedCallback(p)
+}
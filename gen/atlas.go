@@ -0,0 +1,150 @@
+package model
+
+import (
+	"goworld/pb"
+	"image"
+	"image/draw"
+	"sort"
+
+	"github.com/go-errors/errors"
+	"github.com/golang/protobuf/proto"
+)
+
+//maxAtlasSize is the largest atlas side PackAtlas will produce
+const maxAtlasSize = 4096
+
+//PackAtlas packs images into a square power of two atlas, sorted into shelves by
+//height. Every image is surrounded by padding pixels that repeat its border so
+//that filtering and mipmaps do not bleed its neighbours into it.
+//The UV rectangle of each image is returned as (u0, v0, u1, v1) with V running up the atlas.
+func PackAtlas(images []image.Image, padding int) (*image.RGBA, [][4]float64, error) {
+	order := make([]int, len(images))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return images[order[a]].Bounds().Dy() > images[order[b]].Bounds().Dy()
+	})
+	size := 1
+	for _, img := range images {
+		for size < img.Bounds().Dx()+2*padding || size < img.Bounds().Dy()+2*padding {
+			size *= 2
+		}
+	}
+	var origins []image.Point
+	for origins = shelves(images, order, padding, size); origins == nil; origins = shelves(images, order, padding, size) {
+		size *= 2
+		if size > maxAtlasSize {
+			return nil, nil, errors.Errorf("textures do not fit in a %dx%d atlas", maxAtlasSize, maxAtlasSize)
+		}
+	}
+	atlas := image.NewRGBA(image.Rect(0, 0, size, size))
+	rects := make([][4]float64, len(images))
+	for i, img := range images {
+		b := img.Bounds()
+		o := origins[i]
+		for y := -padding; y < b.Dy()+padding; y++ {
+			for x := -padding; x < b.Dx()+padding; x++ {
+				sx, sy := clamp(x, 0, b.Dx()-1), clamp(y, 0, b.Dy()-1)
+				atlas.Set(o.X+x, o.Y+y, img.At(b.Min.X+sx, b.Min.Y+sy))
+			}
+		}
+		s := float64(size)
+		rects[i] = [4]float64{float64(o.X) / s, 1 - float64(o.Y+b.Dy())/s, float64(o.X+b.Dx()) / s, 1 - float64(o.Y)/s}
+	}
+	return atlas, rects, nil
+}
+
+//shelves places images left to right in rows as tall as their first image,
+//returning the top left corner of each or nil if they overflow the atlas
+func shelves(images []image.Image, order []int, padding int, size int) []image.Point {
+	origins := make([]image.Point, len(images))
+	x, y, shelf := 0, 0, 0
+	for _, i := range order {
+		w, h := images[i].Bounds().Dx()+2*padding, images[i].Bounds().Dy()+2*padding
+		if x+w > size {
+			x, y, shelf = 0, y+shelf, 0
+		}
+		if shelf == 0 {
+			shelf = h
+		}
+		if y+h > size {
+			return nil
+		}
+		origins[i] = image.Point{X: x + padding, Y: y + padding}
+		x += w
+	}
+	return origins
+}
+
+func max(a, b int) int {
+	if a >= b {
+		return a
+	}
+	return b
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+//RemapUVs returns a copy of a mesh with its texture coordinates moved into a rectangle of an atlas.
+//The mesh must have UVs within [0, 1], since repeating textures cannot be packed.
+func RemapUVs(m *pb.Mesh, rect [4]float64) (*pb.Mesh, error) {
+	if !hasUVs(m) {
+		return nil, errors.Errorf("mesh has no UVs to remap")
+	}
+	r := proto.Clone(m).(*pb.Mesh)
+	for _, f := range r.Faces {
+		for i, v := range f.Uvs {
+			if v < 0 || v > 1 {
+				return nil, errors.Errorf("UV %v outside [0, 1]", v)
+			}
+			if i%2 == 0 {
+				f.Uvs[i] = rect[0] + v*(rect[2]-rect[0])
+			} else {
+				f.Uvs[i] = rect[1] + v*(rect[3]-rect[1])
+			}
+		}
+	}
+	return r, nil
+}
+
+//Mipmaps halves an image with a box filter until it is a single pixel wide and
+//high, returning every level after the image itself
+func Mipmaps(img image.Image) []image.Image {
+	levels := []image.Image{}
+	src := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+	draw.Draw(src, src.Bounds(), img, img.Bounds().Min, draw.Src)
+	for src.Bounds().Dx() > 1 || src.Bounds().Dy() > 1 {
+		w, h := max(src.Bounds().Dx()/2, 1), max(src.Bounds().Dy()/2, 1)
+		dst := image.NewRGBA(image.Rect(0, 0, w, h))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				var sum [4]int
+				n := 0
+				for _, p := range [4]image.Point{{2 * x, 2 * y}, {2*x + 1, 2 * y}, {2 * x, 2*y + 1}, {2*x + 1, 2*y + 1}} {
+					if p.X >= src.Bounds().Dx() || p.Y >= src.Bounds().Dy() {
+						continue
+					}
+					c := src.RGBAAt(p.X, p.Y)
+					sum[0], sum[1], sum[2], sum[3] = sum[0]+int(c.R), sum[1]+int(c.G), sum[2]+int(c.B), sum[3]+int(c.A)
+					n++
+				}
+				dst.Pix[dst.PixOffset(x, y)+0] = uint8((sum[0] + n/2) / n)
+				dst.Pix[dst.PixOffset(x, y)+1] = uint8((sum[1] + n/2) / n)
+				dst.Pix[dst.PixOffset(x, y)+2] = uint8((sum[2] + n/2) / n)
+				dst.Pix[dst.PixOffset(x, y)+3] = uint8((sum[3] + n/2) / n)
+			}
+		}
+		levels = append(levels, dst)
+		src = dst
+	}
+	return levels
+}
@@ -0,0 +1,356 @@
+package model
+
+import (
+	"goworld/pb"
+	"math"
+)
+
+//csgEpsilon is the distance below which points are considered to lie on a plane
+const csgEpsilon = 1e-5
+
+type csgVertex struct {
+	pos    [3]float64
+	normal [3]float64
+	uv     [2]float64
+}
+
+type csgPlane struct {
+	normal [3]float64
+	w      float64
+}
+
+type csgPolygon struct {
+	vertices []csgVertex
+	plane    csgPlane
+}
+
+type csgNode struct {
+	plane    *csgPlane
+	front    *csgNode
+	back     *csgNode
+	polygons []csgPolygon
+}
+
+func sub3(a, b [3]float64) [3]float64 {
+	return [3]float64{a[0] - b[0], a[1] - b[1], a[2] - b[2]}
+}
+
+func dot3(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func cross3(a, b [3]float64) [3]float64 {
+	return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+
+func lerp3(a, b [3]float64, t float64) [3]float64 {
+	return [3]float64{a[0] + (b[0]-a[0])*t, a[1] + (b[1]-a[1])*t, a[2] + (b[2]-a[2])*t}
+}
+
+func (v csgVertex) lerp(o csgVertex, t float64) csgVertex {
+	return csgVertex{
+		pos:    lerp3(v.pos, o.pos, t),
+		normal: normalize(lerp3(v.normal, o.normal, t)),
+		uv:     [2]float64{v.uv[0] + (o.uv[0]-v.uv[0])*t, v.uv[1] + (o.uv[1]-v.uv[1])*t},
+	}
+}
+
+func (p csgPlane) flip() csgPlane {
+	return csgPlane{normal: [3]float64{-p.normal[0], -p.normal[1], -p.normal[2]}, w: -p.w}
+}
+
+func (p csgPolygon) flip() csgPolygon {
+	n := len(p.vertices)
+	vs := make([]csgVertex, n)
+	for i, v := range p.vertices {
+		v.normal = [3]float64{-v.normal[0], -v.normal[1], -v.normal[2]}
+		vs[n-1-i] = v
+	}
+	return csgPolygon{vertices: vs, plane: p.plane.flip()}
+}
+
+//split sorts a polygon into the lists matching its side of the plane,
+//cutting it in two if it spans the plane
+func (p csgPlane) split(poly csgPolygon, coplanarFront, coplanarBack, front, back *[]csgPolygon) {
+	const (
+		coplanar = 0
+		inFront  = 1
+		behind   = 2
+		spanning = 3
+	)
+	kind := 0
+	types := make([]int, len(poly.vertices))
+	for i, v := range poly.vertices {
+		t := dot3(p.normal, v.pos) - p.w
+		switch {
+		case t < -csgEpsilon:
+			types[i] = behind
+		case t > csgEpsilon:
+			types[i] = inFront
+		}
+		kind |= types[i]
+	}
+	switch kind {
+	case coplanar:
+		if dot3(p.normal, poly.plane.normal) > 0 {
+			*coplanarFront = append(*coplanarFront, poly)
+		} else {
+			*coplanarBack = append(*coplanarBack, poly)
+		}
+	case inFront:
+		*front = append(*front, poly)
+	case behind:
+		*back = append(*back, poly)
+	case spanning:
+		f, b := []csgVertex{}, []csgVertex{}
+		for i, v := range poly.vertices {
+			j := (i + 1) % len(poly.vertices)
+			ti, tj := types[i], types[j]
+			if ti != behind {
+				f = append(f, v)
+			}
+			if ti != inFront {
+				b = append(b, v)
+			}
+			if ti|tj == spanning {
+				w := poly.vertices[j]
+				t := (p.w - dot3(p.normal, v.pos)) / dot3(p.normal, sub3(w.pos, v.pos))
+				m := v.lerp(w, t)
+				f = append(f, m)
+				b = append(b, m)
+			}
+		}
+		if len(f) >= 3 {
+			*front = append(*front, csgPolygon{vertices: f, plane: poly.plane})
+		}
+		if len(b) >= 3 {
+			*back = append(*back, csgPolygon{vertices: b, plane: poly.plane})
+		}
+	}
+}
+
+func newCSGNode(polygons []csgPolygon) *csgNode {
+	n := new(csgNode)
+	if len(polygons) > 0 {
+		n.build(polygons)
+	}
+	return n
+}
+
+func (n *csgNode) invert() {
+	for i := range n.polygons {
+		n.polygons[i] = n.polygons[i].flip()
+	}
+	if n.plane != nil {
+		p := n.plane.flip()
+		n.plane = &p
+	}
+	if n.front != nil {
+		n.front.invert()
+	}
+	if n.back != nil {
+		n.back.invert()
+	}
+	n.front, n.back = n.back, n.front
+}
+
+//clipPolygons removes the parts of polygons inside the solid of this tree
+func (n *csgNode) clipPolygons(polygons []csgPolygon) []csgPolygon {
+	if n.plane == nil {
+		return append([]csgPolygon{}, polygons...)
+	}
+	front, back := []csgPolygon{}, []csgPolygon{}
+	for _, p := range polygons {
+		n.plane.split(p, &front, &back, &front, &back)
+	}
+	if n.front != nil {
+		front = n.front.clipPolygons(front)
+	}
+	if n.back != nil {
+		back = n.back.clipPolygons(back)
+	} else {
+		back = nil
+	}
+	return append(front, back...)
+}
+
+//clipTo removes the parts of this tree inside the solid of o
+func (n *csgNode) clipTo(o *csgNode) {
+	n.polygons = o.clipPolygons(n.polygons)
+	if n.front != nil {
+		n.front.clipTo(o)
+	}
+	if n.back != nil {
+		n.back.clipTo(o)
+	}
+}
+
+func (n *csgNode) allPolygons() []csgPolygon {
+	polygons := append([]csgPolygon{}, n.polygons...)
+	if n.front != nil {
+		polygons = append(polygons, n.front.allPolygons()...)
+	}
+	if n.back != nil {
+		polygons = append(polygons, n.back.allPolygons()...)
+	}
+	return polygons
+}
+
+func (n *csgNode) build(polygons []csgPolygon) {
+	if len(polygons) == 0 {
+		return
+	}
+	if n.plane == nil {
+		p := polygons[0].plane
+		n.plane = &p
+	}
+	front, back := []csgPolygon{}, []csgPolygon{}
+	for _, p := range polygons {
+		n.plane.split(p, &n.polygons, &n.polygons, &front, &back)
+	}
+	if len(front) > 0 {
+		if n.front == nil {
+			n.front = new(csgNode)
+		}
+		n.front.build(front)
+	}
+	if len(back) > 0 {
+		if n.back == nil {
+			n.back = new(csgNode)
+		}
+		n.back.build(back)
+	}
+}
+
+//csgPolygons turns the faces of a mesh into polygons, computing flat normals
+//when the mesh has none
+func csgPolygons(m *pb.Mesh) []csgPolygon {
+	polygons := []csgPolygon{}
+	count := uint64(len(m.Vertices) / 3)
+	normals := len(m.Normals) == len(m.Vertices)
+	for _, f := range m.Faces {
+		if f.A >= count || f.B >= count || f.C >= count {
+			continue
+		}
+		vs := make([]csgVertex, 3)
+		for k, i := range []uint64{f.A, f.B, f.C} {
+			vs[k].pos = [3]float64{m.Vertices[i*3], m.Vertices[i*3+1], m.Vertices[i*3+2]}
+			if normals {
+				vs[k].normal = [3]float64{m.Normals[i*3], m.Normals[i*3+1], m.Normals[i*3+2]}
+			}
+			if len(f.Uvs) == 6 {
+				vs[k].uv = [2]float64{f.Uvs[k*2], f.Uvs[k*2+1]}
+			}
+		}
+		n := cross3(sub3(vs[1].pos, vs[0].pos), sub3(vs[2].pos, vs[0].pos))
+		if math.Sqrt(dot3(n, n)) < csgEpsilon*csgEpsilon {
+			continue
+		}
+		n = normalize(n)
+		if !normals {
+			for k := range vs {
+				vs[k].normal = n
+			}
+		}
+		polygons = append(polygons, csgPolygon{vertices: vs, plane: csgPlane{normal: n, w: dot3(n, vs[0].pos)}})
+	}
+	return polygons
+}
+
+func hasUVs(m *pb.Mesh) bool {
+	for _, f := range m.Faces {
+		if len(f.Uvs) != 6 {
+			return false
+		}
+	}
+	return len(m.Faces) > 0
+}
+
+//csgMesh fans polygons back into triangles, sharing identical vertices
+func csgMesh(polygons []csgPolygon, uvs bool) *pb.Mesh {
+	m := &pb.Mesh{
+		Vertices: []float64{},
+		Normals:  []float64{},
+	}
+	vmap := map[[8]float64]uint64{}
+	vertex := func(v csgVertex) uint64 {
+		k := [8]float64{v.pos[0], v.pos[1], v.pos[2], v.normal[0], v.normal[1], v.normal[2]}
+		if uvs {
+			k[6], k[7] = v.uv[0], v.uv[1]
+		}
+		if i, ok := vmap[k]; ok {
+			return i
+		}
+		i := uint64(len(m.Vertices) / 3)
+		m.Vertices = append(m.Vertices, v.pos[0], v.pos[1], v.pos[2])
+		m.Normals = append(m.Normals, v.normal[0], v.normal[1], v.normal[2])
+		vmap[k] = i
+		return i
+	}
+	for _, p := range polygons {
+		for i := 2; i < len(p.vertices); i++ {
+			a, b, c := p.vertices[0], p.vertices[i-1], p.vertices[i]
+			f := &pb.Mesh_Face{A: vertex(a), B: vertex(b), C: vertex(c)}
+			if uvs {
+				f.Uvs = []float64{a.uv[0], a.uv[1], b.uv[0], b.uv[1], c.uv[0], c.uv[1]}
+			}
+			m.Faces = append(m.Faces, f)
+		}
+	}
+	return m
+}
+
+//Union returns the volume covered by either mesh
+func Union(a, b *pb.Mesh) (*pb.Mesh, Shape) {
+	x, y := newCSGNode(csgPolygons(a)), newCSGNode(csgPolygons(b))
+	x.clipTo(y)
+	y.clipTo(x)
+	y.invert()
+	y.clipTo(x)
+	y.invert()
+	x.build(y.allPolygons())
+	return csgMesh(x.allPolygons(), hasUVs(a) && hasUVs(b)), Shape{Type: pb.Body_MESH}
+}
+
+//Difference returns the volume of a that is not covered by b
+func Difference(a, b *pb.Mesh) (*pb.Mesh, Shape) {
+	x, y := newCSGNode(csgPolygons(a)), newCSGNode(csgPolygons(b))
+	x.invert()
+	x.clipTo(y)
+	y.clipTo(x)
+	y.invert()
+	y.clipTo(x)
+	y.invert()
+	x.build(y.allPolygons())
+	x.invert()
+	return csgMesh(x.allPolygons(), hasUVs(a) && hasUVs(b)), Shape{Type: pb.Body_MESH}
+}
+
+//Intersection returns the volume covered by both meshes
+func Intersection(a, b *pb.Mesh) (*pb.Mesh, Shape) {
+	x, y := newCSGNode(csgPolygons(a)), newCSGNode(csgPolygons(b))
+	x.invert()
+	y.clipTo(x)
+	y.invert()
+	x.clipTo(y)
+	y.clipTo(x)
+	x.build(y.allPolygons())
+	x.invert()
+	return csgMesh(x.allPolygons(), hasUVs(a) && hasUVs(b)), Shape{Type: pb.Body_MESH}
+}
+
+//Translate returns a copy of a mesh moved by the given offset
+func Translate(m *pb.Mesh, x, y, z float64) *pb.Mesh {
+	t := &pb.Mesh{
+		Vertices: make([]float64, len(m.Vertices)),
+		Normals:  append([]float64{}, m.Normals...),
+		Faces:    make([]*pb.Mesh_Face, len(m.Faces)),
+	}
+	for i, v := range m.Vertices {
+		t.Vertices[i] = v + [3]float64{x, y, z}[i%3]
+	}
+	for i, f := range m.Faces {
+		t.Faces[i] = &pb.Mesh_Face{A: f.A, B: f.B, C: f.C, Uvs: append([]float64{}, f.Uvs...)}
+	}
+	return t
+}
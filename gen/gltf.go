@@ -0,0 +1,410 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"goworld/pb"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+type gltfAccessor struct {
+	BufferView    *int   `json:"bufferView"`
+	ByteOffset    int    `json:"byteOffset"`
+	ComponentType int    `json:"componentType"`
+	Normalized    bool   `json:"normalized"`
+	Count         int    `json:"count"`
+	Type          string `json:"type"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices"`
+	Mode       *int           `json:"mode"`
+}
+
+type gltfNode struct {
+	Mesh        *int      `json:"mesh"`
+	Children    []int     `json:"children"`
+	Matrix      []float64 `json:"matrix"`
+	Translation []float64 `json:"translation"`
+	Rotation    []float64 `json:"rotation"`
+	Scale       []float64 `json:"scale"`
+}
+
+type gltfDocument struct {
+	Buffers []struct {
+		URI string `json:"uri"`
+	} `json:"buffers"`
+	BufferViews []gltfBufferView `json:"bufferViews"`
+	Accessors   []gltfAccessor   `json:"accessors"`
+	Meshes      []struct {
+		Primitives []gltfPrimitive `json:"primitives"`
+	} `json:"meshes"`
+	Nodes  []gltfNode `json:"nodes"`
+	Scenes []struct {
+		Nodes []int `json:"nodes"`
+	} `json:"scenes"`
+	Scene *int `json:"scene"`
+
+	buffers [][]byte
+}
+
+var gltfComponentSizes = map[int]int{5120: 1, 5121: 1, 5122: 2, 5123: 2, 5125: 4, 5126: 4}
+
+var gltfComponentCounts = map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4, "MAT2": 4, "MAT3": 9, "MAT4": 16}
+
+var identity = [16]float64{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1}
+
+//loadGLTF imports the triangles of every mesh in the default scene of a glTF or GLB file
+func loadGLTF(path string) (*pb.Mesh, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	var bin []byte
+	if len(b) >= 12 && string(b[:4]) == "glTF" {
+		b, bin, err = splitGLB(b)
+		if err != nil {
+			return nil, err
+		}
+	}
+	d := new(gltfDocument)
+	if err := json.Unmarshal(b, d); err != nil {
+		return nil, errors.Errorf("%s: %v", path, err)
+	}
+	for _, bf := range d.Buffers {
+		var data []byte
+		switch {
+		case bf.URI == "":
+			data = bin
+		case strings.HasPrefix(bf.URI, "data:"):
+			data, err = base64.StdEncoding.DecodeString(bf.URI[strings.Index(bf.URI, ",")+1:])
+		default:
+			var name string
+			name, err = url.PathUnescape(bf.URI)
+			if err == nil {
+				data, err = ioutil.ReadFile(filepath.Join(filepath.Dir(path), name))
+			}
+		}
+		if err != nil {
+			return nil, errors.Errorf("%s: %v", path, err)
+		}
+		d.buffers = append(d.buffers, data)
+	}
+	m := &pb.Mesh{
+		Vertices: []float64{},
+		Normals:  []float64{},
+	}
+	roots := []int{}
+	switch {
+	case d.Scene != nil && *d.Scene < len(d.Scenes):
+		roots = d.Scenes[*d.Scene].Nodes
+	case len(d.Scenes) > 0:
+		roots = d.Scenes[0].Nodes
+	case len(d.Nodes) == 0:
+		for i := range d.Meshes {
+			if err := d.addMesh(m, i, identity); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+	for _, n := range roots {
+		if err := d.addNode(m, n, identity, 0); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func splitGLB(b []byte) ([]byte, []byte, error) {
+	var doc, bin []byte
+	for o := 12; o+8 <= len(b); {
+		l := int(binary.LittleEndian.Uint32(b[o:]))
+		t := binary.LittleEndian.Uint32(b[o+4:])
+		if o+8+l > len(b) {
+			return nil, nil, errors.New("truncated GLB chunk")
+		}
+		switch t {
+		case 0x4E4F534A:
+			doc = b[o+8 : o+8+l]
+		case 0x004E4942:
+			bin = b[o+8 : o+8+l]
+		}
+		o += 8 + l
+	}
+	if doc == nil {
+		return nil, nil, errors.New("GLB without a JSON chunk")
+	}
+	return doc, bin, nil
+}
+
+func (d *gltfDocument) addNode(m *pb.Mesh, i int, parent [16]float64, depth int) error {
+	if i >= len(d.Nodes) || depth > 64 {
+		return errors.Errorf("invalid node %d", i)
+	}
+	n := d.Nodes[i]
+	t := mul4(parent, n.transform())
+	if n.Mesh != nil {
+		if err := d.addMesh(m, *n.Mesh, t); err != nil {
+			return err
+		}
+	}
+	for _, c := range n.Children {
+		if err := d.addNode(m, c, t, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n gltfNode) transform() [16]float64 {
+	if len(n.Matrix) == 16 {
+		var t [16]float64
+		copy(t[:], n.Matrix)
+		return t
+	}
+	t := identity
+	if len(n.Scale) == 3 {
+		t = mul4([16]float64{n.Scale[0], 0, 0, 0, 0, n.Scale[1], 0, 0, 0, 0, n.Scale[2], 0, 0, 0, 0, 1}, t)
+	}
+	if len(n.Rotation) == 4 {
+		x, y, z, w := n.Rotation[0], n.Rotation[1], n.Rotation[2], n.Rotation[3]
+		t = mul4([16]float64{
+			1 - 2*(y*y+z*z), 2 * (x*y + z*w), 2 * (x*z - y*w), 0,
+			2 * (x*y - z*w), 1 - 2*(x*x+z*z), 2 * (y*z + x*w), 0,
+			2 * (x*z + y*w), 2 * (y*z - x*w), 1 - 2*(x*x+y*y), 0,
+			0, 0, 0, 1,
+		}, t)
+	}
+	if len(n.Translation) == 3 {
+		t = mul4([16]float64{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, n.Translation[0], n.Translation[1], n.Translation[2], 1}, t)
+	}
+	return t
+}
+
+//mul4 multiplies two column-major 4x4 matrices
+func mul4(a, b [16]float64) [16]float64 {
+	var r [16]float64
+	for c := 0; c < 4; c++ {
+		for k := 0; k < 4; k++ {
+			for j := 0; j < 4; j++ {
+				r[c*4+j] += a[k*4+j] * b[c*4+k]
+			}
+		}
+	}
+	return r
+}
+
+//normalMatrix returns the inverse transpose of the upper 3x3 of a column-major 4x4 matrix,
+//which keeps normals perpendicular to surfaces under non-uniform scale, and whether the
+//matrix mirrors, which turns triangles inside out. It is left unscaled by the determinant
+//as normals are normalized afterwards, but keeps its sign.
+func normalMatrix(t [16]float64) (n [9]float64, mirrors bool) {
+	cross := func(a, b [3]float64) [3]float64 {
+		return [3]float64{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+	}
+	c0, c1, c2 := [3]float64{t[0], t[1], t[2]}, [3]float64{t[4], t[5], t[6]}, [3]float64{t[8], t[9], t[10]}
+	r0, r1, r2 := cross(c1, c2), cross(c2, c0), cross(c0, c1)
+	det := c0[0]*r0[0] + c0[1]*r0[1] + c0[2]*r0[2]
+	s := 1.0
+	if det < 0 {
+		s = -1
+	}
+	for i := 0; i < 3; i++ {
+		n[i], n[3+i], n[6+i] = s*r0[i], s*r1[i], s*r2[i]
+	}
+	return n, det < 0
+}
+
+func (d *gltfDocument) addMesh(m *pb.Mesh, i int, t [16]float64) error {
+	if i >= len(d.Meshes) {
+		return errors.Errorf("invalid mesh %d", i)
+	}
+	for _, p := range d.Meshes[i].Primitives {
+		if p.Mode != nil && *p.Mode != 4 {
+			continue
+		}
+		if err := d.addPrimitive(m, p, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *gltfDocument) addPrimitive(m *pb.Mesh, p gltfPrimitive, t [16]float64) error {
+	pa, ok := p.Attributes["POSITION"]
+	if !ok {
+		return errors.New("primitive without positions")
+	}
+	positions, err := d.values(pa, 3)
+	if err != nil {
+		return err
+	}
+	count := len(positions) / 3
+	var normals, uvs, indices []float64
+	if a, ok := p.Attributes["NORMAL"]; ok {
+		if normals, err = d.values(a, 3); err != nil {
+			return err
+		}
+	}
+	if a, ok := p.Attributes["TEXCOORD_0"]; ok {
+		if uvs, err = d.values(a, 2); err != nil {
+			return err
+		}
+	}
+	if p.Indices != nil {
+		if indices, err = d.values(*p.Indices, 1); err != nil {
+			return err
+		}
+	} else {
+		for i := 0; i < count; i++ {
+			indices = append(indices, float64(i))
+		}
+	}
+	if len(normals) != len(positions) {
+		normals = vertexNormals(positions, indices)
+	}
+	base := uint64(len(m.Vertices) / 3)
+	n, mirrors := normalMatrix(t)
+	for i := 0; i < count; i++ {
+		x, y, z := positions[i*3], positions[i*3+1], positions[i*3+2]
+		m.Vertices = append(m.Vertices,
+			t[0]*x+t[4]*y+t[8]*z+t[12],
+			t[1]*x+t[5]*y+t[9]*z+t[13],
+			t[2]*x+t[6]*y+t[10]*z+t[14])
+		x, y, z = normals[i*3], normals[i*3+1], normals[i*3+2]
+		nx, ny, nz := n[0]*x+n[3]*y+n[6]*z, n[1]*x+n[4]*y+n[7]*z, n[2]*x+n[5]*y+n[8]*z
+		l := math.Sqrt(nx*nx + ny*ny + nz*nz)
+		if l > 0 {
+			nx, ny, nz = nx/l, ny/l, nz/l
+		}
+		m.Normals = append(m.Normals, nx, ny, nz)
+	}
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := uint64(indices[i]), uint64(indices[i+1]), uint64(indices[i+2])
+		if a >= uint64(count) || b >= uint64(count) || c >= uint64(count) {
+			return errors.Errorf("index out of range")
+		}
+		if mirrors {
+			b, c = c, b
+		}
+		f := &pb.Mesh_Face{A: base + a, B: base + b, C: base + c}
+		if len(uvs) == count*2 {
+			//glTF puts the texture origin in the top left corner
+			f.Uvs = []float64{uvs[a*2], 1 - uvs[a*2+1], uvs[b*2], 1 - uvs[b*2+1], uvs[c*2], 1 - uvs[c*2+1]}
+		}
+		m.Faces = append(m.Faces, f)
+	}
+	return nil
+}
+
+//values reads an accessor as float64s, checking that it has the expected component count
+func (d *gltfDocument) values(i int, components int) ([]float64, error) {
+	if i >= len(d.Accessors) {
+		return nil, errors.Errorf("invalid accessor %d", i)
+	}
+	a := d.Accessors[i]
+	n, size := gltfComponentCounts[a.Type], gltfComponentSizes[a.ComponentType]
+	if n != components || size == 0 {
+		return nil, errors.Errorf("accessor %d: unsupported layout %s/%d", i, a.Type, a.ComponentType)
+	}
+	out := make([]float64, a.Count*n)
+	if a.BufferView == nil {
+		return out, nil
+	}
+	if *a.BufferView >= len(d.BufferViews) {
+		return nil, errors.Errorf("accessor %d: invalid buffer view", i)
+	}
+	v := d.BufferViews[*a.BufferView]
+	if v.Buffer >= len(d.buffers) {
+		return nil, errors.Errorf("accessor %d: invalid buffer", i)
+	}
+	b := d.buffers[v.Buffer]
+	stride := v.ByteStride
+	if stride == 0 {
+		stride = n * size
+	}
+	for e := 0; e < a.Count; e++ {
+		for c := 0; c < n; c++ {
+			o := v.ByteOffset + a.ByteOffset + e*stride + c*size
+			if o+size > len(b) {
+				return nil, errors.Errorf("accessor %d: out of bounds", i)
+			}
+			out[e*n+c] = component(b[o:], a.ComponentType, a.Normalized)
+		}
+	}
+	return out, nil
+}
+
+func component(b []byte, t int, normalized bool) float64 {
+	switch t {
+	case 5120:
+		if normalized {
+			return math.Max(float64(int8(b[0]))/127, -1)
+		}
+		return float64(int8(b[0]))
+	case 5121:
+		if normalized {
+			return float64(b[0]) / 255
+		}
+		return float64(b[0])
+	case 5122:
+		v := int16(binary.LittleEndian.Uint16(b))
+		if normalized {
+			return math.Max(float64(v)/32767, -1)
+		}
+		return float64(v)
+	case 5123:
+		v := binary.LittleEndian.Uint16(b)
+		if normalized {
+			return float64(v) / 65535
+		}
+		return float64(v)
+	case 5125:
+		return float64(binary.LittleEndian.Uint32(b))
+	}
+	return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+}
+
+//vertexNormals averages the face normals around each vertex, weighted by face area
+func vertexNormals(positions []float64, indices []float64) []float64 {
+	normals := make([]float64, len(positions))
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := int(indices[i])*3, int(indices[i+1])*3, int(indices[i+2])*3
+		if a+2 >= len(positions) || b+2 >= len(positions) || c+2 >= len(positions) {
+			continue
+		}
+		ux, uy, uz := positions[b]-positions[a], positions[b+1]-positions[a+1], positions[b+2]-positions[a+2]
+		vx, vy, vz := positions[c]-positions[a], positions[c+1]-positions[a+1], positions[c+2]-positions[a+2]
+		nx, ny, nz := uy*vz-uz*vy, uz*vx-ux*vz, ux*vy-uy*vx
+		for _, k := range []int{a, b, c} {
+			normals[k] += nx
+			normals[k+1] += ny
+			normals[k+2] += nz
+		}
+	}
+	for i := 0; i+2 < len(normals); i += 3 {
+		l := math.Sqrt(normals[i]*normals[i] + normals[i+1]*normals[i+1] + normals[i+2]*normals[i+2])
+		if l > 0 {
+			normals[i] /= l
+			normals[i+1] /= l
+			normals[i+2] /= l
+		}
+	}
+	return normals
+}
@@ -0,0 +1,230 @@
+package model
+
+import (
+	"goworld/pb"
+	"math"
+	"math/rand"
+)
+
+//BuildingStyle parameterises the shape grammar that generates buildings
+type BuildingStyle struct {
+	//Width and Depth bound the footprint, which is shrunk at random by up to a quarter
+	Width       float64
+	Depth       float64
+	MinFloors   int
+	MaxFloors   int
+	FloorHeight float64
+	//Bay is the width facades are split into windows and walls by
+	Bay          float64
+	WindowChance float64
+	//Roof is flat or gable
+	Roof string
+}
+
+//Buildings are ready made building styles
+var Buildings = map[string]BuildingStyle{
+	"cottage": {Width: 6, Depth: 5, MinFloors: 1, MaxFloors: 1, FloorHeight: 2.8, Bay: 1.6, WindowChance: 0.5, Roof: "gable"},
+	"house":   {Width: 8, Depth: 7, MinFloors: 1, MaxFloors: 2, FloorHeight: 3, Bay: 1.8, WindowChance: 0.7, Roof: "gable"},
+	"tower":   {Width: 4, Depth: 4, MinFloors: 3, MaxFloors: 5, FloorHeight: 3, Bay: 2, WindowChance: 0.4, Roof: "flat"},
+}
+
+//the bands of the palette texture buildings are coloured with
+const (
+	wallBand = iota
+	windowBand
+	roofBand
+	doorBand
+	buildingBands
+)
+
+//scope is the box a rule of a shape grammar works on. X runs along it, Y up and Z out
+//of it for facades, and Size is its extent along each axis from Origin.
+type scope struct {
+	Origin [3]float64
+	X      [3]float64
+	Y      [3]float64
+	Z      [3]float64
+	Size   [3]float64
+	//Floor counts floors from the ground and Front marks the facade with the door
+	Floor int
+	Front bool
+}
+
+//at returns the point of a scope at fractions of its size along each axis
+func (s scope) at(u, v, w float64) [3]float64 {
+	return madd3(madd3(madd3(s.Origin, s.X, u*s.Size[0]), s.Y, v*s.Size[1]), s.Z, w*s.Size[2])
+}
+
+//split cuts a scope along an axis into pieces of the given sizes
+func (s scope) split(axis int, sizes []float64) []scope {
+	dirs := [3][3]float64{s.X, s.Y, s.Z}
+	pieces := make([]scope, len(sizes))
+	offset := 0.0
+	for i, size := range sizes {
+		p := s
+		p.Origin = madd3(s.Origin, dirs[axis], offset)
+		p.Size[axis] = size
+		pieces[i] = p
+		offset += size
+	}
+	return pieces
+}
+
+//repeat splits a scope along an axis into as many equal pieces of about size as fit
+func (s scope) repeat(axis int, size float64) []scope {
+	n := math.Max(1, math.Round(s.Size[axis]/size))
+	sizes := make([]float64, int(n))
+	for i := range sizes {
+		sizes[i] = s.Size[axis] / n
+	}
+	return s.split(axis, sizes)
+}
+
+//facades returns the four sides of a box scope as flat scopes facing out, the first one
+//being the front, along -Z
+func (s scope) facades() []scope {
+	w, h, d := s.Size[0], s.Size[1], s.Size[2]
+	neg := func(v [3]float64) [3]float64 { return [3]float64{-v[0], -v[1], -v[2]} }
+	return []scope{
+		{Origin: s.at(0, 0, 0), X: s.X, Y: s.Y, Z: neg(s.Z), Size: [3]float64{w, h, 0}, Floor: s.Floor, Front: true},
+		{Origin: s.at(1, 0, 0), X: s.Z, Y: s.Y, Z: s.X, Size: [3]float64{d, h, 0}, Floor: s.Floor},
+		{Origin: s.at(1, 0, 1), X: neg(s.X), Y: s.Y, Z: s.Z, Size: [3]float64{w, h, 0}, Floor: s.Floor},
+		{Origin: s.at(0, 0, 1), X: neg(s.Z), Y: s.Y, Z: neg(s.X), Size: [3]float64{d, h, 0}, Floor: s.Floor},
+	}
+}
+
+//grammar derives shapes from symbols by its rules and builds the terminal ones
+type grammar struct {
+	rules map[string]func(s scope)
+	b     *builder
+}
+
+func (gr *grammar) derive(symbol string, s scope) {
+	if r, ok := gr.rules[symbol]; ok {
+		r(s)
+	}
+}
+
+//quad builds a flat scope as a quad facing along its Z, pushed out by lift
+func (gr *grammar) quad(s scope, band int, lift float64) {
+	uv := bandUV(band, buildingBands)
+	uvs := []float64{uv[0], uv[1], uv[0], uv[1], uv[0], uv[1]}
+	n := normalize(s.Z)
+	var idx [4]uint64
+	for i, c := range [4][2]float64{{0, 0}, {1, 0}, {1, 1}, {0, 1}} {
+		idx[i] = gr.b.vertex(madd3(s.at(c[0], c[1], 0), n, lift), n)
+	}
+	if dot3(cross3(s.X, s.Y), s.Z) < 0 {
+		idx[1], idx[3] = idx[3], idx[1]
+	}
+	gr.b.face(idx[0], idx[1], idx[2], uvs)
+	gr.b.face(idx[0], idx[2], idx[3], uvs)
+}
+
+//triangle builds a flat triangle with the normal of its winding
+func (gr *grammar) triangle(a, b, c [3]float64, band int) {
+	uv := bandUV(band, buildingBands)
+	uvs := []float64{uv[0], uv[1], uv[0], uv[1], uv[0], uv[1]}
+	n := normalize(cross3(sub3(b, a), sub3(c, a)))
+	gr.b.face(gr.b.vertex(a, n), gr.b.vertex(b, n), gr.b.vertex(c, n), uvs)
+}
+
+//Building derives a building of a style from a seed with a shape grammar: the lot is
+//shrunk into a mass, split into floors topped by a roof, each floor into four facades,
+//and each facade into bays holding a window, a plain wall or, on the ground floor of
+//the front, the door. The building stands on the origin, its front facing -Z, and
+//collides as a box for its floors and another for its roof.
+func Building(style BuildingStyle, seed int64) (*pb.Mesh, Shape) {
+	g := rand.New(rand.NewSource(seed))
+	gr := &grammar{b: newBuilder()}
+	floors := style.MinFloors
+	if style.MaxFloors > style.MinFloors {
+		floors += g.Intn(style.MaxFloors - style.MinFloors + 1)
+	}
+	w := style.Width * (0.75 + 0.25*g.Float64())
+	d := style.Depth * (0.75 + 0.25*g.Float64())
+	roofHeight := 0.0
+	if style.Roof == "gable" {
+		roofHeight = d * 0.4
+	}
+	mass := scope{
+		Origin: [3]float64{-w / 2, 0, -d / 2},
+		X:      [3]float64{1, 0, 0},
+		Y:      [3]float64{0, 1, 0},
+		Z:      [3]float64{0, 0, 1},
+		Size:   [3]float64{w, float64(floors) * style.FloorHeight, d},
+	}
+	gr.rules = map[string]func(s scope){
+		"Mass": func(s scope) {
+			for i, f := range s.repeat(1, style.FloorHeight) {
+				f.Floor = i
+				gr.derive("Floor", f)
+			}
+			roof := s
+			roof.Origin = s.at(0, 1, 0)
+			roof.Size[1] = roofHeight
+			gr.derive("Roof", roof)
+		},
+		"Floor": func(s scope) {
+			for _, f := range s.facades() {
+				gr.derive("Facade", f)
+			}
+		},
+		"Facade": func(s scope) {
+			bays := s.repeat(0, style.Bay)
+			for i, b := range bays {
+				switch {
+				case s.Front && s.Floor == 0 && i == len(bays)/2:
+					gr.derive("Door", b)
+				case g.Float64() < style.WindowChance:
+					gr.derive("Window", b)
+				default:
+					gr.derive("Wall", b)
+				}
+			}
+		},
+		"Wall": func(s scope) {
+			gr.quad(s, wallBand, 0)
+		},
+		"Window": func(s scope) {
+			gr.quad(s, wallBand, 0)
+			rows := s.split(1, []float64{s.Size[1] * 0.35, s.Size[1] * 0.4, s.Size[1] * 0.25})
+			cols := rows[1].split(0, []float64{s.Size[0] * 0.25, s.Size[0] * 0.5, s.Size[0] * 0.25})
+			gr.quad(cols[1], windowBand, 0.02)
+		},
+		"Door": func(s scope) {
+			gr.quad(s, wallBand, 0)
+			rows := s.split(1, []float64{s.Size[1] * 0.75, s.Size[1] * 0.25})
+			cols := rows[0].split(0, []float64{s.Size[0] * 0.2, s.Size[0] * 0.6, s.Size[0] * 0.2})
+			gr.quad(cols[1], doorBand, 0.02)
+		},
+		"Roof": func(s scope) {
+			if style.Roof != "gable" {
+				gr.quad(scope{Origin: s.Origin, X: s.X, Y: s.Z, Z: s.Y, Size: [3]float64{s.Size[0], s.Size[2], 0}}, roofBand, 0)
+				return
+			}
+			ridge0, ridge1 := s.at(0, 1, 0.5), s.at(1, 1, 0.5)
+			gr.triangle(s.at(0, 0, 0), ridge1, s.at(1, 0, 0), roofBand)
+			gr.triangle(s.at(0, 0, 0), ridge0, ridge1, roofBand)
+			gr.triangle(s.at(1, 0, 1), ridge0, s.at(0, 0, 1), roofBand)
+			gr.triangle(s.at(1, 0, 1), ridge1, ridge0, roofBand)
+			gr.triangle(s.at(0, 0, 1), ridge0, s.at(0, 0, 0), wallBand)
+			gr.triangle(s.at(1, 0, 0), ridge1, s.at(1, 0, 1), wallBand)
+		},
+	}
+	gr.derive("Mass", mass)
+	h := mass.Size[1]
+	parts := []*pb.Body{{
+		Type:   pb.Body_BOX,
+		Data:   []float64{w, h, d},
+		Offset: &pb.RelativeLocation{Y: h / 2},
+	}}
+	if roofHeight > 0 {
+		parts = append(parts, &pb.Body{
+			Type:   pb.Body_BOX,
+			Data:   []float64{w, roofHeight, d / 2},
+			Offset: &pb.RelativeLocation{Y: h + roofHeight/2},
+		})
+	}
+	return gr.b.mesh, Shape{Type: pb.Body_MESH, Parts: parts}
+}
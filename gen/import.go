@@ -0,0 +1,31 @@
+package model
+
+import (
+	"goworld/pb"
+	"path/filepath"
+	"strings"
+
+	"github.com/fogleman/fauxgl"
+	"github.com/go-errors/errors"
+)
+
+//Load imports an OBJ, STL or glTF file into a mesh
+func Load(path string) (*pb.Mesh, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".obj":
+		m, err := fauxgl.LoadOBJ(path)
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+		return convertMesh(m), nil
+	case ".stl":
+		m, err := fauxgl.LoadSTL(path)
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+		return convertMesh(m), nil
+	case ".gltf", ".glb":
+		return loadGLTF(path)
+	}
+	return nil, errors.Errorf("%s: unsupported mesh format", path)
+}
@@ -0,0 +1,327 @@
+package model
+
+import (
+	"goworld/gen/noise"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+//Biome is the kind of land a column of chunks belongs to
+type Biome int
+
+//Biomes
+const (
+	Plains Biome = iota
+	Forest
+	Desert
+	Mountains
+)
+
+var biomeNames = [...]string{"plains", "forest", "desert", "mountains"}
+
+func (b Biome) String() string {
+	return biomeNames[b]
+}
+
+//relief is the height profile a biome lends the terrain where it dominates
+type relief struct {
+	Base      float64
+	Amplitude float64
+}
+
+var biomeReliefs = [...]relief{
+	Plains:    {Base: -6, Amplitude: 4},
+	Forest:    {Base: -4, Amplitude: 8},
+	Desert:    {Base: -7, Amplitude: 3},
+	Mountains: {Base: 6, Amplitude: 30},
+}
+
+//PointsOfInterest names the point of interest each biome grows
+var PointsOfInterest = [...]string{
+	Plains:    "village",
+	Forest:    "camp",
+	Desert:    "ruin",
+	Mountains: "tower",
+}
+
+const (
+	//biomeSharpness is how quickly one biome gives way to the next, higher is more abrupt
+	biomeSharpness = 12
+	riverWidth     = 0.015
+	riverDepth     = 8
+	//pointChance is how likely a region is to have a point of interest
+	pointChance = 0.75
+	//regionCache is how many regions a layout keeps, as they can be computed again from the seed
+	regionCache = 256
+)
+
+//Column is the layout of a vertical column of chunks
+type Column struct {
+	Biome Biome
+	River bool
+	Road  bool
+	//Surface is the height of the ground at the centre of the column, before overhangs and caves
+	Surface float64
+}
+
+//PointOfInterest is a place worth building something on
+type PointOfInterest struct {
+	Kind     string
+	Column   [2]int64
+	Position [3]float64
+}
+
+//Region is the layout of a square of columns of chunks
+type Region struct {
+	X       int64
+	Z       int64
+	Columns []Column
+	Point   *PointOfInterest
+}
+
+//Layout is the structure of the world above the chunk level: biomes, rivers, roads and
+//points of interest. Biomes and rivers are smooth fields over the plane that the terrain
+//is shaped by. Each region of RegionSize by RegionSize chunk columns may have a point of
+//interest and roads join it to those of the regions around it. Regions are computed
+//lazily from the seed and the last regionCache used are kept.
+type Layout struct {
+	Seed       int64
+	ChunkSize  float64
+	RegionSize int64
+	relief     *noise.Noise
+	climate    *noise.Noise
+	rivers     *noise.Noise
+	regions    *lru
+	mutex      *sync.Mutex
+}
+
+//NewLayout returns the layout of a world
+func NewLayout(seed int64, chunkSize float64, regionSize int64) *Layout {
+	return &Layout{
+		Seed:       seed,
+		ChunkSize:  chunkSize,
+		RegionSize: regionSize,
+		relief:     noise.New(seed),
+		climate:    noise.New(seed + 1),
+		rivers:     noise.New(seed + 2),
+		regions:    newLRU(regionCache),
+		mutex:      new(sync.Mutex),
+	}
+}
+
+//weights returns how much each biome holds a point, summing to 1
+func (l *Layout) weights(x, z float64) [len(biomeNames)]float64 {
+	temperature := l.climate.FBM2(x/600, z/600, 3) * 2
+	moisture := l.climate.FBM2(x/400+37.5, z/400-11.3, 3) * 2
+	ruggedness := l.climate.FBM2(x/800-71.1, z/800+53.7, 2) * 2
+	scores := [len(biomeNames)]float64{
+		Plains:    0,
+		Forest:    moisture - 0.2,
+		Desert:    temperature - moisture - 0.4,
+		Mountains: ruggedness - 0.3,
+	}
+	var w [len(biomeNames)]float64
+	sum := 0.0
+	for i, s := range scores {
+		w[i] = math.Exp(s * biomeSharpness)
+		sum += w[i]
+	}
+	for i := range w {
+		w[i] /= sum
+	}
+	return w
+}
+
+//Biome returns the biome holding a point
+func (l *Layout) Biome(x, z float64) Biome {
+	w := l.weights(x, z)
+	b := Plains
+	for i := range w {
+		if w[i] > w[b] {
+			b = Biome(i)
+		}
+	}
+	return b
+}
+
+//riverField is the noise whose zero crossings rivers run along
+func (l *Layout) riverField(x, z float64) float64 {
+	return l.rivers.FBM2(x/800, z/800, 2)
+}
+
+//river returns how close a point is to the middle of a river, 1 on it and 0 at its banks and beyond
+func (l *Layout) river(x, z float64) float64 {
+	v := math.Abs(l.riverField(x, z)) / riverWidth
+	if v >= 1 {
+		return 0
+	}
+	return 1 - v*v
+}
+
+//Surface returns the height of the ground, blending the reliefs of the biomes around
+//and lowered along rivers, before overhangs and caves
+func (l *Layout) Surface(x, z float64) float64 {
+	w := l.weights(x, z)
+	base, amplitude := 0.0, 0.0
+	for i, r := range biomeReliefs {
+		base += w[i] * r.Base
+		amplitude += w[i] * r.Amplitude
+	}
+	h := base + l.relief.FBM2(x/48, z/48, 4)*amplitude
+	return h - l.river(x, z)*riverDepth
+}
+
+//Terrain returns the density of the world
+func (l *Layout) Terrain() Density {
+	return terrain(l.relief, l.Surface)
+}
+
+//column computes the layout of a column without its roads
+func (l *Layout) column(x, z int64) Column {
+	o := ChunkOrigin(x, 0, z, l.ChunkSize)
+	return Column{
+		Biome:   l.Biome(o[0], o[2]),
+		River:   l.crossesRiver(o),
+		Surface: l.Surface(o[0], o[2]),
+	}
+}
+
+//crossesRiver tells whether a river runs through a column centred on o, which
+//happens where the river field changes sign between its corners or nears zero at its centre
+func (l *Layout) crossesRiver(o [3]float64) bool {
+	if l.river(o[0], o[2]) > 0 {
+		return true
+	}
+	h := l.ChunkSize / 2
+	s := l.riverField(o[0]-h, o[2]-h) > 0
+	for _, c := range [][2]float64{{h, -h}, {-h, h}, {h, h}} {
+		if (l.riverField(o[0]+c[0], o[2]+c[1]) > 0) != s {
+			return true
+		}
+	}
+	return false
+}
+
+//RegionOf returns the region a column of chunks belongs to
+func (l *Layout) RegionOf(x, z int64) (int64, int64) {
+	return floorDiv(x, l.RegionSize), floorDiv(z, l.RegionSize)
+}
+
+//Column returns the layout of a column of chunks
+func (l *Layout) Column(x, z int64) Column {
+	rx, rz := l.RegionOf(x, z)
+	r := l.Region(rx, rz)
+	return r.Columns[(x-rx*l.RegionSize)+(z-rz*l.RegionSize)*l.RegionSize]
+}
+
+//Region returns the layout of a region, computing it on first use. Regions are computed
+//outside the lock so that chunks can be generated in parallel, and the first one kept wins.
+func (l *Layout) Region(x, z int64) *Region {
+	k := [2]int64{x, z}
+	l.mutex.Lock()
+	kept, ok := l.regions.get(k)
+	l.mutex.Unlock()
+	if ok {
+		return kept.(*Region)
+	}
+	r := &Region{X: x, Z: z, Columns: make([]Column, l.RegionSize*l.RegionSize)}
+	for j := int64(0); j < l.RegionSize; j++ {
+		for i := int64(0); i < l.RegionSize; i++ {
+			r.Columns[i+j*l.RegionSize] = l.column(x*l.RegionSize+i, z*l.RegionSize+j)
+		}
+	}
+	r.Point = l.point(x, z)
+	for _, n := range [][2]int64{{x - 1, z}, {x + 1, z}, {x, z - 1}, {x, z + 1}} {
+		if p := l.point(n[0], n[1]); p != nil && r.Point != nil {
+			l.road(r, r.Point.Column, p.Column)
+		}
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if kept, ok := l.regions.get(k); ok {
+		return kept.(*Region)
+	}
+	l.regions.add(k, r)
+	return r
+}
+
+//point places the point of interest of a region, if it has one, at a column picked from
+//the seed. Points are never placed on rivers.
+func (l *Layout) point(x, z int64) *PointOfInterest {
+	g := rand.New(rand.NewSource(l.Seed ^ x*73856093 ^ z*19349663))
+	if g.Float64() >= pointChance {
+		return nil
+	}
+	margin := int64(1)
+	if l.RegionSize <= 2 {
+		margin = 0
+	}
+	span := l.RegionSize - 2*margin
+	cx := x*l.RegionSize + margin + g.Int63n(span)
+	cz := z*l.RegionSize + margin + g.Int63n(span)
+	c := l.column(cx, cz)
+	if c.River {
+		return nil
+	}
+	o := ChunkOrigin(cx, 0, cz, l.ChunkSize)
+	return &PointOfInterest{
+		Kind:     PointsOfInterest[c.Biome],
+		Column:   [2]int64{cx, cz},
+		Position: [3]float64{o[0], c.Surface, o[2]},
+	}
+}
+
+//road marks the columns of a region a straight road between two columns runs through.
+//The road is always traced from the same end so that neighbouring regions agree on it.
+func (l *Layout) road(r *Region, a [2]int64, b [2]int64) {
+	if b[0] < a[0] || b[0] == a[0] && b[1] < a[1] {
+		a, b = b, a
+	}
+	dx, dz := abs64(b[0]-a[0]), -abs64(b[1]-a[1])
+	sx, sz := sign64(b[0]-a[0]), sign64(b[1]-a[1])
+	e := dx + dz
+	for p := a; ; {
+		i, j := p[0]-r.X*l.RegionSize, p[1]-r.Z*l.RegionSize
+		if i >= 0 && i < l.RegionSize && j >= 0 && j < l.RegionSize {
+			r.Columns[i+j*l.RegionSize].Road = true
+		}
+		if p == b {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dz {
+			e += dz
+			p[0] += sx
+		}
+		if e2 <= dx {
+			e += dx
+			p[1] += sz
+		}
+	}
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func abs64(a int64) int64 {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func sign64(a int64) int64 {
+	switch {
+	case a < 0:
+		return -1
+	case a > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,49 @@
+package model
+
+import "container/list"
+
+//lru keeps the values of the most recently used keys of a plane of regions or columns,
+//dropping the least recently used ones beyond its capacity. Callers hold their own lock.
+type lru struct {
+	capacity int
+	order    *list.List
+	entries  map[[2]int64]*list.Element
+}
+
+type lruEntry struct {
+	key   [2]int64
+	value interface{}
+}
+
+func newLRU(capacity int) *lru {
+	return &lru{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[[2]int64]*list.Element),
+	}
+}
+
+//get returns the value of a key, marking it as the most recently used
+func (c *lru) get(k [2]int64) (interface{}, bool) {
+	e, ok := c.entries[k]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+//add keeps the value of a key, dropping the least recently used key if over capacity
+func (c *lru) add(k [2]int64, v interface{}) {
+	if e, ok := c.entries[k]; ok {
+		e.Value.(*lruEntry).value = v
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[k] = c.order.PushFront(&lruEntry{key: k, value: v})
+	if c.order.Len() > c.capacity {
+		last := c.order.Back()
+		c.order.Remove(last)
+		delete(c.entries, last.Value.(*lruEntry).key)
+	}
+}
@@ -0,0 +1,256 @@
+package model
+
+import (
+	"goworld/pb"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+//Production is one of the successors a symbol of an L-system may be rewritten to,
+//picked with a probability proportional to its weight
+type Production struct {
+	Weight    float64
+	Successor string
+}
+
+//LSystem describes a plant grown by rewriting a string and drawing it with a turtle.
+//F draws a branch and moves forward, f moves without drawing, + and - turn, & and ^
+//pitch, \ and / roll by Angle degrees, | turns around, [ and ] push and pop the turtle,
+//shortening and thinning branches by Taper, and L draws a leaf. Other symbols only
+//take part in rewriting.
+type LSystem struct {
+	Axiom      string
+	Rules      map[byte][]Production
+	Iterations int
+	Angle      float64
+	//Jitter is how much every turn may stray from Angle, as a fraction of it
+	Jitter   float64
+	Length   float64
+	Radius   float64
+	Taper    float64
+	Leaf     float64
+	Segments int
+}
+
+//Plants are ready made L-systems
+var Plants = map[string]LSystem{
+	"oak": {
+		Axiom: "FFA",
+		Rules: map[byte][]Production{
+			'A': {{Weight: 2, Successor: "[&FLA]/////[&FLA]///////[&FLA]"}, {Weight: 1, Successor: "[&FLA]//////[&FLA]"}},
+			'F': {{Weight: 3, Successor: "F"}, {Weight: 1, Successor: "FF"}},
+		},
+		Iterations: 4, Angle: 24, Jitter: 0.3, Length: 1, Radius: 0.3, Taper: 0.7, Leaf: 0.6, Segments: 6,
+	},
+	"pine": {
+		Axiom: "FFFA",
+		Rules: map[byte][]Production{
+			'A': {{Weight: 1, Successor: "F[&&&BL]///[&&&BL]///[&&&BL]///[&&&BL]A"}},
+			'B': {{Weight: 1, Successor: "FL"}, {Weight: 1, Successor: "F"}},
+		},
+		Iterations: 5, Angle: 22, Jitter: 0.2, Length: 0.8, Radius: 0.25, Taper: 0.5, Leaf: 0.5, Segments: 5,
+	},
+	"bush": {
+		Axiom: "A",
+		Rules: map[byte][]Production{
+			'A': {{Weight: 1, Successor: "[&FLA]////[&FLA]////[&FLA]"}},
+		},
+		Iterations: 3, Angle: 40, Jitter: 0.4, Length: 0.4, Radius: 0.08, Taper: 0.8, Leaf: 0.35, Segments: 4,
+	},
+}
+
+const (
+	//plantBands is the number of bands of the palette texture plants are coloured with, bark and leaves
+	plantBands = 2
+	//maxPlantColliders caps the branches of a plant that collide, thickest first
+	maxPlantColliders = 12
+)
+
+//Expand rewrites the axiom Iterations times, choosing between productions with the random source
+func (l LSystem) Expand(g *rand.Rand) string {
+	s := l.Axiom
+	for i := 0; i < l.Iterations; i++ {
+		var b strings.Builder
+		for j := 0; j < len(s); j++ {
+			ps, ok := l.Rules[s[j]]
+			if !ok {
+				b.WriteByte(s[j])
+				continue
+			}
+			total := 0.0
+			for _, p := range ps {
+				total += p.Weight
+			}
+			r := g.Float64() * total
+			for _, p := range ps {
+				if r -= p.Weight; r < 0 {
+					b.WriteString(p.Successor)
+					break
+				}
+			}
+		}
+		s = b.String()
+	}
+	return s
+}
+
+//turtle is the state of the pen drawing an L-system: a position, its heading, left and up
+//directions, and the length and radius of the next branch
+type turtle struct {
+	pos    [3]float64
+	h      [3]float64
+	l      [3]float64
+	u      [3]float64
+	length float64
+	radius float64
+}
+
+//branch is a drawn segment of a plant
+type branch struct {
+	from   [3]float64
+	to     [3]float64
+	radius float64
+}
+
+//Plant grows an L-system from a seed into a mesh, its base at the origin and growing up
+//the Y axis. Bark and leaves are coloured by the first and second band of a palette texture.
+//The thickest branches make up its compound collider.
+func Plant(l LSystem, seed int64) (*pb.Mesh, Shape) {
+	g := rand.New(rand.NewSource(seed))
+	s := l.Expand(g)
+	b := newBuilder()
+	bark, leaf := bandUV(0, plantBands), bandUV(1, plantBands)
+	t := turtle{h: [3]float64{0, 1, 0}, l: [3]float64{-1, 0, 0}, u: [3]float64{0, 0, 1}, length: l.Length, radius: l.Radius}
+	stack := []turtle{}
+	branches := []branch{}
+	turn := func(sign float64) float64 {
+		return sign * l.Angle * (1 + l.Jitter*(2*g.Float64()-1)) * math.Pi / 180
+	}
+	for i := 0; i < len(s); i++ {
+		dir := 1.0
+		if strings.IndexByte("-^/", s[i]) >= 0 {
+			dir = -1
+		}
+		switch s[i] {
+		case 'F':
+			end := madd3(t.pos, t.h, t.length)
+			b.frustum(t.pos, end, t.l, t.u, t.radius, t.radius*math.Sqrt(l.Taper), l.Segments, bark)
+			branches = append(branches, branch{from: t.pos, to: end, radius: t.radius})
+			t.pos = end
+		case 'f':
+			t.pos = madd3(t.pos, t.h, t.length)
+		case '+', '-':
+			a := turn(dir)
+			t.h, t.l = rotate3(t.h, t.u, a), rotate3(t.l, t.u, a)
+		case '&', '^':
+			a := turn(dir)
+			t.h, t.u = rotate3(t.h, t.l, a), rotate3(t.u, t.l, a)
+		case '\\', '/':
+			a := turn(dir)
+			t.l, t.u = rotate3(t.l, t.h, a), rotate3(t.u, t.h, a)
+		case '|':
+			t.h, t.l = rotate3(t.h, t.u, math.Pi), rotate3(t.l, t.u, math.Pi)
+		case '[':
+			stack = append(stack, t)
+			t.length *= l.Taper
+			t.radius *= l.Taper
+		case ']':
+			if len(stack) > 0 {
+				t, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+		case 'L':
+			b.octahedron(t.pos, l.Leaf*(0.8+0.4*g.Float64()), leaf)
+		}
+	}
+	return b.mesh, Shape{Type: pb.Body_MESH, Parts: branchColliders(branches)}
+}
+
+//branchColliders turns the thickest branches into cylinders
+func branchColliders(branches []branch) []*pb.Body {
+	sorted := append([]branch{}, branches...)
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && sorted[j].radius > sorted[j-1].radius; j-- {
+			sorted[j], sorted[j-1] = sorted[j-1], sorted[j]
+		}
+	}
+	if len(sorted) > maxPlantColliders {
+		sorted = sorted[:maxPlantColliders]
+	}
+	parts := make([]*pb.Body, len(sorted))
+	for i, c := range sorted {
+		d := sub3(c.to, c.from)
+		centre := lerp3(c.from, c.to, 0.5)
+		q := rotationTo([3]float64{0, 1, 0}, normalize(d))
+		parts[i] = &pb.Body{
+			Type:     pb.Body_CYLINDER,
+			Data:     []float64{c.radius, math.Sqrt(dot3(d, d))},
+			Offset:   &pb.RelativeLocation{X: centre[0], Y: centre[1], Z: centre[2]},
+			Rotation: &pb.Rotation{X: float32(q[0]), Y: float32(q[1]), Z: float32(q[2]), W: float32(q[3])},
+		}
+	}
+	return parts
+}
+
+//frustum adds the side of a truncated cone between two points, its rings spanned by l and u
+func (b *builder) frustum(from, to, l, u [3]float64, r0, r1 float64, segments int, uv [2]float64) {
+	uvs := []float64{uv[0], uv[1], uv[0], uv[1], uv[0], uv[1]}
+	bottom, top := make([]uint64, segments), make([]uint64, segments)
+	for i := 0; i < segments; i++ {
+		a := 2 * math.Pi * float64(i) / float64(segments)
+		n := madd3(madd3([3]float64{}, l, math.Cos(a)), u, math.Sin(a))
+		bottom[i] = b.vertex(madd3(from, n, r0), n)
+		top[i] = b.vertex(madd3(to, n, r1), n)
+	}
+	for i := 0; i < segments; i++ {
+		j := (i + 1) % segments
+		b.face(bottom[i], top[j], top[i], uvs)
+		b.face(bottom[i], bottom[j], top[j], uvs)
+	}
+}
+
+//octahedron adds a closed octahedron around a point
+func (b *builder) octahedron(c [3]float64, r float64, uv [2]float64) {
+	uvs := []float64{uv[0], uv[1], uv[0], uv[1], uv[0], uv[1]}
+	dirs := [6][3]float64{{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1}}
+	var idx [6]uint64
+	for i, d := range dirs {
+		idx[i] = b.vertex(madd3(c, d, r), d)
+	}
+	for _, f := range [8][3]int{{0, 2, 4}, {4, 2, 1}, {1, 2, 5}, {5, 2, 0}, {4, 3, 0}, {1, 3, 4}, {5, 3, 1}, {0, 3, 5}} {
+		b.face(idx[f[0]], idx[f[1]], idx[f[2]], uvs)
+	}
+}
+
+//bandUV returns the texture coordinates of the middle of band i of a texture of n bands
+//made with the bands pattern
+func bandUV(i, n int) [2]float64 {
+	return [2]float64{(float64(i) + 0.5) / float64(n), 0.5}
+}
+
+func madd3(a, b [3]float64, s float64) [3]float64 {
+	return [3]float64{a[0] + b[0]*s, a[1] + b[1]*s, a[2] + b[2]*s}
+}
+
+//rotate3 turns v by an angle about a unit axis
+func rotate3(v, axis [3]float64, angle float64) [3]float64 {
+	c, s := math.Cos(angle), math.Sin(angle)
+	return madd3(madd3(madd3([3]float64{}, v, c), cross3(axis, v), s), axis, dot3(axis, v)*(1-c))
+}
+
+//rotationTo returns the quaternion (x, y, z, w) turning unit vector a onto unit vector b
+func rotationTo(a, b [3]float64) [4]float64 {
+	d := dot3(a, b)
+	if d < -0.999999 {
+		axis := cross3([3]float64{1, 0, 0}, a)
+		if dot3(axis, axis) < 1e-12 {
+			axis = cross3([3]float64{0, 0, 1}, a)
+		}
+		axis = normalize(axis)
+		return [4]float64{axis[0], axis[1], axis[2], 0}
+	}
+	c := cross3(a, b)
+	q := [4]float64{c[0], c[1], c[2], 1 + d}
+	l := math.Sqrt(q[0]*q[0] + q[1]*q[1] + q[2]*q[2] + q[3]*q[3])
+	return [4]float64{q[0] / l, q[1] / l, q[2] / l, q[3] / l}
+}
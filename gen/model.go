@@ -6,35 +6,58 @@ import (
 	"github.com/fogleman/fauxgl"
 )
 
-//Box creates a box
-func Box(x, y, z float64) (vertices []float64, faces []*pb.Mesh_Face) {
+//Box creates a box with the given side lengths
+func Box(x, y, z float64) (*pb.Mesh, Shape) {
 	c := fauxgl.NewCube()
+	//fauxgl's cube spans -1 to 1 on every axis
 	c.Transform(fauxgl.Scale(fauxgl.Vector{
-		X: x,
-		Y: y,
-		Z: z,
+		X: x / 2,
+		Y: y / 2,
+		Z: z / 2,
 	}))
-	return convertMesh(c)
+	return convertMesh(c), Shape{Type: pb.Body_BOX, Data: []float64{x, y, z}}
 }
 
-func convertMesh(m *fauxgl.Mesh) (vertices []float64, faces []*pb.Mesh_Face) {
-	vertices = []float64{}
-	vmap := map[[3]float64]uint64{}
+//convertMesh keeps the per-face texture coordinates and per-vertex normals of
+//a fauxgl mesh. Vertices are shared only when position, normal and texture
+//coordinates all match, and are numbered in the order they are first used.
+func convertMesh(m *fauxgl.Mesh) *pb.Mesh {
+	pm := &pb.Mesh{
+		Vertices: []float64{},
+		Normals:  []float64{},
+	}
+	uvs := false
 	for _, t := range m.Triangles {
-		vmap[[3]float64{t.V1.Position.X, t.V1.Position.Y, t.V1.Position.Z}] = 0
-		vmap[[3]float64{t.V2.Position.X, t.V2.Position.Y, t.V2.Position.Z}] = 0
-		vmap[[3]float64{t.V3.Position.X, t.V3.Position.Y, t.V3.Position.Z}] = 0
+		if t.V1.Texture != (fauxgl.Vector{}) || t.V2.Texture != (fauxgl.Vector{}) || t.V3.Texture != (fauxgl.Vector{}) {
+			uvs = true
+			break
+		}
 	}
-	for k := range vmap {
-		vertices = append(vertices, k[0], k[1], k[2])
-		vmap[k] = uint64((len(vertices) / 3) - 1)
+	vmap := map[[8]float64]uint64{}
+	vertex := func(v fauxgl.Vertex) uint64 {
+		k := [8]float64{v.Position.X, v.Position.Y, v.Position.Z, v.Normal.X, v.Normal.Y, v.Normal.Z}
+		if uvs {
+			k[6], k[7] = v.Texture.X, v.Texture.Y
+		}
+		if i, ok := vmap[k]; ok {
+			return i
+		}
+		i := uint64(len(pm.Vertices) / 3)
+		pm.Vertices = append(pm.Vertices, v.Position.X, v.Position.Y, v.Position.Z)
+		pm.Normals = append(pm.Normals, v.Normal.X, v.Normal.Y, v.Normal.Z)
+		vmap[k] = i
+		return i
 	}
 	for _, t := range m.Triangles {
-		faces = append(faces, &pb.Mesh_Face{
-			A: vmap[[3]float64{t.V1.Position.X, t.V1.Position.Y, t.V1.Position.Z}],
-			B: vmap[[3]float64{t.V2.Position.X, t.V2.Position.Y, t.V2.Position.Z}],
-			C: vmap[[3]float64{t.V3.Position.X, t.V3.Position.Y, t.V3.Position.Z}],
-		})
+		f := &pb.Mesh_Face{
+			A: vertex(t.V1),
+			B: vertex(t.V2),
+			C: vertex(t.V3),
+		}
+		if uvs {
+			f.Uvs = []float64{t.V1.Texture.X, t.V1.Texture.Y, t.V2.Texture.X, t.V2.Texture.Y, t.V3.Texture.X, t.V3.Texture.Y}
+		}
+		pm.Faces = append(pm.Faces, f)
 	}
-	return
+	return pm
 }
@@ -0,0 +1,92 @@
+package noise
+
+import (
+	"math"
+	"math/rand"
+)
+
+//Noise is seeded gradient noise in the style of Perlin's improved noise.
+//Values lie roughly in [-1, 1] and are 0 at every integer lattice point.
+type Noise struct {
+	perm [512]int
+}
+
+//New returns the noise for a seed
+func New(seed int64) *Noise {
+	n := new(Noise)
+	p := rand.New(rand.NewSource(seed)).Perm(256)
+	for i := range n.perm {
+		n.perm[i] = p[i&255]
+	}
+	return n
+}
+
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func lerp(t, a, b float64) float64 {
+	return a + t*(b-a)
+}
+
+func grad(hash int, x, y, z float64) float64 {
+	h := hash & 15
+	u, v := y, z
+	if h < 8 {
+		u = x
+	}
+	if h < 4 {
+		v = y
+	} else if h == 12 || h == 14 {
+		v = x
+	}
+	if h&1 != 0 {
+		u = -u
+	}
+	if h&2 != 0 {
+		v = -v
+	}
+	return u + v
+}
+
+//Eval3 samples the noise at a point
+func (n *Noise) Eval3(x, y, z float64) float64 {
+	fx, fy, fz := math.Floor(x), math.Floor(y), math.Floor(z)
+	X, Y, Z := int(fx)&255, int(fy)&255, int(fz)&255
+	x, y, z = x-fx, y-fy, z-fz
+	u, v, w := fade(x), fade(y), fade(z)
+	p := n.perm
+	a := p[X] + Y
+	aa, ab := p[a]+Z, p[a+1]+Z
+	b := p[X+1] + Y
+	ba, bb := p[b]+Z, p[b+1]+Z
+	return lerp(w,
+		lerp(v,
+			lerp(u, grad(p[aa], x, y, z), grad(p[ba], x-1, y, z)),
+			lerp(u, grad(p[ab], x, y-1, z), grad(p[bb], x-1, y-1, z))),
+		lerp(v,
+			lerp(u, grad(p[aa+1], x, y, z-1), grad(p[ba+1], x-1, y, z-1)),
+			lerp(u, grad(p[ab+1], x, y-1, z-1), grad(p[bb+1], x-1, y-1, z-1))))
+}
+
+//Eval2 samples the noise on the plane z = 0
+func (n *Noise) Eval2(x, y float64) float64 {
+	return n.Eval3(x, y, 0)
+}
+
+//FBM3 sums octaves of noise, each at double the frequency and half the amplitude
+//of the one before, normalised back to roughly [-1, 1]
+func (n *Noise) FBM3(x, y, z float64, octaves int) float64 {
+	sum, amp, norm := 0.0, 1.0, 0.0
+	for i := 0; i < octaves; i++ {
+		sum += amp * n.Eval3(x, y, z)
+		norm += amp
+		x, y, z, amp = x*2, y*2, z*2, amp/2
+	}
+	return sum / norm
+}
+
+//FBM2 is FBM3 on the plane z = 0
+func (n *Noise) FBM2(x, y float64, octaves int) float64 {
+	return n.FBM3(x, y, 0, octaves)
+}
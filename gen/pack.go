@@ -0,0 +1,204 @@
+package model
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"goworld/pb"
+	"io/ioutil"
+	"math"
+
+	"github.com/go-errors/errors"
+	"github.com/golang/protobuf/proto"
+)
+
+//Pack converts a mesh to its compact form. FLOAT32 halves the size of every
+//attribute, QUANTIZED stores positions and UVs as 16 bit fractions of their bounds
+//and normals as 8 bit components. Face indices are always stored as zigzag varint
+//deltas from the previous index.
+func Pack(m *pb.Mesh, encoding pb.PackedMesh_Encoding) *pb.PackedMesh {
+	p := new(pb.PackedMesh)
+	p.Encoding = encoding
+	p.VertexCount = uint32(len(m.Vertices) / 3)
+	quantized := encoding == pb.PackedMesh_QUANTIZED
+	if quantized {
+		p.Min, p.Max = bounds32(m.Vertices, 3)
+		p.Positions = packUnorm(m.Vertices, p.Min, p.Max)
+		p.Normals = make([]byte, len(m.Normals))
+		for i, v := range m.Normals {
+			p.Normals[i] = byte(int8(math.Round(v * 127)))
+		}
+	} else {
+		p.Positions = packFloats(m.Vertices)
+		p.Normals = packFloats(m.Normals)
+	}
+	uvs := []float64{}
+	if hasUVs(m) {
+		for _, f := range m.Faces {
+			uvs = append(uvs, f.Uvs...)
+		}
+	}
+	if quantized {
+		p.UvMin, p.UvMax = bounds32(uvs, 2)
+		p.Uvs = packUnorm(uvs, p.UvMin, p.UvMax)
+	} else {
+		p.Uvs = packFloats(uvs)
+	}
+	buf := make([]byte, binary.MaxVarintLen64)
+	prev := int64(0)
+	for _, f := range m.Faces {
+		for _, i := range []uint64{f.A, f.B, f.C} {
+			n := binary.PutVarint(buf, int64(i)-prev)
+			p.Indices = append(p.Indices, buf[:n]...)
+			prev = int64(i)
+		}
+	}
+	return p
+}
+
+//Unpack restores a mesh from its compact form
+func Unpack(p *pb.PackedMesh) (*pb.Mesh, error) {
+	m := new(pb.Mesh)
+	n := int(p.VertexCount) * 3
+	quantized := p.Encoding == pb.PackedMesh_QUANTIZED
+	var uvs []float64
+	if quantized {
+		m.Vertices = unpackUnorm(p.Positions, p.Min, p.Max)
+		for _, b := range p.Normals {
+			m.Normals = append(m.Normals, math.Max(float64(int8(b))/127, -1))
+		}
+		uvs = unpackUnorm(p.Uvs, p.UvMin, p.UvMax)
+	} else {
+		m.Vertices = unpackFloats(p.Positions)
+		m.Normals = unpackFloats(p.Normals)
+		uvs = unpackFloats(p.Uvs)
+	}
+	if len(m.Vertices) != n || (len(m.Normals) != 0 && len(m.Normals) != n) {
+		return nil, errors.Errorf("packed mesh: expected %d vertices", p.VertexCount)
+	}
+	r := bytes.NewReader(p.Indices)
+	prev := int64(0)
+	idx := [3]uint64{}
+	for r.Len() > 0 {
+		for k := range idx {
+			d, err := binary.ReadVarint(r)
+			if err != nil {
+				return nil, errors.Wrap(err, 0)
+			}
+			prev += d
+			if prev < 0 || prev >= int64(p.VertexCount) {
+				return nil, errors.Errorf("packed mesh: index %d out of range", prev)
+			}
+			idx[k] = uint64(prev)
+		}
+		f := &pb.Mesh_Face{A: idx[0], B: idx[1], C: idx[2]}
+		if o := len(m.Faces) * 6; o+6 <= len(uvs) {
+			f.Uvs = uvs[o : o+6]
+		}
+		m.Faces = append(m.Faces, f)
+	}
+	return m, nil
+}
+
+//Encode serializes a mesh with the given encoding, compressing it with deflate if asked to
+func Encode(m *pb.Mesh, encoding pb.PackedMesh_Encoding, deflate bool) ([]byte, error) {
+	var b []byte
+	var err error
+	if encoding == pb.PackedMesh_PROTO {
+		b, err = proto.Marshal(m)
+	} else {
+		b, err = proto.Marshal(Pack(m, encoding))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	if !deflate {
+		return b, nil
+	}
+	buf := new(bytes.Buffer)
+	w, _ := flate.NewWriter(buf, flate.BestCompression)
+	w.Write(b)
+	w.Close()
+	return buf.Bytes(), nil
+}
+
+//Decode reverses Encode
+func Decode(b []byte, encoding pb.PackedMesh_Encoding, deflate bool) (*pb.Mesh, error) {
+	if deflate {
+		d, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(b)))
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+		b = d
+	}
+	if encoding == pb.PackedMesh_PROTO {
+		m := new(pb.Mesh)
+		if err := proto.Unmarshal(b, m); err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+		return m, nil
+	}
+	p := new(pb.PackedMesh)
+	if err := proto.Unmarshal(b, p); err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	return Unpack(p)
+}
+
+func bounds32(v []float64, stride int) ([]float32, []float32) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	min, max := make([]float32, stride), make([]float32, stride)
+	for k := 0; k < stride; k++ {
+		min[k], max[k] = float32(v[k]), float32(v[k])
+	}
+	for i, x := range v {
+		k := i % stride
+		min[k] = float32(math.Min(float64(min[k]), x))
+		max[k] = float32(math.Max(float64(max[k]), x))
+	}
+	return min, max
+}
+
+func packUnorm(v []float64, min []float32, max []float32) []byte {
+	b := make([]byte, len(v)*2)
+	for i, x := range v {
+		k := i % len(min)
+		q := 0.0
+		if r := float64(max[k] - min[k]); r > 0 {
+			q = math.Round((x - float64(min[k])) / r * 65535)
+		}
+		binary.LittleEndian.PutUint16(b[i*2:], uint16(math.Max(0, math.Min(65535, q))))
+	}
+	return b
+}
+
+func unpackUnorm(b []byte, min []float32, max []float32) []float64 {
+	if len(min) == 0 {
+		return nil
+	}
+	v := make([]float64, len(b)/2)
+	for i := range v {
+		k := i % len(min)
+		q := float64(binary.LittleEndian.Uint16(b[i*2:])) / 65535
+		v[i] = float64(min[k]) + q*float64(max[k]-min[k])
+	}
+	return v
+}
+
+func packFloats(v []float64) []byte {
+	b := make([]byte, len(v)*4)
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(b[i*4:], math.Float32bits(float32(x)))
+	}
+	return b
+}
+
+func unpackFloats(b []byte) []float64 {
+	v := make([]float64, len(b)/4)
+	for i := range v {
+		v[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:])))
+	}
+	return v
+}
@@ -0,0 +1,282 @@
+package model
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+)
+
+//PlacementRule describes where objects of a kind may be placed
+type PlacementRule struct {
+	Kind   string
+	Biomes []Biome
+	//Spacing is the least distance between two objects of the rule, the radius of its Poisson-disc sampling
+	Spacing float64
+	//Footprint is the radius around an object that no other object may reach into
+	Footprint float64
+	//MaxSlope is the steepest ground an object stands on, as rise over run
+	MaxSlope float64
+	//Chance is the share of sampled points that are kept
+	Chance float64
+}
+
+//Placement is an object placed on the ground
+type Placement struct {
+	Kind     string
+	Position [3]float64
+	Yaw      float64
+}
+
+//candidate is a placement before conflicts between columns are settled
+type candidate struct {
+	Placement
+	rule     int
+	priority int64
+}
+
+const (
+	//roadClearance is how far objects keep from the middle of a column crossed by a road
+	roadClearance = 8
+	//pointClearance is how far objects keep from a point of interest
+	pointClearance = 16
+	//groundSearch is how far above and below the surface of the layout the ground is looked for
+	groundSearch = 12
+	//columnCache is how many columns a placer keeps the objects and candidates of
+	columnCache = 4096
+)
+
+//Placer populates the columns of chunks of a layout with objects. The candidates of each
+//column only depend on the seed and the column, and a candidate is dropped when it conflicts
+//with one of higher priority from any column, so every column gets the same objects whatever
+//order the columns are placed in, including objects whose footprints cross into neighbours.
+//Each object belongs to the column its position lies in. The last columnCache columns
+//placed and sampled are kept, as they can be computed again from the seed.
+type Placer struct {
+	Layout     *Layout
+	Terrain    Density
+	Rules      []PlacementRule
+	columns    *lru
+	candidates *lru
+	mutex      *sync.Mutex
+}
+
+//NewPlacer returns a placer of objects on the terrain of a layout
+func NewPlacer(l *Layout, terrain Density, rules []PlacementRule) *Placer {
+	return &Placer{
+		Layout:     l,
+		Terrain:    terrain,
+		Rules:      rules,
+		columns:    newLRU(columnCache),
+		candidates: newLRU(columnCache),
+		mutex:      new(sync.Mutex),
+	}
+}
+
+//Column returns the objects placed in a column of chunks, computing them on first use.
+//Columns are computed outside the lock so that several can be placed in parallel.
+func (p *Placer) Column(x, z int64) []Placement {
+	k := [2]int64{x, z}
+	p.mutex.Lock()
+	c, ok := p.columns.get(k)
+	p.mutex.Unlock()
+	if ok {
+		return c.([]Placement)
+	}
+	reach := 0.0
+	for _, r := range p.Rules {
+		reach = math.Max(reach, math.Max(r.Spacing, 2*r.Footprint))
+	}
+	ring := int64(math.Ceil(reach / p.Layout.ChunkSize))
+	others := []candidate{}
+	for j := z - ring; j <= z+ring; j++ {
+		for i := x - ring; i <= x+ring; i++ {
+			others = append(others, p.sample(i, j)...)
+		}
+	}
+	placed := []Placement{}
+	for _, c := range p.sample(x, z) {
+		kept := true
+		for _, o := range others {
+			if o.Position != c.Position && p.conflict(c, o) && o.priority >= c.priority {
+				kept = false
+				break
+			}
+		}
+		if kept {
+			placed = append(placed, c.Placement)
+		}
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if kept, ok := p.columns.get(k); ok {
+		return kept.([]Placement)
+	}
+	p.columns.add(k, placed)
+	return placed
+}
+
+//conflict tells whether two candidates are too close to both be placed
+func (p *Placer) conflict(a, b candidate) bool {
+	d := math.Hypot(a.Position[0]-b.Position[0], a.Position[2]-b.Position[2])
+	if a.rule == b.rule && d < p.Rules[a.rule].Spacing {
+		return true
+	}
+	return d < p.Rules[a.rule].Footprint+p.Rules[b.rule].Footprint
+}
+
+//sample returns the candidates of a column, sampling them on first use
+func (p *Placer) sample(x, z int64) []candidate {
+	k := [2]int64{x, z}
+	p.mutex.Lock()
+	kept, ok := p.candidates.get(k)
+	p.mutex.Unlock()
+	if ok {
+		return kept.([]candidate)
+	}
+	cs := p.candidatesOf(x, z)
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if kept, ok := p.candidates.get(k); ok {
+		return kept.([]candidate)
+	}
+	p.candidates.add(k, cs)
+	return cs
+}
+
+//candidatesOf samples each rule over a column and keeps the points that satisfy it
+func (p *Placer) candidatesOf(x, z int64) []candidate {
+	l := p.Layout
+	s := l.ChunkSize
+	o := ChunkOrigin(x, 0, z, s)
+	col := l.column(x, z)
+	rx, rz := l.RegionOf(x, z)
+	point := l.point(rx, rz)
+	cs := []candidate{}
+	for i, r := range p.Rules {
+		if !hasBiome(r.Biomes, col.Biome) {
+			continue
+		}
+		g := rand.New(rand.NewSource(l.Seed ^ x*73856093 ^ z*19349663 ^ int64(i+1)*83492791))
+		for _, q := range PoissonDisc(g, s, r.Spacing) {
+			wx, wz := o[0]-s/2+q[0], o[2]-s/2+q[1]
+			yaw, priority := g.Float64()*2*math.Pi, g.Int63()
+			if g.Float64() >= r.Chance || l.Biome(wx, wz) != col.Biome || l.river(wx, wz) > 0 {
+				continue
+			}
+			if col.Road && math.Hypot(wx-o[0], wz-o[2]) < roadClearance {
+				continue
+			}
+			if point != nil && math.Hypot(wx-point.Position[0], wz-point.Position[2]) < pointClearance {
+				continue
+			}
+			y, ok := p.ground(wx, wz)
+			if !ok || p.slope(wx, y, wz) > r.MaxSlope {
+				continue
+			}
+			cs = append(cs, candidate{
+				Placement: Placement{Kind: r.Kind, Position: [3]float64{wx, y, wz}, Yaw: yaw},
+				rule:      i,
+				priority:  priority,
+			})
+		}
+	}
+	return cs
+}
+
+//ground finds the top of the terrain at a point around the surface of the layout
+func (p *Placer) ground(x, z float64) (float64, bool) {
+	top := p.Layout.Surface(x, z) + groundSearch
+	if p.Terrain(x, top, z) > 0 {
+		return 0, false
+	}
+	for y := top - 1; y >= top-2*groundSearch; y-- {
+		if p.Terrain(x, y, z) <= 0 {
+			continue
+		}
+		lo, hi := y, y+1
+		for i := 0; i < 8; i++ {
+			m := (lo + hi) / 2
+			if p.Terrain(x, m, z) > 0 {
+				lo = m
+			} else {
+				hi = m
+			}
+		}
+		return (lo + hi) / 2, true
+	}
+	return 0, false
+}
+
+//slope estimates the steepness of the ground at a point from the ground a unit away on each axis
+func (p *Placer) slope(x, y, z float64) float64 {
+	s := 0.0
+	for _, d := range [][2]float64{{1, 0}, {-1, 0}, {0, 1}, {0, -1}} {
+		h, ok := p.ground(x+d[0], z+d[1])
+		if !ok {
+			return math.Inf(1)
+		}
+		s = math.Max(s, math.Abs(h-y))
+	}
+	return s
+}
+
+func hasBiome(biomes []Biome, b Biome) bool {
+	for _, c := range biomes {
+		if c == b {
+			return true
+		}
+	}
+	return false
+}
+
+//PoissonDisc samples points in a square of a size with Bridson's algorithm, every two
+//points at least radius apart and no room left for another
+func PoissonDisc(g *rand.Rand, size float64, radius float64) [][2]float64 {
+	const attempts = 30
+	cell := radius / math.Sqrt2
+	n := int(math.Ceil(size / cell))
+	grid := make([]int, n*n)
+	for i := range grid {
+		grid[i] = -1
+	}
+	at := func(q [2]float64) (int, int) {
+		return int(q[0] / cell), int(q[1] / cell)
+	}
+	points := [][2]float64{{g.Float64() * size, g.Float64() * size}}
+	i, j := at(points[0])
+	grid[i+j*n] = 0
+	active := []int{0}
+	for len(active) > 0 {
+		k := g.Intn(len(active))
+		c := points[active[k]]
+		found := false
+		for t := 0; t < attempts; t++ {
+			a, d := g.Float64()*2*math.Pi, radius*(1+g.Float64())
+			q := [2]float64{c[0] + math.Cos(a)*d, c[1] + math.Sin(a)*d}
+			if q[0] < 0 || q[0] >= size || q[1] < 0 || q[1] >= size {
+				continue
+			}
+			qi, qj := at(q)
+			free := true
+			for v := max(qj-2, 0); v <= qj+2 && v < n && free; v++ {
+				for u := max(qi-2, 0); u <= qi+2 && u < n; u++ {
+					if o := grid[u+v*n]; o >= 0 && math.Hypot(points[o][0]-q[0], points[o][1]-q[1]) < radius {
+						free = false
+						break
+					}
+				}
+			}
+			if free {
+				grid[qi+qj*n] = len(points)
+				active = append(active, len(points))
+				points = append(points, q)
+				found = true
+				break
+			}
+		}
+		if !found {
+			active = append(active[:k], active[k+1:]...)
+		}
+	}
+	return points
+}
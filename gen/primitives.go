@@ -0,0 +1,372 @@
+package model
+
+import (
+	"goworld/pb"
+	"math"
+)
+
+//Shape describes the collision shape matching a generated mesh. Boxes take
+//their extents, spheres their radius, capsules and cylinders their radius and
+//length along the Y axis. Meshes collide as a trimesh of their own faces unless
+//they have Parts, primitive colliders placed by their offset and rotation that
+//together make up a compound shape.
+type Shape struct {
+	Type  pb.Body_Type
+	Data  []float64
+	Parts []*pb.Body
+}
+
+type builder struct {
+	mesh *pb.Mesh
+}
+
+func newBuilder() *builder {
+	return &builder{mesh: &pb.Mesh{
+		Vertices: []float64{},
+		Normals:  []float64{},
+	}}
+}
+
+func (b *builder) vertex(p, n [3]float64) uint64 {
+	b.mesh.Vertices = append(b.mesh.Vertices, p[0], p[1], p[2])
+	b.mesh.Normals = append(b.mesh.Normals, n[0], n[1], n[2])
+	return uint64(len(b.mesh.Vertices)/3 - 1)
+}
+
+func (b *builder) position(i uint64) [3]float64 {
+	return [3]float64{b.mesh.Vertices[i*3], b.mesh.Vertices[i*3+1], b.mesh.Vertices[i*3+2]}
+}
+
+//face adds a triangle unless two of its corners coincide
+func (b *builder) face(i, j, k uint64, uvs []float64) {
+	p, q, r := b.position(i), b.position(j), b.position(k)
+	if p == q || q == r || p == r {
+		return
+	}
+	b.mesh.Faces = append(b.mesh.Faces, &pb.Mesh_Face{A: i, B: j, C: k, Uvs: uvs})
+}
+
+//surface builds a grid of rows by cols quads. f returns the position and normal
+//at grid point (i, j) and is given the texture coordinates of that point.
+//Faces are wound so that increasing j then i turns counter-clockwise.
+func (b *builder) surface(rows, cols int, f func(i, j int, u, v float64) (p, n [3]float64)) {
+	idx := make([][]uint64, rows+1)
+	for i := 0; i <= rows; i++ {
+		idx[i] = make([]uint64, cols+1)
+		for j := 0; j <= cols; j++ {
+			p, n := f(i, j, float64(j)/float64(cols), float64(i)/float64(rows))
+			idx[i][j] = b.vertex(p, n)
+		}
+	}
+	for i := 0; i < rows; i++ {
+		v0, v1 := float64(i)/float64(rows), float64(i+1)/float64(rows)
+		for j := 0; j < cols; j++ {
+			u0, u1 := float64(j)/float64(cols), float64(j+1)/float64(cols)
+			b.face(idx[i][j], idx[i][j+1], idx[i+1][j+1], []float64{u0, v0, u1, v0, u1, v1})
+			b.face(idx[i][j], idx[i+1][j+1], idx[i+1][j], []float64{u0, v0, u1, v1, u0, v1})
+		}
+	}
+}
+
+func normalize(v [3]float64) [3]float64 {
+	l := math.Sqrt(v[0]*v[0] + v[1]*v[1] + v[2]*v[2])
+	if l == 0 {
+		return v
+	}
+	return [3]float64{v[0] / l, v[1] / l, v[2] / l}
+}
+
+//Sphere creates a UV sphere
+func Sphere(radius float64, segments, rings int) (*pb.Mesh, Shape) {
+	b := newBuilder()
+	b.surface(rings, segments, func(i, j int, u, v float64) (p, n [3]float64) {
+		phi, theta := math.Pi*(v-0.5), 2*math.Pi*u
+		n = [3]float64{math.Cos(phi) * math.Sin(theta), math.Sin(phi), math.Cos(phi) * math.Cos(theta)}
+		return [3]float64{n[0] * radius, n[1] * radius, n[2] * radius}, n
+	})
+	return b.mesh, Shape{Type: pb.Body_SPHERE, Data: []float64{radius}}
+}
+
+//Icosphere creates a sphere by subdividing an icosahedron
+func Icosphere(radius float64, detail int) (*pb.Mesh, Shape) {
+	t := (1 + math.Sqrt(5)) / 2
+	points := [][3]float64{
+		{-1, t, 0}, {1, t, 0}, {-1, -t, 0}, {1, -t, 0},
+		{0, -1, t}, {0, 1, t}, {0, -1, -t}, {0, 1, -t},
+		{t, 0, -1}, {t, 0, 1}, {-t, 0, -1}, {-t, 0, 1},
+	}
+	for i := range points {
+		points[i] = normalize(points[i])
+	}
+	tris := [][3]int{
+		{0, 11, 5}, {0, 5, 1}, {0, 1, 7}, {0, 7, 10}, {0, 10, 11},
+		{1, 5, 9}, {5, 11, 4}, {11, 10, 2}, {10, 7, 6}, {7, 1, 8},
+		{3, 9, 4}, {3, 4, 2}, {3, 2, 6}, {3, 6, 8}, {3, 8, 9},
+		{4, 9, 5}, {2, 4, 11}, {6, 2, 10}, {8, 6, 7}, {9, 8, 1},
+	}
+	for d := 0; d < detail; d++ {
+		mids := map[[2]int]int{}
+		mid := func(a, b int) int {
+			k := [2]int{a, b}
+			if a > b {
+				k = [2]int{b, a}
+			}
+			if m, ok := mids[k]; ok {
+				return m
+			}
+			p, q := points[a], points[b]
+			points = append(points, normalize([3]float64{p[0] + q[0], p[1] + q[1], p[2] + q[2]}))
+			mids[k] = len(points) - 1
+			return len(points) - 1
+		}
+		next := make([][3]int, 0, len(tris)*4)
+		for _, f := range tris {
+			a, b, c := mid(f[0], f[1]), mid(f[1], f[2]), mid(f[2], f[0])
+			next = append(next, [3]int{f[0], a, c}, [3]int{f[1], b, a}, [3]int{f[2], c, b}, [3]int{a, b, c})
+		}
+		tris = next
+	}
+	b := newBuilder()
+	for _, n := range points {
+		b.vertex([3]float64{n[0] * radius, n[1] * radius, n[2] * radius}, n)
+	}
+	for _, f := range tris {
+		b.face(uint64(f[0]), uint64(f[1]), uint64(f[2]), sphericalUVs(points[f[0]], points[f[1]], points[f[2]]))
+	}
+	return b.mesh, Shape{Type: pb.Body_SPHERE, Data: []float64{radius}}
+}
+
+//sphericalUVs maps the corners of a face on the unit sphere to equirectangular
+//coordinates, keeping faces that straddle the seam or touch a pole contiguous
+func sphericalUVs(ps ...[3]float64) []float64 {
+	uvs := make([]float64, 6)
+	poles := map[int]bool{}
+	for i, p := range ps {
+		uvs[i*2] = 0.5 + math.Atan2(p[0], p[2])/(2*math.Pi)
+		uvs[i*2+1] = 0.5 + math.Asin(math.Max(-1, math.Min(1, p[1])))/math.Pi
+		poles[i] = math.Abs(p[1]) > 0.999999
+	}
+	max := -1.0
+	for i := 0; i < 3; i++ {
+		if !poles[i] && uvs[i*2] > max {
+			max = uvs[i*2]
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if !poles[i] && max-uvs[i*2] > 0.5 {
+			uvs[i*2]++
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if poles[i] {
+			uvs[i*2] = (uvs[((i+1)%3)*2] + uvs[((i+2)%3)*2]) / 2
+		}
+	}
+	return uvs
+}
+
+//Lathe revolves a profile of (radius, height) points around the Y axis.
+//Normals are averaged along the profile; repeat a point to get a hard edge.
+func Lathe(profile [][2]float64, segments int) (*pb.Mesh, Shape) {
+	n := len(profile)
+	lengths := make([]float64, n)
+	for i := 1; i < n; i++ {
+		lengths[i] = lengths[i-1] + math.Hypot(profile[i][0]-profile[i-1][0], profile[i][1]-profile[i-1][1])
+	}
+	normals := make([][2]float64, n)
+	for i := range profile {
+		for _, s := range [][2]int{{i - 1, i}, {i, i + 1}} {
+			if s[0] < 0 || s[1] >= n {
+				continue
+			}
+			dx, dy := profile[s[1]][0]-profile[s[0]][0], profile[s[1]][1]-profile[s[0]][1]
+			if l := math.Hypot(dx, dy); l > 0 {
+				normals[i][0] += dy / l
+				normals[i][1] -= dx / l
+			}
+		}
+		if l := math.Hypot(normals[i][0], normals[i][1]); l > 0 {
+			normals[i] = [2]float64{normals[i][0] / l, normals[i][1] / l}
+		}
+	}
+	b := newBuilder()
+	if n < 2 {
+		return b.mesh, Shape{Type: pb.Body_MESH}
+	}
+	b.surface(n-1, segments, func(i, j int, u, v float64) (p, nv [3]float64) {
+		theta := 2 * math.Pi * u
+		s, c := math.Sin(theta), math.Cos(theta)
+		r, y := profile[i][0], profile[i][1]
+		return [3]float64{r * s, y, r * c}, [3]float64{normals[i][0] * s, normals[i][1], normals[i][0] * c}
+	})
+	if lengths[n-1] > 0 {
+		//spread v by arc length rather than by point index
+		for _, f := range b.mesh.Faces {
+			for k, idx := range []uint64{f.A, f.B, f.C} {
+				f.Uvs[k*2+1] = lengths[int(idx)/(segments+1)] / lengths[n-1]
+			}
+		}
+	}
+	return b.mesh, Shape{Type: pb.Body_MESH}
+}
+
+//Cylinder creates a capped cylinder along the Y axis
+func Cylinder(radius, height float64, segments int) (*pb.Mesh, Shape) {
+	h := height / 2
+	m, _ := Lathe([][2]float64{{0, -h}, {radius, -h}, {radius, -h}, {radius, h}, {radius, h}, {0, h}}, segments)
+	return m, Shape{Type: pb.Body_CYLINDER, Data: []float64{radius, height}}
+}
+
+//Cone creates a capped cone along the Y axis
+func Cone(radius, height float64, segments int) (*pb.Mesh, Shape) {
+	h := height / 2
+	return Lathe([][2]float64{{0, -h}, {radius, -h}, {radius, -h}, {0, h}}, segments)
+}
+
+//Capsule creates a cylinder of the given length along the Y axis with hemispherical ends
+func Capsule(radius, length float64, segments, rings int) (*pb.Mesh, Shape) {
+	half := rings / 2
+	if half < 1 {
+		half = 1
+	}
+	b := newBuilder()
+	b.surface(2*half+1, segments, func(i, j int, u, v float64) (p, n [3]float64) {
+		phi, y := -math.Pi/2+math.Pi/2*float64(i)/float64(half), -length/2
+		if i > half {
+			phi, y = math.Pi/2*float64(i-half-1)/float64(half), length/2
+		}
+		theta := 2 * math.Pi * u
+		n = [3]float64{math.Cos(phi) * math.Sin(theta), math.Sin(phi), math.Cos(phi) * math.Cos(theta)}
+		return [3]float64{n[0] * radius, n[1]*radius + y, n[2] * radius}, n
+	})
+	return b.mesh, Shape{Type: pb.Body_CAPSULE, Data: []float64{radius, length}}
+}
+
+//Torus creates a torus around the Y axis
+func Torus(radius, tube float64, radialSegments, tubularSegments int) (*pb.Mesh, Shape) {
+	b := newBuilder()
+	b.surface(tubularSegments, radialSegments, func(i, j int, u, v float64) (p, n [3]float64) {
+		theta, phi := 2*math.Pi*u, 2*math.Pi*v
+		d := [3]float64{math.Sin(theta), 0, math.Cos(theta)}
+		n = [3]float64{math.Cos(phi) * d[0], math.Sin(phi), math.Cos(phi) * d[2]}
+		return [3]float64{d[0]*radius + n[0]*tube, n[1] * tube, d[2]*radius + n[2]*tube}, n
+	})
+	return b.mesh, Shape{Type: pb.Body_MESH}
+}
+
+//Grid creates a flat grid on the XZ plane facing up
+func Grid(width, depth float64, xSegments, zSegments int) (*pb.Mesh, Shape) {
+	b := newBuilder()
+	b.surface(zSegments, xSegments, func(i, j int, u, v float64) (p, n [3]float64) {
+		return [3]float64{(u - 0.5) * width, 0, (0.5 - v) * depth}, [3]float64{0, 1, 0}
+	})
+	return b.mesh, Shape{Type: pb.Body_MESH}
+}
+
+//Extrude extrudes a simple polygon on the XY plane along Z, centered on the origin
+func Extrude(outline [][2]float64, depth float64) (*pb.Mesh, Shape) {
+	b := newBuilder()
+	n := len(outline)
+	if n < 3 {
+		return b.mesh, Shape{Type: pb.Body_MESH}
+	}
+	area := 0.0
+	for i := range outline {
+		p, q := outline[i], outline[(i+1)%n]
+		area += p[0]*q[1] - q[0]*p[1]
+	}
+	pts := make([][2]float64, n)
+	copy(pts, outline)
+	if area < 0 {
+		for i := range pts {
+			pts[i] = outline[n-1-i]
+		}
+	}
+	minX, minY, maxX, maxY := pts[0][0], pts[0][1], pts[0][0], pts[0][1]
+	perimeter := 0.0
+	for i, p := range pts {
+		minX, minY, maxX, maxY = math.Min(minX, p[0]), math.Min(minY, p[1]), math.Max(maxX, p[0]), math.Max(maxY, p[1])
+		perimeter += math.Hypot(pts[(i+1)%n][0]-p[0], pts[(i+1)%n][1]-p[1])
+	}
+	h := depth / 2
+	along := 0.0
+	for i, p := range pts {
+		q := pts[(i+1)%n]
+		l := math.Hypot(q[0]-p[0], q[1]-p[1])
+		if l == 0 {
+			continue
+		}
+		nv := [3]float64{(q[1] - p[1]) / l, -(q[0] - p[0]) / l, 0}
+		a := b.vertex([3]float64{p[0], p[1], -h}, nv)
+		c := b.vertex([3]float64{q[0], q[1], -h}, nv)
+		d := b.vertex([3]float64{q[0], q[1], h}, nv)
+		e := b.vertex([3]float64{p[0], p[1], h}, nv)
+		u0, u1 := along/perimeter, (along+l)/perimeter
+		b.face(a, c, d, []float64{u0, 0, u1, 0, u1, 1})
+		b.face(a, d, e, []float64{u0, 0, u1, 1, u0, 1})
+		along += l
+	}
+	w, ht := math.Max(maxX-minX, 1e-9), math.Max(maxY-minY, 1e-9)
+	for _, side := range []float64{-1, 1} {
+		base := uint64(len(b.mesh.Vertices) / 3)
+		for _, p := range pts {
+			b.vertex([3]float64{p[0], p[1], side * h}, [3]float64{0, 0, side})
+		}
+		for _, t := range triangulate(pts) {
+			i, j, k := t[0], t[1], t[2]
+			if side < 0 {
+				j, k = k, j
+			}
+			b.face(base+uint64(i), base+uint64(j), base+uint64(k), []float64{
+				(pts[i][0] - minX) / w, (pts[i][1] - minY) / ht,
+				(pts[j][0] - minX) / w, (pts[j][1] - minY) / ht,
+				(pts[k][0] - minX) / w, (pts[k][1] - minY) / ht,
+			})
+		}
+	}
+	return b.mesh, Shape{Type: pb.Body_MESH}
+}
+
+//triangulate ear-clips a counter-clockwise simple polygon
+func triangulate(pts [][2]float64) [][3]int {
+	idx := make([]int, len(pts))
+	for i := range idx {
+		idx[i] = i
+	}
+	cross := func(a, b, c [2]float64) float64 {
+		return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+	}
+	tris := [][3]int{}
+	for guard := 0; len(idx) > 3 && guard < len(pts)*len(pts); guard++ {
+		clipped := false
+		for i := range idx {
+			a, b, c := idx[(i+len(idx)-1)%len(idx)], idx[i], idx[(i+1)%len(idx)]
+			if cross(pts[a], pts[b], pts[c]) <= 0 {
+				continue
+			}
+			ear := true
+			for _, o := range idx {
+				if o == a || o == b || o == c {
+					continue
+				}
+				if cross(pts[a], pts[b], pts[o]) >= 0 && cross(pts[b], pts[c], pts[o]) >= 0 && cross(pts[c], pts[a], pts[o]) >= 0 {
+					ear = false
+					break
+				}
+			}
+			if ear {
+				tris = append(tris, [3]int{a, b, c})
+				idx = append(idx[:i], idx[i+1:]...)
+				clipped = true
+				break
+			}
+		}
+		if !clipped {
+			break
+		}
+	}
+	if len(idx) == 3 {
+		tris = append(tris, [3]int{idx[0], idx[1], idx[2]})
+	}
+	return tris
+}
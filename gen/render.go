@@ -0,0 +1,182 @@
+package model
+
+import (
+	"goworld/pb"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/fogleman/fauxgl"
+)
+
+//Camera frames a rendered scene. It projects orthographically when Ortho, the half
+//height of its view, is set, and in perspective with a vertical field of view of Fov
+//degrees otherwise.
+type Camera struct {
+	Eye    [3]float64
+	Target [3]float64
+	Up     [3]float64
+	Ortho  float64
+	Fov    float64
+	Near   float64
+	Far    float64
+}
+
+//Instance is a mesh placed in a rendered scene, coloured by its texture, if any, tinted
+//by a hex colour the way materials are
+type Instance struct {
+	Mesh     *pb.Mesh
+	Position [3]float64
+	//Rotation is a quaternion (x, y, z, w), none when zero
+	Rotation [4]float64
+	Texture  image.Image
+	Color    string
+}
+
+//sky is the colour rendered scenes are cleared to
+var sky = fauxgl.Color{R: 0.53, G: 0.72, B: 0.9, A: 1}
+
+//Render rasterises instances seen from a camera into an image of a size, lit from a
+//direction by a white light and an ambient one
+func Render(instances []Instance, camera Camera, width, height int, light [3]float64) (image.Image, error) {
+	ctx := fauxgl.NewContext(width, height)
+	ctx.ClearColorBufferWith(sky)
+	ctx.ClearDepthBuffer()
+	aspect := float64(width) / float64(height)
+	projection := fauxgl.Perspective(camera.Fov, aspect, camera.Near, camera.Far)
+	if camera.Ortho > 0 {
+		h := camera.Ortho
+		projection = fauxgl.Orthographic(-h*aspect, h*aspect, -h, h, camera.Near, camera.Far)
+	}
+	eye := vector(camera.Eye)
+	matrix := projection.Mul(fauxgl.LookAt(eye, vector(camera.Target), vector(camera.Up)))
+	textures := map[tint]fauxgl.Texture{}
+	for _, in := range instances {
+		shader := fauxgl.NewPhongShader(matrix, vector(normalize(light)), eye)
+		shader.AmbientColor = fauxgl.Gray(0.45)
+		shader.DiffuseColor = fauxgl.Gray(0.65)
+		shader.SpecularColor = fauxgl.Black
+		c, err := parseColor(in.Color)
+		if err != nil {
+			return nil, err
+		}
+		if in.Texture != nil {
+			k := tint{in.Texture, c}
+			if _, ok := textures[k]; !ok {
+				textures[k] = fauxgl.NewImageTexture(tinted(in.Texture, c))
+			}
+			shader.Texture = textures[k]
+		} else {
+			shader.ObjectColor = fauxgl.Color{R: float64(c.R) / 255, G: float64(c.G) / 255, B: float64(c.B) / 255, A: 1}
+		}
+		ctx.Shader = shader
+		ctx.DrawTriangles(triangles(in))
+	}
+	return ctx.Image(), nil
+}
+
+//tint is a texture multiplied by a colour
+type tint struct {
+	image image.Image
+	color color.RGBA
+}
+
+func parseColor(c string) (color.RGBA, error) {
+	if c == "" {
+		return color.RGBA{R: 255, G: 255, B: 255, A: 255}, nil
+	}
+	p, err := palette([]string{c})
+	if err != nil {
+		return color.RGBA{}, err
+	}
+	return p[0], nil
+}
+
+//tinted multiplies an image by a colour, which fauxgl's shaders do not do with textures
+func tinted(img image.Image, c color.RGBA) image.Image {
+	b := img.Bounds()
+	out := image.NewNRGBA(b)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			p := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			out.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(uint32(p.R) * uint32(c.R) / 255),
+				G: uint8(uint32(p.G) * uint32(c.G) / 255),
+				B: uint8(uint32(p.B) * uint32(c.B) / 255),
+				A: p.A,
+			})
+		}
+	}
+	return out
+}
+
+//triangles places the faces of an instance in the scene
+func triangles(in Instance) []*fauxgl.Triangle {
+	m := in.Mesh
+	q := in.Rotation
+	if q == ([4]float64{}) {
+		q[3] = 1
+	}
+	vertex := func(i uint64, u, v float64) fauxgl.Vertex {
+		p := [3]float64{m.Vertices[3*i], m.Vertices[3*i+1], m.Vertices[3*i+2]}
+		n := [3]float64{0, 1, 0}
+		if len(m.Normals) >= len(m.Vertices) {
+			n = [3]float64{m.Normals[3*i], m.Normals[3*i+1], m.Normals[3*i+2]}
+		}
+		return fauxgl.Vertex{
+			Position: vector(madd3(rotateByQuaternion(q, p), in.Position, 1)),
+			Normal:   vector(rotateByQuaternion(q, n)),
+			Texture:  fauxgl.Vector{X: u, Y: v},
+		}
+	}
+	ts := make([]*fauxgl.Triangle, 0, len(m.Faces))
+	for _, f := range m.Faces {
+		uv := make([]float64, 6)
+		copy(uv, f.Uvs)
+		ts = append(ts, &fauxgl.Triangle{
+			V1: vertex(f.A, uv[0], uv[1]),
+			V2: vertex(f.B, uv[2], uv[3]),
+			V3: vertex(f.C, uv[4], uv[5]),
+		})
+	}
+	return ts
+}
+
+//rotateByQuaternion turns a vector by a unit quaternion (x, y, z, w)
+func rotateByQuaternion(q [4]float64, v [3]float64) [3]float64 {
+	u := [3]float64{q[0], q[1], q[2]}
+	t := cross3(u, v)
+	t = [3]float64{2 * t[0], 2 * t[1], 2 * t[2]}
+	return madd3(madd3(v, t, q[3]), cross3(u, t), 1)
+}
+
+func vector(v [3]float64) fauxgl.Vector {
+	return fauxgl.Vector{X: v[0], Y: v[1], Z: v[2]}
+}
+
+//FitCamera returns a camera framing a box of the world from lo to hi: looking straight
+//down with north up when top is set, as for a map, and from above its south-east corner
+//in perspective otherwise. It also returns the aspect ratio the box is best rendered at.
+func FitCamera(lo, hi [3]float64, top bool) (Camera, float64) {
+	c := lerp3(lo, hi, 0.5)
+	size := sub3(hi, lo)
+	if top {
+		return Camera{
+			Eye:    [3]float64{c[0], hi[1] + 1, c[2]},
+			Target: c,
+			Up:     [3]float64{0, 0, -1},
+			Ortho:  size[2] / 2,
+			Near:   0.5,
+			Far:    size[1] + 2,
+		}, size[0] / size[2]
+	}
+	r := math.Sqrt(dot3(size, size)) / 2
+	return Camera{
+		Eye:    madd3(c, [3]float64{0.9, 0.8, 0.9}, r),
+		Target: c,
+		Up:     [3]float64{0, 1, 0},
+		Fov:    45,
+		Near:   1,
+		Far:    r * 4,
+	}, 4.0 / 3
+}
@@ -0,0 +1,272 @@
+package model
+
+import (
+	"container/heap"
+	"goworld/pb"
+	"math"
+)
+
+//quadric is the symmetric 4x4 error matrix of Garland and Heckbert, stored as
+//its upper triangle
+type quadric [10]float64
+
+func planeQuadric(n [3]float64, d float64, w float64) quadric {
+	a, b, c := n[0], n[1], n[2]
+	return quadric{a * a * w, a * b * w, a * c * w, a * d * w, b * b * w, b * c * w, b * d * w, c * c * w, c * d * w, d * d * w}
+}
+
+func (q quadric) add(o quadric) quadric {
+	for i := range q {
+		q[i] += o[i]
+	}
+	return q
+}
+
+func (q quadric) error(v [3]float64) float64 {
+	x, y, z := v[0], v[1], v[2]
+	return q[0]*x*x + 2*q[1]*x*y + 2*q[2]*x*z + 2*q[3]*x + q[4]*y*y + 2*q[5]*y*z + 2*q[6]*y + q[7]*z*z + 2*q[8]*z + q[9]
+}
+
+type collapse struct {
+	cost     float64
+	a, b     int
+	target   [3]float64
+	versions [2]int
+}
+
+type collapseQueue []*collapse
+
+func (q collapseQueue) Len() int            { return len(q) }
+func (q collapseQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q collapseQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *collapseQueue) Push(x interface{}) { *q = append(*q, x.(*collapse)) }
+func (q *collapseQueue) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+type simplifyFace struct {
+	v       [3]int
+	corners [3]csgVertex
+	removed bool
+}
+
+type simplifier struct {
+	pos      [][3]float64
+	quadrics []quadric
+	faces    []*simplifyFace
+	around   [][]int
+	removed  []bool
+	versions []int
+	queue    collapseQueue
+}
+
+//Simplify collapses edges until at most target faces remain, always picking the
+//collapse that least changes the shape. Vertices sharing a position are welded
+//so that UV and normal seams do not open up.
+func Simplify(m *pb.Mesh, target int) *pb.Mesh {
+	polygons := csgPolygons(m)
+	if target >= len(polygons) {
+		return m
+	}
+	s := new(simplifier)
+	index := map[[3]float64]int{}
+	for _, p := range polygons {
+		f := new(simplifyFace)
+		for k, v := range p.vertices {
+			i, ok := index[v.pos]
+			if !ok {
+				i = len(s.pos)
+				index[v.pos] = i
+				s.pos = append(s.pos, v.pos)
+				s.quadrics = append(s.quadrics, quadric{})
+				s.around = append(s.around, nil)
+			}
+			f.v[k] = i
+			f.corners[k] = v
+			s.around[i] = append(s.around[i], len(s.faces))
+			s.quadrics[i] = s.quadrics[i].add(planeQuadric(p.plane.normal, -p.plane.w, 1))
+		}
+		s.faces = append(s.faces, f)
+	}
+	s.removed = make([]bool, len(s.pos))
+	s.versions = make([]int, len(s.pos))
+	s.constrainBoundaries()
+	for i := range s.pos {
+		for _, j := range s.neighbours(i) {
+			if i < j {
+				s.push(i, j)
+			}
+		}
+	}
+	faces := len(s.faces)
+	for faces > target && s.queue.Len() > 0 {
+		c := heap.Pop(&s.queue).(*collapse)
+		if s.removed[c.a] || s.removed[c.b] || s.versions[c.a] != c.versions[0] || s.versions[c.b] != c.versions[1] {
+			continue
+		}
+		if s.flips(c.a, c.b, c.target) || s.flips(c.b, c.a, c.target) {
+			continue
+		}
+		faces -= s.collapse(c)
+	}
+	out := []csgPolygon{}
+	for _, f := range s.faces {
+		if f.removed {
+			continue
+		}
+		p := csgPolygon{vertices: make([]csgVertex, 3)}
+		for k := range f.v {
+			p.vertices[k] = f.corners[k]
+			p.vertices[k].pos = s.pos[f.v[k]]
+		}
+		out = append(out, p)
+	}
+	return csgMesh(out, hasUVs(m))
+}
+
+//constrainBoundaries adds heavily weighted planes perpendicular to open edges
+//so that the outline of open meshes is kept
+func (s *simplifier) constrainBoundaries() {
+	edges := map[[2]int]int{}
+	for _, f := range s.faces {
+		for k := 0; k < 3; k++ {
+			a, b := f.v[k], f.v[(k+1)%3]
+			if a > b {
+				a, b = b, a
+			}
+			edges[[2]int{a, b}]++
+		}
+	}
+	for _, f := range s.faces {
+		n := normalize(cross3(sub3(s.pos[f.v[1]], s.pos[f.v[0]]), sub3(s.pos[f.v[2]], s.pos[f.v[0]])))
+		for k := 0; k < 3; k++ {
+			a, b := f.v[k], f.v[(k+1)%3]
+			key := [2]int{a, b}
+			if a > b {
+				key = [2]int{b, a}
+			}
+			if edges[key] != 1 {
+				continue
+			}
+			p := normalize(cross3(sub3(s.pos[b], s.pos[a]), n))
+			q := planeQuadric(p, -dot3(p, s.pos[a]), 1000)
+			s.quadrics[a] = s.quadrics[a].add(q)
+			s.quadrics[b] = s.quadrics[b].add(q)
+		}
+	}
+}
+
+func (s *simplifier) neighbours(i int) []int {
+	seen := map[int]bool{}
+	out := []int{}
+	for _, fi := range s.around[i] {
+		f := s.faces[fi]
+		if f.removed {
+			continue
+		}
+		for _, j := range f.v {
+			if j != i && !seen[j] {
+				seen[j] = true
+				out = append(out, j)
+			}
+		}
+	}
+	return out
+}
+
+func (s *simplifier) push(a, b int) {
+	q := s.quadrics[a].add(s.quadrics[b])
+	c := &collapse{a: a, b: b, cost: math.Inf(1), versions: [2]int{s.versions[a], s.versions[b]}}
+	for _, t := range [][3]float64{s.pos[a], s.pos[b], lerp3(s.pos[a], s.pos[b], 0.5)} {
+		if e := q.error(t); e < c.cost {
+			c.cost, c.target = e, t
+		}
+	}
+	heap.Push(&s.queue, c)
+}
+
+//flips reports whether moving a to target would turn over one of the faces
+//around a that does not also contain b
+func (s *simplifier) flips(a, b int, target [3]float64) bool {
+	for _, fi := range s.around[a] {
+		f := s.faces[fi]
+		if f.removed || f.v[0] == b || f.v[1] == b || f.v[2] == b {
+			continue
+		}
+		p := [3][3]float64{s.pos[f.v[0]], s.pos[f.v[1]], s.pos[f.v[2]]}
+		before := cross3(sub3(p[1], p[0]), sub3(p[2], p[0]))
+		for k := range f.v {
+			if f.v[k] == a {
+				p[k] = target
+			}
+		}
+		after := cross3(sub3(p[1], p[0]), sub3(p[2], p[0]))
+		if dot3(normalize(before), normalize(after)) < 0.2 {
+			return true
+		}
+	}
+	return false
+}
+
+//collapse merges b into a and returns the number of faces removed
+func (s *simplifier) collapse(c *collapse) int {
+	a, b := c.a, c.b
+	s.pos[a] = c.target
+	s.quadrics[a] = s.quadrics[a].add(s.quadrics[b])
+	s.removed[b] = true
+	s.versions[a]++
+	removed := 0
+	var corner *csgVertex
+	for _, fi := range s.around[b] {
+		f := s.faces[fi]
+		if f.removed {
+			continue
+		}
+		for k := range f.v {
+			if f.v[k] == a && (f.v[0] == b || f.v[1] == b || f.v[2] == b) {
+				v := f.corners[k]
+				corner = &v
+			}
+		}
+	}
+	for _, fi := range s.around[b] {
+		f := s.faces[fi]
+		if f.removed {
+			continue
+		}
+		if f.v[0] == a || f.v[1] == a || f.v[2] == a {
+			f.removed = true
+			removed++
+			continue
+		}
+		for k := range f.v {
+			if f.v[k] == b {
+				f.v[k] = a
+				if corner != nil {
+					f.corners[k].uv = corner.uv
+				}
+			}
+		}
+		s.around[a] = append(s.around[a], fi)
+	}
+	for _, j := range s.neighbours(a) {
+		s.push(a, j)
+	}
+	return removed
+}
+
+//LODs returns count progressively coarser versions of a mesh, each with half
+//the faces of the one before
+func LODs(m *pb.Mesh, count int) []*pb.Mesh {
+	lods := []*pb.Mesh{}
+	faces := len(m.Faces)
+	for i := 0; i < count && faces > 8; i++ {
+		faces /= 2
+		m = Simplify(m, faces)
+		lods = append(lods, m)
+	}
+	return lods
+}
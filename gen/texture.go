@@ -0,0 +1,212 @@
+package model
+
+import (
+	"bytes"
+	"goworld/gen/noise"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+//TextureRecipe describes a procedurally generated texture. The pattern yields a
+//value between 0 and 1 for every pixel, which is mapped onto Colors as evenly
+//spaced stops. Scale is the number of features across the texture: noise
+//frequency, marble veins, checker cells, stripes or brick rows.
+type TextureRecipe struct {
+	Pattern string
+	Size    int
+	Colors  []string
+	Scale   float64
+	Octaves int
+	Seed    int64
+}
+
+//TextureRecipes are ready made recipes for common terrain and building materials
+var TextureRecipes = map[string]TextureRecipe{
+	"stone":  {Pattern: "noise", Size: 128, Colors: []string{"#5a5a5e", "#7d7b7a", "#9a9894"}, Scale: 6, Octaves: 5, Seed: 1},
+	"grass":  {Pattern: "noise", Size: 128, Colors: []string{"#2f4f1a", "#4a7a23", "#6b9a34"}, Scale: 12, Octaves: 4, Seed: 2},
+	"dirt":   {Pattern: "noise", Size: 128, Colors: []string{"#4a3320", "#6b4a2e", "#7e5a3a"}, Scale: 10, Octaves: 4, Seed: 3},
+	"sand":   {Pattern: "noise", Size: 128, Colors: []string{"#c2a66b", "#d8c08a", "#e6d3a3"}, Scale: 24, Octaves: 3, Seed: 4},
+	"marble": {Pattern: "marble", Size: 256, Colors: []string{"#e8e6e1", "#b9b6b0", "#f4f2ee"}, Scale: 3, Octaves: 5, Seed: 5},
+	"brick":  {Pattern: "bricks", Size: 128, Colors: []string{"#b8b2a6", "#7a2e1f", "#9c4028"}, Scale: 8, Octaves: 3, Seed: 6},
+	"tiles":  {Pattern: "checker", Size: 64, Colors: []string{"#d9d9d9", "#3b3b3b"}, Scale: 8},
+	//foliage and facade are the palettes plants and buildings are coloured with
+	"foliage": {Pattern: "bands", Size: 64, Colors: []string{"#5e4127", "#3f6e2a"}},
+	"facade":  {Pattern: "bands", Size: 64, Colors: []string{"#cbbda2", "#2d3a48", "#7a3324", "#4d3220"}},
+}
+
+var texturePatterns = map[string]func(r TextureRecipe, n *noise.Noise) func(u, v float64) float64{
+	"noise":    noisePattern,
+	"marble":   marblePattern,
+	"gradient": gradientPattern,
+	"checker":  checkerPattern,
+	"stripes":  stripesPattern,
+	"bricks":   bricksPattern,
+	"bands":    bandsPattern,
+}
+
+//Check reports whether the recipe can be rendered
+func (r TextureRecipe) Check() error {
+	if _, ok := texturePatterns[r.Pattern]; !ok {
+		return errors.Errorf("unknown pattern %q", r.Pattern)
+	}
+	if r.Size <= 0 || r.Size > 2048 {
+		return errors.Errorf("texture size %d out of range", r.Size)
+	}
+	if len(r.Colors) == 0 {
+		return errors.Errorf("texture without colors")
+	}
+	_, err := palette(r.Colors)
+	return err
+}
+
+//RenderTexture renders a recipe to an image. Every pattern but gradient tiles seamlessly.
+func RenderTexture(r TextureRecipe) (image.Image, error) {
+	if err := r.Check(); err != nil {
+		return nil, err
+	}
+	stops, _ := palette(r.Colors)
+	f := texturePatterns[r.Pattern](r, noise.New(r.Seed))
+	img := image.NewRGBA(image.Rect(0, 0, r.Size, r.Size))
+	for y := 0; y < r.Size; y++ {
+		for x := 0; x < r.Size; x++ {
+			t := f((float64(x)+0.5)/float64(r.Size), (float64(y)+0.5)/float64(r.Size))
+			img.Set(x, y, sample(stops, t))
+		}
+	}
+	return img, nil
+}
+
+//TexturePNG renders a recipe and encodes it as a PNG
+func TexturePNG(r TextureRecipe) ([]byte, error) {
+	img, err := RenderTexture(r)
+	if err != nil {
+		return nil, err
+	}
+	return EncodePNG(img)
+}
+
+//EncodePNG encodes an image as a PNG
+func EncodePNG(img image.Image) ([]byte, error) {
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	return b.Bytes(), nil
+}
+
+func palette(colors []string) ([]color.RGBA, error) {
+	stops := make([]color.RGBA, len(colors))
+	for i, c := range colors {
+		v, err := strconv.ParseUint(strings.TrimPrefix(c, "#"), 16, 32)
+		if err != nil || len(strings.TrimPrefix(c, "#")) != 6 {
+			return nil, errors.Errorf("invalid color %q", c)
+		}
+		stops[i] = color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}
+	}
+	return stops, nil
+}
+
+//sample interpolates between evenly spaced palette stops
+func sample(stops []color.RGBA, t float64) color.RGBA {
+	if len(stops) == 1 {
+		return stops[0]
+	}
+	t = math.Max(0, math.Min(1, t)) * float64(len(stops)-1)
+	i := int(math.Min(t, float64(len(stops)-2)))
+	f := t - float64(i)
+	a, b := stops[i], stops[i+1]
+	mix := func(p, q uint8) uint8 {
+		return uint8(math.Round(float64(p) + (float64(q)-float64(p))*f))
+	}
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 255}
+}
+
+//tiled blends fractal noise with copies of itself shifted by one period on each axis,
+//so that the result wraps around the unit square without seams
+func tiled(n *noise.Noise, scale float64, octaves int) func(u, v float64) float64 {
+	if octaves <= 0 {
+		octaves = 1
+	}
+	f := func(u, v float64) float64 {
+		return n.FBM2(u*scale, v*scale, octaves)
+	}
+	return func(u, v float64) float64 {
+		return f(u, v)*(1-u)*(1-v) + f(u-1, v)*u*(1-v) + f(u, v-1)*(1-u)*v + f(u-1, v-1)*u*v
+	}
+}
+
+func noisePattern(r TextureRecipe, n *noise.Noise) func(u, v float64) float64 {
+	f := tiled(n, r.Scale, r.Octaves)
+	return func(u, v float64) float64 {
+		return f(u, v)*1.5 + 0.5
+	}
+}
+
+func marblePattern(r TextureRecipe, n *noise.Noise) func(u, v float64) float64 {
+	f := tiled(n, 4, r.Octaves)
+	veins := math.Max(1, math.Round(r.Scale))
+	return func(u, v float64) float64 {
+		return math.Sin(2*math.Pi*(veins*(u+v)+2*f(u, v)))*0.5 + 0.5
+	}
+}
+
+func gradientPattern(r TextureRecipe, n *noise.Noise) func(u, v float64) float64 {
+	return func(u, v float64) float64 {
+		return v
+	}
+}
+
+func checkerPattern(r TextureRecipe, n *noise.Noise) func(u, v float64) float64 {
+	cells := math.Max(1, math.Round(r.Scale))
+	return func(u, v float64) float64 {
+		return float64((int(u*cells) + int(v*cells)) % 2)
+	}
+}
+
+func stripesPattern(r TextureRecipe, n *noise.Noise) func(u, v float64) float64 {
+	stripes := math.Max(1, math.Round(r.Scale))
+	return func(u, v float64) float64 {
+		return float64(int(v*stripes*2) % 2)
+	}
+}
+
+//bandsPattern splits the texture across into one band of flat color for each of Colors
+func bandsPattern(r TextureRecipe, n *noise.Noise) func(u, v float64) float64 {
+	bands := float64(len(r.Colors))
+	return func(u, v float64) float64 {
+		if bands < 2 {
+			return 0
+		}
+		return math.Min(math.Floor(u*bands), bands-1) / (bands - 1)
+	}
+}
+
+//bricksPattern lays rows of bricks twice as wide as they are high, every other row
+//offset by half a brick. Mortar takes the first color and each brick a shade from the rest.
+func bricksPattern(r TextureRecipe, n *noise.Noise) func(u, v float64) float64 {
+	rows := math.Max(2, 2*math.Round(r.Scale/2))
+	cols := rows / 2
+	mortar := 0.06
+	grain := tiled(n, 16, r.Octaves)
+	return func(u, v float64) float64 {
+		y := v * rows
+		row := math.Floor(y)
+		x := u * cols
+		if int(row)%2 == 1 {
+			x += 0.5
+		}
+		col := math.Floor(x)
+		fx, fy := x-col, y-row
+		if fx < mortar/2 || fx > 1-mortar/2 || fy < mortar || fy > 1-mortar {
+			return 0
+		}
+		shade := n.Eval2(math.Mod(col, cols)*7.31+0.5, row*3.17+0.5)
+		return 0.5 + 0.5*math.Max(0.1, math.Min(1, 0.55+shade+grain(u, v)*0.2))
+	}
+}
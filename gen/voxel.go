@@ -0,0 +1,187 @@
+package model
+
+import (
+	"goworld/gen/noise"
+	"goworld/pb"
+	"math"
+)
+
+//Density tells how solid the world is at a point. Positive values are solid
+//and the surface lies where the density crosses zero.
+type Density func(x, y, z float64) float64
+
+//Terrain returns a density of rolling hills with overhangs and caves carved into them
+func Terrain(seed int64) Density {
+	n := noise.New(seed)
+	return terrain(n, func(x, z float64) float64 {
+		return -6 + n.FBM2(x/48, z/48, 4)*8
+	})
+}
+
+//terrain roughens a height field with overhangs and carves caves into it
+func terrain(n *noise.Noise, height func(x, z float64) float64) Density {
+	return func(x, y, z float64) float64 {
+		d := height(x, z) - y
+		d += n.FBM3(x/16, y/16, z/16, 3) * 4
+		if c := (math.Abs(n.Eval3(x/12+100, y/12, z/12)) - 0.06) * 30; c < d {
+			d = c
+		}
+		return d
+	}
+}
+
+//Sculpt is a spherical edit that digs the density away or builds it up
+type Sculpt struct {
+	Centre [3]float64
+	Radius float64
+	Build  bool
+}
+
+//sculptBlend is how far beyond its radius a sculpt still reshapes the density
+const sculptBlend = 2
+
+//Reach returns the distance from its centre beyond which a sculpt leaves the density untouched
+func (s Sculpt) Reach() float64 {
+	return s.Radius + sculptBlend
+}
+
+//Sculpted returns the density with the sculpts applied over it in order
+func Sculpted(d Density, sculpts []Sculpt) Density {
+	if len(sculpts) == 0 {
+		return d
+	}
+	return func(x, y, z float64) float64 {
+		v := d(x, y, z)
+		for _, s := range sculpts {
+			r := math.Sqrt((x-s.Centre[0])*(x-s.Centre[0]) + (y-s.Centre[1])*(y-s.Centre[1]) + (z-s.Centre[2])*(z-s.Centre[2]))
+			if r >= s.Reach() {
+				continue
+			}
+			if s.Build {
+				v = math.Max(v, s.Radius-r)
+			} else {
+				v = math.Min(v, r-s.Radius)
+			}
+		}
+		return v
+	}
+}
+
+//ChunkMargin returns how far outside its bounds Voxels samples the density of a chunk
+func ChunkMargin(size float64, resolution int) float64 {
+	return 2 * size / float64(resolution)
+}
+
+//ChunkOrigin returns the world position of the centre of a chunk.
+//Chunk (x, y, z) spans size units around (x, y, z) * size.
+func ChunkOrigin(x, y, z int64, size float64) [3]float64 {
+	return [3]float64{float64(x) * size, float64(y) * size, float64(z) * size}
+}
+
+//Voxels meshes the surface of a density inside a chunk with surface nets, a dual
+//contouring scheme that places one vertex per cell at the mean of its edge crossings.
+//The density is sampled on a world aligned lattice of resolution cells per side and
+//each chunk only emits the faces of lattice edges that start inside it, so adjacent
+//chunks share their boundary vertices exactly and stitch without cracks.
+//Vertices are relative to ChunkOrigin.
+func Voxels(d Density, x, y, z int64, size float64, resolution int) (*pb.Mesh, Shape) {
+	step := size / float64(resolution)
+	o := ChunkOrigin(x, y, z, size)
+	base := [3]int64{x*int64(resolution) - int64(resolution/2), y*int64(resolution) - int64(resolution/2), z*int64(resolution) - int64(resolution/2)}
+	world := func(i, j, k int) [3]float64 {
+		return [3]float64{float64(base[0]+int64(i)) * step, float64(base[1]+int64(j)) * step, float64(base[2]+int64(k)) * step}
+	}
+	//samples cover lattice points -1..resolution+1 on each axis
+	n := resolution + 3
+	samples := make([]float64, n*n*n)
+	at := func(i, j, k int) float64 {
+		return samples[(i+1)+(j+1)*n+(k+1)*n*n]
+	}
+	for k := -1; k <= resolution+1; k++ {
+		for j := -1; j <= resolution+1; j++ {
+			for i := -1; i <= resolution+1; i++ {
+				p := world(i, j, k)
+				samples[(i+1)+(j+1)*n+(k+1)*n*n] = d(p[0], p[1], p[2])
+			}
+		}
+	}
+	b := newBuilder()
+	//cells -1..resolution, each spanning lattice points i..i+1
+	cells := make([]int64, (n-1)*(n-1)*(n-1))
+	cell := func(i, j, k int) int {
+		return (i + 1) + (j+1)*(n-1) + (k+1)*(n-1)*(n-1)
+	}
+	for k := -1; k <= resolution; k++ {
+		for j := -1; j <= resolution; j++ {
+			for i := -1; i <= resolution; i++ {
+				cells[cell(i, j, k)] = -1
+				sum, count := [3]float64{}, 0.0
+				for e := 0; e < 12; e++ {
+					a, c := cubeEdges[e][0], cubeEdges[e][1]
+					s0, s1 := at(i+a[0], j+a[1], k+a[2]), at(i+c[0], j+c[1], k+c[2])
+					if (s0 > 0) == (s1 > 0) {
+						continue
+					}
+					t := s0 / (s0 - s1)
+					p := lerp3(world(i+a[0], j+a[1], k+a[2]), world(i+c[0], j+c[1], k+c[2]), t)
+					for m := range sum {
+						sum[m] += p[m]
+					}
+					count++
+				}
+				if count == 0 {
+					continue
+				}
+				p := [3]float64{sum[0] / count, sum[1] / count, sum[2] / count}
+				h := step / 2
+				g := [3]float64{
+					d(p[0]+h, p[1], p[2]) - d(p[0]-h, p[1], p[2]),
+					d(p[0], p[1]+h, p[2]) - d(p[0], p[1]-h, p[2]),
+					d(p[0], p[1], p[2]+h) - d(p[0], p[1], p[2]-h),
+				}
+				normal := normalize([3]float64{-g[0], -g[1], -g[2]})
+				cells[cell(i, j, k)] = int64(b.vertex(sub3(p, o), normal))
+			}
+		}
+	}
+	for axis := 0; axis < 3; axis++ {
+		u, v := (axis+1)%3, (axis+2)%3
+		for k := 0; k < resolution; k++ {
+			for j := 0; j < resolution; j++ {
+				for i := 0; i < resolution; i++ {
+					p := [3]int{i, j, k}
+					q := p
+					q[axis]++
+					s0, s1 := at(p[0], p[1], p[2]), at(q[0], q[1], q[2])
+					if (s0 > 0) == (s1 > 0) {
+						continue
+					}
+					quad := [4]int64{}
+					for c, off := range [4][2]int{{-1, -1}, {0, -1}, {0, 0}, {-1, 0}} {
+						r := p
+						r[u] += off[0]
+						r[v] += off[1]
+						quad[c] = cells[cell(r[0], r[1], r[2])]
+					}
+					if s1 > 0 {
+						quad[1], quad[3] = quad[3], quad[1]
+					}
+					uvs := make([]float64, 0, 8)
+					for _, c := range quad {
+						w := b.position(uint64(c))
+						uvs = append(uvs, (w[u]+o[u])/4, (w[v]+o[v])/4)
+					}
+					b.face(uint64(quad[0]), uint64(quad[1]), uint64(quad[2]), []float64{uvs[0], uvs[1], uvs[2], uvs[3], uvs[4], uvs[5]})
+					b.face(uint64(quad[0]), uint64(quad[2]), uint64(quad[3]), []float64{uvs[0], uvs[1], uvs[4], uvs[5], uvs[6], uvs[7]})
+				}
+			}
+		}
+	}
+	return b.mesh, Shape{Type: pb.Body_MESH}
+}
+
+var cubeEdges = [12][2][3]int{
+	{{0, 0, 0}, {1, 0, 0}}, {{0, 1, 0}, {1, 1, 0}}, {{0, 0, 1}, {1, 0, 1}}, {{0, 1, 1}, {1, 1, 1}},
+	{{0, 0, 0}, {0, 1, 0}}, {{1, 0, 0}, {1, 1, 0}}, {{0, 0, 1}, {0, 1, 1}}, {{1, 0, 1}, {1, 1, 1}},
+	{{0, 0, 0}, {0, 0, 1}}, {{1, 0, 0}, {1, 0, 1}}, {{0, 1, 0}, {0, 1, 1}}, {{1, 1, 0}, {1, 1, 1}},
+}
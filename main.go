@@ -1,21 +1,200 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"goworld/assets"
+	"goworld/config"
 	"goworld/connector"
+	"goworld/gen"
 	"goworld/logging"
+	"goworld/pb"
 	"goworld/world"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-errors/errors"
 )
 
+//commands are run in place of the server when named as the first argument
+var commands = map[string]func(args []string) error{
+	"pregen":  pregen,
+	"preview": preview,
+	"token":   token,
+}
+
 func main() {
-	c := connector.Init()
-	logging.L("HTTP listening on :8081")
+	args := os.Args[1:]
+	command := serve
+	if len(args) > 0 {
+		if c, ok := commands[args[0]]; ok {
+			command, args = c, args[1:]
+		}
+	}
+	if err := command(args); err != nil {
+		logging.Error(err)
+		os.Exit(1)
+	}
+}
+
+//serve runs the server until it receives SIGINT or SIGTERM, then shuts it down gracefully.
+//A second signal kills it right away. It refuses to start without a token secret unless
+//told to let anyone in with -anonymous.
+func serve(args []string) error {
+	f := flag.NewFlagSet("goworld", flag.ExitOnError)
+	anonymous := f.Bool("anonymous", false, "let anyone connect under a random ID when no token secret is configured")
+	cfg, err := config.Parse(f, args)
+	if err != nil {
+		return err
+	}
+	var verifier connector.Verifier
+	switch {
+	case cfg.TokenSecret != "":
+		verifier = connector.NewTokens(cfg.TokenSecret)
+	case *anonymous:
+		verifier = connector.Anonymous{}
+		logging.L("No token secret configured, anyone may connect")
+	default:
+		return errors.Errorf("no token secret configured, set one or run with -anonymous to let anyone connect")
+	}
+	a, err := assets.Load(cfg.Assets)
+	if err != nil {
+		return err
+	}
+	c, err := connector.Init(cfg.Listen, cfg.ICEServers, connector.Banning(verifier, cfg.Banned))
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	logging.L("HTTP listening on " + cfg.Listen)
 	logging.Green()
-	w := world.New()
-	c.OnPeerConnected(func(p *connector.Peer) {
+	w := world.New(a, cfg)
+	c.OnPeerConnected(func(p connector.Peer) {
 		w.AddPlayer(p)
 	})
-	c.OnPeerDisconnected(func(p *connector.Peer) {
+	c.OnPeerDisconnected(func(p connector.Peer) {
 		w.RemovePlayer(p)
 	})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		s := <-signals
+		signal.Reset(os.Interrupt, syscall.SIGTERM)
+		logging.L("Received " + s.String() + ", shutting down")
+		c.Stop()
+		w.Shutdown()
+		c.Close()
+	}()
 	c.Wait()
+	logging.L("Shut down")
+	return nil
+}
+
+//pregen generates the chunks between two corners into the chunk store, as in
+//goworld pregen -from -4,-2,-4 -to 4,2,4 -workers 8
+func pregen(args []string) error {
+	f := flag.NewFlagSet("pregen", flag.ExitOnError)
+	from := f.String("from", "", "corner of the chunks to generate, as x,y,z")
+	to := f.String("to", "", "opposite corner of the chunks to generate, as x,y,z")
+	workers := f.Int("workers", runtime.NumCPU(), "number of chunks generated at once")
+	cfg, err := config.Parse(f, args)
+	if err != nil {
+		return err
+	}
+	a, err := parseLocation(*from)
+	if err != nil {
+		return err
+	}
+	b, err := parseLocation(*to)
+	if err != nil {
+		return err
+	}
+	return world.Pregenerate(cfg, a, b, *workers)
+}
+
+//preview renders a map or a perspective view of the chunks between two corners into a PNG file, as in
+//goworld preview -from -4,-1,-4 -to 4,1,4 -mode map -width 1024 -out map.png
+func preview(args []string) error {
+	f := flag.NewFlagSet("preview", flag.ExitOnError)
+	from := f.String("from", "", "corner of the chunks to render, as x,y,z")
+	to := f.String("to", "", "opposite corner of the chunks to render, as x,y,z")
+	mode := f.String("mode", "map", "map for a top-down view or perspective")
+	width := f.Int("width", 1024, "width of the image in pixels")
+	out := f.String("out", "preview.png", "PNG file to write")
+	cfg, err := config.Parse(f, args)
+	if err != nil {
+		return err
+	}
+	if *mode != "map" && *mode != "perspective" {
+		return errors.Errorf("unknown preview mode %q", *mode)
+	}
+	if *width <= 0 {
+		return errors.Errorf("invalid width %d", *width)
+	}
+	a, err := parseLocation(*from)
+	if err != nil {
+		return err
+	}
+	b, err := parseLocation(*to)
+	if err != nil {
+		return err
+	}
+	r, err := assets.Load(cfg.Assets)
+	if err != nil {
+		return err
+	}
+	img, err := world.RenderPreview(r, cfg, a, b, *mode == "map", *width)
+	if err != nil {
+		return err
+	}
+	data, err := model.EncodePNG(img)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(*out, data, 0644); err != nil {
+		return errors.Wrap(err, 0)
+	}
+	logging.L("Wrote " + *out)
+	return nil
+}
+
+//token prints a token letting a player connect, signed with the configured secret, as in
+//goworld token -player alice -ttl 720h
+func token(args []string) error {
+	f := flag.NewFlagSet("token", flag.ExitOnError)
+	player := f.String("player", "", "ID of the player")
+	ttl := f.Duration("ttl", 24*time.Hour, "how long the token is valid")
+	cfg, err := config.Parse(f, args)
+	if err != nil {
+		return err
+	}
+	if cfg.TokenSecret == "" {
+		return errors.Errorf("no token secret configured")
+	}
+	if *player == "" || *ttl <= 0 {
+		return errors.Errorf("a player and a positive ttl are needed")
+	}
+	fmt.Println(connector.NewTokens(cfg.TokenSecret).Sign(*player, *ttl))
+	return nil
+}
+
+func parseLocation(s string) (*pb.AbsoluteLocation, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 {
+		return nil, errors.Errorf("expected a chunk location as x,y,z, got %q", s)
+	}
+	var v [3]int64
+	for i, p := range parts {
+		n, err := strconv.ParseInt(strings.TrimSpace(p), 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, 0)
+		}
+		v[i] = n
+	}
+	return &pb.AbsoluteLocation{X: v[0], Y: v[1], Z: v[2]}, nil
 }
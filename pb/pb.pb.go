@@ -88,21 +88,27 @@ func (Material_Side) EnumDescriptor() ([]byte, []int) {
 type Body_Type int32
 
 const (
-	Body_MESH   Body_Type = 0
-	Body_BOX    Body_Type = 1
-	Body_SPHERE Body_Type = 2
+	Body_MESH     Body_Type = 0
+	Body_BOX      Body_Type = 1
+	Body_SPHERE   Body_Type = 2
+	Body_CAPSULE  Body_Type = 3
+	Body_CYLINDER Body_Type = 4
 )
 
 var Body_Type_name = map[int32]string{
 	0: "MESH",
 	1: "BOX",
 	2: "SPHERE",
+	3: "CAPSULE",
+	4: "CYLINDER",
 }
 
 var Body_Type_value = map[string]int32{
-	"MESH":   0,
-	"BOX":    1,
-	"SPHERE": 2,
+	"MESH":     0,
+	"BOX":      1,
+	"SPHERE":   2,
+	"CAPSULE":  3,
+	"CYLINDER": 4,
 }
 
 func (x Body_Type) String() string {
@@ -120,6 +126,8 @@ const (
 	Request_AUDIO    Request_Type = 1
 	Request_MATERIAL Request_Type = 2
 	Request_MESH     Request_Type = 3
+	Request_EDIT     Request_Type = 4
+	Request_SETTINGS Request_Type = 5
 )
 
 var Request_Type_name = map[int32]string{
@@ -127,6 +135,8 @@ var Request_Type_name = map[int32]string{
 	1: "AUDIO",
 	2: "MATERIAL",
 	3: "MESH",
+	4: "EDIT",
+	5: "SETTINGS",
 }
 
 var Request_Type_value = map[string]int32{
@@ -134,6 +144,8 @@ var Request_Type_value = map[string]int32{
 	"AUDIO":    1,
 	"MATERIAL": 2,
 	"MESH":     3,
+	"EDIT":     4,
+	"SETTINGS": 5,
 }
 
 func (x Request_Type) String() string {
@@ -144,14 +156,45 @@ func (Request_Type) EnumDescriptor() ([]byte, []int) {
 	return fileDescriptor_f80abaa17e25ccc8, []int{3, 0}
 }
 
+type PackedMesh_Encoding int32
+
+const (
+	PackedMesh_PROTO     PackedMesh_Encoding = 0
+	PackedMesh_FLOAT32   PackedMesh_Encoding = 1
+	PackedMesh_QUANTIZED PackedMesh_Encoding = 2
+)
+
+var PackedMesh_Encoding_name = map[int32]string{
+	0: "PROTO",
+	1: "FLOAT32",
+	2: "QUANTIZED",
+}
+
+var PackedMesh_Encoding_value = map[string]int32{
+	"PROTO":     0,
+	"FLOAT32":   1,
+	"QUANTIZED": 2,
+}
+
+func (x PackedMesh_Encoding) String() string {
+	return proto.EnumName(PackedMesh_Encoding_name, int32(x))
+}
+
+func (PackedMesh_Encoding) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f80abaa17e25ccc8, []int{5, 0}
+}
+
 type Response_Type int32
 
 const (
-	Response_TEXTURE  Response_Type = 0
-	Response_AUDIO    Response_Type = 1
-	Response_CHUNK    Response_Type = 2
-	Response_MATERIAL Response_Type = 3
-	Response_MESH     Response_Type = 4
+	Response_TEXTURE       Response_Type = 0
+	Response_AUDIO         Response_Type = 1
+	Response_CHUNK         Response_Type = 2
+	Response_MATERIAL      Response_Type = 3
+	Response_MESH          Response_Type = 4
+	Response_ASSET_CHANGED Response_Type = 5
+	Response_SHUTDOWN      Response_Type = 6
+	Response_PLAYER        Response_Type = 7
 )
 
 var Response_Type_name = map[int32]string{
@@ -160,14 +203,20 @@ var Response_Type_name = map[int32]string{
 	2: "CHUNK",
 	3: "MATERIAL",
 	4: "MESH",
+	5: "ASSET_CHANGED",
+	6: "SHUTDOWN",
+	7: "PLAYER",
 }
 
 var Response_Type_value = map[string]int32{
-	"TEXTURE":  0,
-	"AUDIO":    1,
-	"CHUNK":    2,
-	"MATERIAL": 3,
-	"MESH":     4,
+	"TEXTURE":       0,
+	"AUDIO":         1,
+	"CHUNK":         2,
+	"MATERIAL":      3,
+	"MESH":          4,
+	"ASSET_CHANGED": 5,
+	"SHUTDOWN":      6,
+	"PLAYER":        7,
 }
 
 func (x Response_Type) String() string {
@@ -175,7 +224,7 @@ func (x Response_Type) String() string {
 }
 
 func (Response_Type) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_f80abaa17e25ccc8, []int{5, 0}
+	return fileDescriptor_f80abaa17e25ccc8, []int{6, 0}
 }
 
 type Light_Type int32
@@ -212,7 +261,7 @@ func (x Light_Type) String() string {
 }
 
 func (Light_Type) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_f80abaa17e25ccc8, []int{6, 0}
+	return fileDescriptor_f80abaa17e25ccc8, []int{7, 0}
 }
 
 type Update_Type int32
@@ -234,7 +283,32 @@ func (x Update_Type) String() string {
 }
 
 func (Update_Type) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_f80abaa17e25ccc8, []int{15, 0}
+	return fileDescriptor_f80abaa17e25ccc8, []int{16, 0}
+}
+
+type VoxelEdit_Mode int32
+
+const (
+	VoxelEdit_DIG   VoxelEdit_Mode = 0
+	VoxelEdit_BUILD VoxelEdit_Mode = 1
+)
+
+var VoxelEdit_Mode_name = map[int32]string{
+	0: "DIG",
+	1: "BUILD",
+}
+
+var VoxelEdit_Mode_value = map[string]int32{
+	"DIG":   0,
+	"BUILD": 1,
+}
+
+func (x VoxelEdit_Mode) String() string {
+	return proto.EnumName(VoxelEdit_Mode_name, int32(x))
+}
+
+func (VoxelEdit_Mode) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f80abaa17e25ccc8, []int{17, 0}
 }
 
 type Material struct {
@@ -491,11 +565,17 @@ func (m *Texture) GetData() []byte {
 }
 
 type Request struct {
-	Id                   uint64       `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
-	Type                 Request_Type `protobuf:"varint,1,opt,name=type,proto3,enum=pb.Request_Type" json:"type,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
-	XXX_unrecognized     []byte       `json:"-"`
-	XXX_sizecache        int32        `json:"-"`
+	Id                   uint64              `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Type                 Request_Type        `protobuf:"varint,1,opt,name=type,proto3,enum=pb.Request_Type" json:"type,omitempty"`
+	Hash                 string              `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	Lod                  uint32              `protobuf:"varint,4,opt,name=lod,proto3" json:"lod,omitempty"`
+	Encoding             PackedMesh_Encoding `protobuf:"varint,5,opt,name=encoding,proto3,enum=pb.PackedMesh_Encoding" json:"encoding,omitempty"`
+	Deflate              bool                `protobuf:"varint,6,opt,name=deflate,proto3" json:"deflate,omitempty"`
+	Edit                 *VoxelEdit          `protobuf:"bytes,7,opt,name=edit,proto3" json:"edit,omitempty"`
+	Settings             []*Setting          `protobuf:"bytes,8,rep,name=settings,proto3" json:"settings,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
 }
 
 func (m *Request) Reset()         { *m = Request{} }
@@ -537,9 +617,52 @@ func (m *Request) GetType() Request_Type {
 	return Request_TEXTURE
 }
 
+func (m *Request) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *Request) GetLod() uint32 {
+	if m != nil {
+		return m.Lod
+	}
+	return 0
+}
+
+func (m *Request) GetEncoding() PackedMesh_Encoding {
+	if m != nil {
+		return m.Encoding
+	}
+	return PackedMesh_PROTO
+}
+
+func (m *Request) GetDeflate() bool {
+	if m != nil {
+		return m.Deflate
+	}
+	return false
+}
+
+func (m *Request) GetEdit() *VoxelEdit {
+	if m != nil {
+		return m.Edit
+	}
+	return nil
+}
+
+func (m *Request) GetSettings() []*Setting {
+	if m != nil {
+		return m.Settings
+	}
+	return nil
+}
+
 type Mesh struct {
 	Vertices             []float64    `protobuf:"fixed64,1,rep,packed,name=vertices,proto3" json:"vertices,omitempty"`
 	Faces                []*Mesh_Face `protobuf:"bytes,2,rep,name=faces,proto3" json:"faces,omitempty"`
+	Normals              []float64    `protobuf:"fixed64,3,rep,packed,name=normals,proto3" json:"normals,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
 	XXX_unrecognized     []byte       `json:"-"`
 	XXX_sizecache        int32        `json:"-"`
@@ -584,6 +707,13 @@ func (m *Mesh) GetFaces() []*Mesh_Face {
 	return nil
 }
 
+func (m *Mesh) GetNormals() []float64 {
+	if m != nil {
+		return m.Normals
+	}
+	return nil
+}
+
 type Mesh_Face struct {
 	A                    uint64    `protobuf:"varint,1,opt,name=a,proto3" json:"a,omitempty"`
 	B                    uint64    `protobuf:"varint,2,opt,name=b,proto3" json:"b,omitempty"`
@@ -647,25 +777,144 @@ func (m *Mesh_Face) GetUvs() []float64 {
 	return nil
 }
 
+type PackedMesh struct {
+	Encoding             PackedMesh_Encoding `protobuf:"varint,1,opt,name=encoding,proto3,enum=pb.PackedMesh_Encoding" json:"encoding,omitempty"`
+	VertexCount          uint32              `protobuf:"varint,2,opt,name=vertexCount,proto3" json:"vertexCount,omitempty"`
+	Positions            []byte              `protobuf:"bytes,3,opt,name=positions,proto3" json:"positions,omitempty"`
+	Min                  []float32           `protobuf:"fixed32,4,rep,packed,name=min,proto3" json:"min,omitempty"`
+	Max                  []float32           `protobuf:"fixed32,5,rep,packed,name=max,proto3" json:"max,omitempty"`
+	Normals              []byte              `protobuf:"bytes,6,opt,name=normals,proto3" json:"normals,omitempty"`
+	Indices              []byte              `protobuf:"bytes,7,opt,name=indices,proto3" json:"indices,omitempty"`
+	Uvs                  []byte              `protobuf:"bytes,8,opt,name=uvs,proto3" json:"uvs,omitempty"`
+	UvMin                []float32           `protobuf:"fixed32,9,rep,packed,name=uvMin,proto3" json:"uvMin,omitempty"`
+	UvMax                []float32           `protobuf:"fixed32,10,rep,packed,name=uvMax,proto3" json:"uvMax,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *PackedMesh) Reset()         { *m = PackedMesh{} }
+func (m *PackedMesh) String() string { return proto.CompactTextString(m) }
+func (*PackedMesh) ProtoMessage()    {}
+func (*PackedMesh) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f80abaa17e25ccc8, []int{5}
+}
+
+func (m *PackedMesh) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PackedMesh.Unmarshal(m, b)
+}
+func (m *PackedMesh) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PackedMesh.Marshal(b, m, deterministic)
+}
+func (m *PackedMesh) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PackedMesh.Merge(m, src)
+}
+func (m *PackedMesh) XXX_Size() int {
+	return xxx_messageInfo_PackedMesh.Size(m)
+}
+func (m *PackedMesh) XXX_DiscardUnknown() {
+	xxx_messageInfo_PackedMesh.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PackedMesh proto.InternalMessageInfo
+
+func (m *PackedMesh) GetEncoding() PackedMesh_Encoding {
+	if m != nil {
+		return m.Encoding
+	}
+	return PackedMesh_PROTO
+}
+
+func (m *PackedMesh) GetVertexCount() uint32 {
+	if m != nil {
+		return m.VertexCount
+	}
+	return 0
+}
+
+func (m *PackedMesh) GetPositions() []byte {
+	if m != nil {
+		return m.Positions
+	}
+	return nil
+}
+
+func (m *PackedMesh) GetMin() []float32 {
+	if m != nil {
+		return m.Min
+	}
+	return nil
+}
+
+func (m *PackedMesh) GetMax() []float32 {
+	if m != nil {
+		return m.Max
+	}
+	return nil
+}
+
+func (m *PackedMesh) GetNormals() []byte {
+	if m != nil {
+		return m.Normals
+	}
+	return nil
+}
+
+func (m *PackedMesh) GetIndices() []byte {
+	if m != nil {
+		return m.Indices
+	}
+	return nil
+}
+
+func (m *PackedMesh) GetUvs() []byte {
+	if m != nil {
+		return m.Uvs
+	}
+	return nil
+}
+
+func (m *PackedMesh) GetUvMin() []float32 {
+	if m != nil {
+		return m.UvMin
+	}
+	return nil
+}
+
+func (m *PackedMesh) GetUvMax() []float32 {
+	if m != nil {
+		return m.UvMax
+	}
+	return nil
+}
+
 type Response struct {
-	Type                 Response_Type `protobuf:"varint,1,opt,name=type,proto3,enum=pb.Response_Type" json:"type,omitempty"`
-	Id                   uint64        `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
-	Texture              *Texture      `protobuf:"bytes,3,opt,name=texture,proto3" json:"texture,omitempty"`
-	Parts                uint64        `protobuf:"varint,4,opt,name=parts,proto3" json:"parts,omitempty"`
-	Part                 uint64        `protobuf:"varint,5,opt,name=part,proto3" json:"part,omitempty"`
-	Chunk                *Chunk        `protobuf:"bytes,6,opt,name=chunk,proto3" json:"chunk,omitempty"`
-	Material             *Material     `protobuf:"bytes,7,opt,name=material,proto3" json:"material,omitempty"`
-	MeshData             []byte        `protobuf:"bytes,8,opt,name=meshData,proto3" json:"meshData,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
-	XXX_unrecognized     []byte        `json:"-"`
-	XXX_sizecache        int32         `json:"-"`
+	Type                 Response_Type       `protobuf:"varint,1,opt,name=type,proto3,enum=pb.Response_Type" json:"type,omitempty"`
+	Id                   uint64              `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Texture              *Texture            `protobuf:"bytes,3,opt,name=texture,proto3" json:"texture,omitempty"`
+	Parts                uint64              `protobuf:"varint,4,opt,name=parts,proto3" json:"parts,omitempty"`
+	Part                 uint64              `protobuf:"varint,5,opt,name=part,proto3" json:"part,omitempty"`
+	Chunk                *Chunk              `protobuf:"bytes,6,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	Material             *Material           `protobuf:"bytes,7,opt,name=material,proto3" json:"material,omitempty"`
+	MeshData             []byte              `protobuf:"bytes,8,opt,name=meshData,proto3" json:"meshData,omitempty"`
+	AssetType            Request_Type        `protobuf:"varint,9,opt,name=assetType,proto3,enum=pb.Request_Type" json:"assetType,omitempty"`
+	Hash                 string              `protobuf:"bytes,10,opt,name=hash,proto3" json:"hash,omitempty"`
+	NotModified          bool                `protobuf:"varint,11,opt,name=notModified,proto3" json:"notModified,omitempty"`
+	Lod                  uint32              `protobuf:"varint,12,opt,name=lod,proto3" json:"lod,omitempty"`
+	Encoding             PackedMesh_Encoding `protobuf:"varint,13,opt,name=encoding,proto3,enum=pb.PackedMesh_Encoding" json:"encoding,omitempty"`
+	Deflate              bool                `protobuf:"varint,14,opt,name=deflate,proto3" json:"deflate,omitempty"`
+	Levels               uint32              `protobuf:"varint,15,opt,name=levels,proto3" json:"levels,omitempty"`
+	Player               *PlayerRecord       `protobuf:"bytes,16,opt,name=player,proto3" json:"player,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
 }
 
 func (m *Response) Reset()         { *m = Response{} }
 func (m *Response) String() string { return proto.CompactTextString(m) }
 func (*Response) ProtoMessage()    {}
 func (*Response) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f80abaa17e25ccc8, []int{5}
+	return fileDescriptor_f80abaa17e25ccc8, []int{6}
 }
 
 func (m *Response) XXX_Unmarshal(b []byte) error {
@@ -742,6 +991,62 @@ func (m *Response) GetMeshData() []byte {
 	return nil
 }
 
+func (m *Response) GetAssetType() Request_Type {
+	if m != nil {
+		return m.AssetType
+	}
+	return Request_TEXTURE
+}
+
+func (m *Response) GetHash() string {
+	if m != nil {
+		return m.Hash
+	}
+	return ""
+}
+
+func (m *Response) GetNotModified() bool {
+	if m != nil {
+		return m.NotModified
+	}
+	return false
+}
+
+func (m *Response) GetLod() uint32 {
+	if m != nil {
+		return m.Lod
+	}
+	return 0
+}
+
+func (m *Response) GetEncoding() PackedMesh_Encoding {
+	if m != nil {
+		return m.Encoding
+	}
+	return PackedMesh_PROTO
+}
+
+func (m *Response) GetDeflate() bool {
+	if m != nil {
+		return m.Deflate
+	}
+	return false
+}
+
+func (m *Response) GetLevels() uint32 {
+	if m != nil {
+		return m.Levels
+	}
+	return 0
+}
+
+func (m *Response) GetPlayer() *PlayerRecord {
+	if m != nil {
+		return m.Player
+	}
+	return nil
+}
+
 type Light struct {
 	Type                 Light_Type        `protobuf:"varint,1,opt,name=type,proto3,enum=pb.Light_Type" json:"type,omitempty"`
 	Color                string            `protobuf:"bytes,2,opt,name=color,proto3" json:"color,omitempty"`
@@ -764,7 +1069,7 @@ func (m *Light) Reset()         { *m = Light{} }
 func (m *Light) String() string { return proto.CompactTextString(m) }
 func (*Light) ProtoMessage()    {}
 func (*Light) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f80abaa17e25ccc8, []int{6}
+	return fileDescriptor_f80abaa17e25ccc8, []int{7}
 }
 
 func (m *Light) XXX_Unmarshal(b []byte) error {
@@ -886,7 +1191,7 @@ func (m *Entity) Reset()         { *m = Entity{} }
 func (m *Entity) String() string { return proto.CompactTextString(m) }
 func (*Entity) ProtoMessage()    {}
 func (*Entity) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f80abaa17e25ccc8, []int{7}
+	return fileDescriptor_f80abaa17e25ccc8, []int{8}
 }
 
 func (m *Entity) XXX_Unmarshal(b []byte) error {
@@ -968,7 +1273,7 @@ func (m *Chunk) Reset()         { *m = Chunk{} }
 func (m *Chunk) String() string { return proto.CompactTextString(m) }
 func (*Chunk) ProtoMessage()    {}
 func (*Chunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f80abaa17e25ccc8, []int{8}
+	return fileDescriptor_f80abaa17e25ccc8, []int{9}
 }
 
 func (m *Chunk) XXX_Unmarshal(b []byte) error {
@@ -1015,7 +1320,7 @@ func (m *EntityID) Reset()         { *m = EntityID{} }
 func (m *EntityID) String() string { return proto.CompactTextString(m) }
 func (*EntityID) ProtoMessage()    {}
 func (*EntityID) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f80abaa17e25ccc8, []int{9}
+	return fileDescriptor_f80abaa17e25ccc8, []int{10}
 }
 
 func (m *EntityID) XXX_Unmarshal(b []byte) error {
@@ -1063,7 +1368,7 @@ func (m *RelativeLocation) Reset()         { *m = RelativeLocation{} }
 func (m *RelativeLocation) String() string { return proto.CompactTextString(m) }
 func (*RelativeLocation) ProtoMessage()    {}
 func (*RelativeLocation) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f80abaa17e25ccc8, []int{10}
+	return fileDescriptor_f80abaa17e25ccc8, []int{11}
 }
 
 func (m *RelativeLocation) XXX_Unmarshal(b []byte) error {
@@ -1119,7 +1424,7 @@ func (m *Rotation) Reset()         { *m = Rotation{} }
 func (m *Rotation) String() string { return proto.CompactTextString(m) }
 func (*Rotation) ProtoMessage()    {}
 func (*Rotation) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f80abaa17e25ccc8, []int{11}
+	return fileDescriptor_f80abaa17e25ccc8, []int{12}
 }
 
 func (m *Rotation) XXX_Unmarshal(b []byte) error {
@@ -1181,7 +1486,7 @@ func (m *Velocity) Reset()         { *m = Velocity{} }
 func (m *Velocity) String() string { return proto.CompactTextString(m) }
 func (*Velocity) ProtoMessage()    {}
 func (*Velocity) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f80abaa17e25ccc8, []int{12}
+	return fileDescriptor_f80abaa17e25ccc8, []int{13}
 }
 
 func (m *Velocity) XXX_Unmarshal(b []byte) error {
@@ -1236,7 +1541,7 @@ func (m *AbsoluteLocation) Reset()         { *m = AbsoluteLocation{} }
 func (m *AbsoluteLocation) String() string { return proto.CompactTextString(m) }
 func (*AbsoluteLocation) ProtoMessage()    {}
 func (*AbsoluteLocation) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f80abaa17e25ccc8, []int{13}
+	return fileDescriptor_f80abaa17e25ccc8, []int{14}
 }
 
 func (m *AbsoluteLocation) XXX_Unmarshal(b []byte) error {
@@ -1290,7 +1595,7 @@ func (m *RelativeAbsoluteLocation) Reset()         { *m = RelativeAbsoluteLocati
 func (m *RelativeAbsoluteLocation) String() string { return proto.CompactTextString(m) }
 func (*RelativeAbsoluteLocation) ProtoMessage()    {}
 func (*RelativeAbsoluteLocation) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f80abaa17e25ccc8, []int{14}
+	return fileDescriptor_f80abaa17e25ccc8, []int{15}
 }
 
 func (m *RelativeAbsoluteLocation) XXX_Unmarshal(b []byte) error {
@@ -1341,7 +1646,7 @@ func (m *Update) Reset()         { *m = Update{} }
 func (m *Update) String() string { return proto.CompactTextString(m) }
 func (*Update) ProtoMessage()    {}
 func (*Update) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f80abaa17e25ccc8, []int{15}
+	return fileDescriptor_f80abaa17e25ccc8, []int{16}
 }
 
 func (m *Update) XXX_Unmarshal(b []byte) error {
@@ -1404,20 +1709,432 @@ func (m *Update) GetRotationalVelocity() *Velocity {
 	return nil
 }
 
+type VoxelEdit struct {
+	Mode                 VoxelEdit_Mode    `protobuf:"varint,1,opt,name=mode,proto3,enum=pb.VoxelEdit_Mode" json:"mode,omitempty"`
+	Chunk                *AbsoluteLocation `protobuf:"bytes,2,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	Position             *RelativeLocation `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`
+	Radius               float64           `protobuf:"fixed64,4,opt,name=radius,proto3" json:"radius,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *VoxelEdit) Reset()         { *m = VoxelEdit{} }
+func (m *VoxelEdit) String() string { return proto.CompactTextString(m) }
+func (*VoxelEdit) ProtoMessage()    {}
+func (*VoxelEdit) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f80abaa17e25ccc8, []int{17}
+}
+
+func (m *VoxelEdit) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VoxelEdit.Unmarshal(m, b)
+}
+func (m *VoxelEdit) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VoxelEdit.Marshal(b, m, deterministic)
+}
+func (m *VoxelEdit) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VoxelEdit.Merge(m, src)
+}
+func (m *VoxelEdit) XXX_Size() int {
+	return xxx_messageInfo_VoxelEdit.Size(m)
+}
+func (m *VoxelEdit) XXX_DiscardUnknown() {
+	xxx_messageInfo_VoxelEdit.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VoxelEdit proto.InternalMessageInfo
+
+func (m *VoxelEdit) GetMode() VoxelEdit_Mode {
+	if m != nil {
+		return m.Mode
+	}
+	return VoxelEdit_DIG
+}
+
+func (m *VoxelEdit) GetChunk() *AbsoluteLocation {
+	if m != nil {
+		return m.Chunk
+	}
+	return nil
+}
+
+func (m *VoxelEdit) GetPosition() *RelativeLocation {
+	if m != nil {
+		return m.Position
+	}
+	return nil
+}
+
+func (m *VoxelEdit) GetRadius() float64 {
+	if m != nil {
+		return m.Radius
+	}
+	return 0
+}
+
+type TerrainEdits struct {
+	Edits                []*VoxelEdit `protobuf:"bytes,1,rep,name=edits,proto3" json:"edits,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *TerrainEdits) Reset()         { *m = TerrainEdits{} }
+func (m *TerrainEdits) String() string { return proto.CompactTextString(m) }
+func (*TerrainEdits) ProtoMessage()    {}
+func (*TerrainEdits) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f80abaa17e25ccc8, []int{18}
+}
+
+func (m *TerrainEdits) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TerrainEdits.Unmarshal(m, b)
+}
+func (m *TerrainEdits) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TerrainEdits.Marshal(b, m, deterministic)
+}
+func (m *TerrainEdits) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TerrainEdits.Merge(m, src)
+}
+func (m *TerrainEdits) XXX_Size() int {
+	return xxx_messageInfo_TerrainEdits.Size(m)
+}
+func (m *TerrainEdits) XXX_DiscardUnknown() {
+	xxx_messageInfo_TerrainEdits.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TerrainEdits proto.InternalMessageInfo
+
+func (m *TerrainEdits) GetEdits() []*VoxelEdit {
+	if m != nil {
+		return m.Edits
+	}
+	return nil
+}
+
+type StoredChunk struct {
+	Version              uint32          `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Seed                 int64           `protobuf:"varint,2,opt,name=seed,proto3" json:"seed,omitempty"`
+	Terrain              *Mesh           `protobuf:"bytes,3,opt,name=terrain,proto3" json:"terrain,omitempty"`
+	Levels               []*Mesh         `protobuf:"bytes,4,rep,name=levels,proto3" json:"levels,omitempty"`
+	Prefabs              []*StoredPrefab `protobuf:"bytes,5,rep,name=prefabs,proto3" json:"prefabs,omitempty"`
+	ChunkSize            float64         `protobuf:"fixed64,6,opt,name=chunkSize,proto3" json:"chunkSize,omitempty"`
+	Resolution           uint32          `protobuf:"varint,7,opt,name=resolution,proto3" json:"resolution,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *StoredChunk) Reset()         { *m = StoredChunk{} }
+func (m *StoredChunk) String() string { return proto.CompactTextString(m) }
+func (*StoredChunk) ProtoMessage()    {}
+func (*StoredChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f80abaa17e25ccc8, []int{19}
+}
+
+func (m *StoredChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StoredChunk.Unmarshal(m, b)
+}
+func (m *StoredChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StoredChunk.Marshal(b, m, deterministic)
+}
+func (m *StoredChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StoredChunk.Merge(m, src)
+}
+func (m *StoredChunk) XXX_Size() int {
+	return xxx_messageInfo_StoredChunk.Size(m)
+}
+func (m *StoredChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_StoredChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StoredChunk proto.InternalMessageInfo
+
+func (m *StoredChunk) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *StoredChunk) GetSeed() int64 {
+	if m != nil {
+		return m.Seed
+	}
+	return 0
+}
+
+func (m *StoredChunk) GetTerrain() *Mesh {
+	if m != nil {
+		return m.Terrain
+	}
+	return nil
+}
+
+func (m *StoredChunk) GetLevels() []*Mesh {
+	if m != nil {
+		return m.Levels
+	}
+	return nil
+}
+
+func (m *StoredChunk) GetPrefabs() []*StoredPrefab {
+	if m != nil {
+		return m.Prefabs
+	}
+	return nil
+}
+
+func (m *StoredChunk) GetChunkSize() float64 {
+	if m != nil {
+		return m.ChunkSize
+	}
+	return 0
+}
+
+func (m *StoredChunk) GetResolution() uint32 {
+	if m != nil {
+		return m.Resolution
+	}
+	return 0
+}
+
+type StoredPrefab struct {
+	Kind                 string            `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Position             *RelativeLocation `protobuf:"bytes,2,opt,name=position,proto3" json:"position,omitempty"`
+	Yaw                  float64           `protobuf:"fixed64,3,opt,name=yaw,proto3" json:"yaw,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *StoredPrefab) Reset()         { *m = StoredPrefab{} }
+func (m *StoredPrefab) String() string { return proto.CompactTextString(m) }
+func (*StoredPrefab) ProtoMessage()    {}
+func (*StoredPrefab) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f80abaa17e25ccc8, []int{20}
+}
+
+func (m *StoredPrefab) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StoredPrefab.Unmarshal(m, b)
+}
+func (m *StoredPrefab) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StoredPrefab.Marshal(b, m, deterministic)
+}
+func (m *StoredPrefab) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StoredPrefab.Merge(m, src)
+}
+func (m *StoredPrefab) XXX_Size() int {
+	return xxx_messageInfo_StoredPrefab.Size(m)
+}
+func (m *StoredPrefab) XXX_DiscardUnknown() {
+	xxx_messageInfo_StoredPrefab.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StoredPrefab proto.InternalMessageInfo
+
+func (m *StoredPrefab) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *StoredPrefab) GetPosition() *RelativeLocation {
+	if m != nil {
+		return m.Position
+	}
+	return nil
+}
+
+func (m *StoredPrefab) GetYaw() float64 {
+	if m != nil {
+		return m.Yaw
+	}
+	return 0
+}
+
+type PlayerRecord struct {
+	Id                   string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Chunk                *AbsoluteLocation `protobuf:"bytes,2,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	Position             *RelativeLocation `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`
+	Rotation             *Rotation         `protobuf:"bytes,4,opt,name=rotation,proto3" json:"rotation,omitempty"`
+	Settings             []*Setting        `protobuf:"bytes,5,rep,name=settings,proto3" json:"settings,omitempty"`
+	State                *PlayerState      `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *PlayerRecord) Reset()         { *m = PlayerRecord{} }
+func (m *PlayerRecord) String() string { return proto.CompactTextString(m) }
+func (*PlayerRecord) ProtoMessage()    {}
+func (*PlayerRecord) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f80abaa17e25ccc8, []int{21}
+}
+
+func (m *PlayerRecord) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PlayerRecord.Unmarshal(m, b)
+}
+func (m *PlayerRecord) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PlayerRecord.Marshal(b, m, deterministic)
+}
+func (m *PlayerRecord) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PlayerRecord.Merge(m, src)
+}
+func (m *PlayerRecord) XXX_Size() int {
+	return xxx_messageInfo_PlayerRecord.Size(m)
+}
+func (m *PlayerRecord) XXX_DiscardUnknown() {
+	xxx_messageInfo_PlayerRecord.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PlayerRecord proto.InternalMessageInfo
+
+func (m *PlayerRecord) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *PlayerRecord) GetChunk() *AbsoluteLocation {
+	if m != nil {
+		return m.Chunk
+	}
+	return nil
+}
+
+func (m *PlayerRecord) GetPosition() *RelativeLocation {
+	if m != nil {
+		return m.Position
+	}
+	return nil
+}
+
+func (m *PlayerRecord) GetRotation() *Rotation {
+	if m != nil {
+		return m.Rotation
+	}
+	return nil
+}
+
+func (m *PlayerRecord) GetSettings() []*Setting {
+	if m != nil {
+		return m.Settings
+	}
+	return nil
+}
+
+func (m *PlayerRecord) GetState() *PlayerState {
+	if m != nil {
+		return m.State
+	}
+	return nil
+}
+
+type PlayerState struct {
+	LastEdit             int64    `protobuf:"varint,1,opt,name=lastEdit,proto3" json:"lastEdit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PlayerState) Reset()         { *m = PlayerState{} }
+func (m *PlayerState) String() string { return proto.CompactTextString(m) }
+func (*PlayerState) ProtoMessage()    {}
+func (*PlayerState) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f80abaa17e25ccc8, []int{22}
+}
+
+func (m *PlayerState) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PlayerState.Unmarshal(m, b)
+}
+func (m *PlayerState) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PlayerState.Marshal(b, m, deterministic)
+}
+func (m *PlayerState) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PlayerState.Merge(m, src)
+}
+func (m *PlayerState) XXX_Size() int {
+	return xxx_messageInfo_PlayerState.Size(m)
+}
+func (m *PlayerState) XXX_DiscardUnknown() {
+	xxx_messageInfo_PlayerState.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PlayerState proto.InternalMessageInfo
+
+func (m *PlayerState) GetLastEdit() int64 {
+	if m != nil {
+		return m.LastEdit
+	}
+	return 0
+}
+
+type Setting struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Setting) Reset()         { *m = Setting{} }
+func (m *Setting) String() string { return proto.CompactTextString(m) }
+func (*Setting) ProtoMessage()    {}
+func (*Setting) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f80abaa17e25ccc8, []int{23}
+}
+
+func (m *Setting) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Setting.Unmarshal(m, b)
+}
+func (m *Setting) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Setting.Marshal(b, m, deterministic)
+}
+func (m *Setting) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Setting.Merge(m, src)
+}
+func (m *Setting) XXX_Size() int {
+	return xxx_messageInfo_Setting.Size(m)
+}
+func (m *Setting) XXX_DiscardUnknown() {
+	xxx_messageInfo_Setting.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Setting proto.InternalMessageInfo
+
+func (m *Setting) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Setting) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterEnum("pb.Material_Type", Material_Type_name, Material_Type_value)
 	proto.RegisterEnum("pb.Material_Side", Material_Side_name, Material_Side_value)
 	proto.RegisterEnum("pb.Body_Type", Body_Type_name, Body_Type_value)
 	proto.RegisterEnum("pb.Request_Type", Request_Type_name, Request_Type_value)
+	proto.RegisterEnum("pb.PackedMesh_Encoding", PackedMesh_Encoding_name, PackedMesh_Encoding_value)
 	proto.RegisterEnum("pb.Response_Type", Response_Type_name, Response_Type_value)
 	proto.RegisterEnum("pb.Light_Type", Light_Type_name, Light_Type_value)
 	proto.RegisterEnum("pb.Update_Type", Update_Type_name, Update_Type_value)
+	proto.RegisterEnum("pb.VoxelEdit_Mode", VoxelEdit_Mode_name, VoxelEdit_Mode_value)
 	proto.RegisterType((*Material)(nil), "pb.Material")
 	proto.RegisterType((*Body)(nil), "pb.Body")
 	proto.RegisterType((*Texture)(nil), "pb.Texture")
 	proto.RegisterType((*Request)(nil), "pb.Request")
 	proto.RegisterType((*Mesh)(nil), "pb.Mesh")
 	proto.RegisterType((*Mesh_Face)(nil), "pb.Mesh.Face")
+	proto.RegisterType((*PackedMesh)(nil), "pb.PackedMesh")
 	proto.RegisterType((*Response)(nil), "pb.Response")
 	proto.RegisterType((*Light)(nil), "pb.Light")
 	proto.RegisterType((*Entity)(nil), "pb.Entity")
@@ -1429,92 +2146,143 @@ func init() {
 	proto.RegisterType((*AbsoluteLocation)(nil), "pb.AbsoluteLocation")
 	proto.RegisterType((*RelativeAbsoluteLocation)(nil), "pb.RelativeAbsoluteLocation")
 	proto.RegisterType((*Update)(nil), "pb.Update")
+	proto.RegisterType((*VoxelEdit)(nil), "pb.VoxelEdit")
+	proto.RegisterType((*TerrainEdits)(nil), "pb.TerrainEdits")
+	proto.RegisterType((*StoredChunk)(nil), "pb.StoredChunk")
+	proto.RegisterType((*StoredPrefab)(nil), "pb.StoredPrefab")
+	proto.RegisterType((*PlayerRecord)(nil), "pb.PlayerRecord")
+	proto.RegisterType((*PlayerState)(nil), "pb.PlayerState")
+	proto.RegisterType((*Setting)(nil), "pb.Setting")
 }
 
 func init() { proto.RegisterFile("pb.proto", fileDescriptor_f80abaa17e25ccc8) }
 
 var fileDescriptor_f80abaa17e25ccc8 = []byte{
-	// 1300 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0xcb, 0x6e, 0xdb, 0x46,
-	0x14, 0xf5, 0x90, 0x94, 0x44, 0x5d, 0xf9, 0xc1, 0x4c, 0xd3, 0x82, 0x08, 0xd2, 0x56, 0x61, 0x1e,
-	0xd0, 0xa2, 0x30, 0x02, 0xb7, 0xe8, 0x2a, 0x1b, 0x4a, 0x62, 0x22, 0x22, 0x7a, 0x18, 0x23, 0xb9,
-	0xc8, 0xce, 0x18, 0x89, 0x63, 0x8b, 0xa8, 0x24, 0xaa, 0x24, 0x65, 0x47, 0x46, 0xd1, 0x1f, 0xe8,
-	0xae, 0x7f, 0xd0, 0x5d, 0x3f, 0xa2, 0x7f, 0xd5, 0x5d, 0x57, 0xc5, 0x1d, 0x0e, 0x29, 0xc9, 0x79,
-	0xd4, 0xc9, 0x4e, 0xe7, 0x9e, 0xb9, 0x33, 0x9c, 0x73, 0xee, 0xbd, 0x23, 0x30, 0x97, 0xe3, 0xe3,
-	0x65, 0x1c, 0xa5, 0x11, 0xd5, 0x96, 0x63, 0xe7, 0x5f, 0x1d, 0xcc, 0x1e, 0x4f, 0x45, 0x1c, 0xf2,
-	0x19, 0xbd, 0x0f, 0xa5, 0x49, 0x34, 0x8b, 0x62, 0xbb, 0x54, 0x27, 0x8d, 0x2a, 0xcb, 0x00, 0x7d,
-	0x00, 0xa6, 0x98, 0x87, 0x49, 0x12, 0x5e, 0x09, 0xbb, 0x2c, 0x89, 0x02, 0xd3, 0x87, 0x50, 0x8d,
-	0xa3, 0xd5, 0xe5, 0x74, 0x21, 0x92, 0xc4, 0xae, 0xd4, 0x49, 0x43, 0x63, 0x9b, 0x00, 0xb2, 0x73,
-	0x91, 0xf2, 0x99, 0x64, 0xcd, 0x8c, 0x2d, 0x02, 0x78, 0x5a, 0x32, 0xe1, 0x33, 0x61, 0x57, 0x25,
-	0x93, 0x01, 0x3c, 0x2d, 0xe0, 0xc9, 0x74, 0x18, 0xde, 0x08, 0xbb, 0x26, 0x89, 0x02, 0x53, 0x1b,
-	0x2a, 0x97, 0x7c, 0x29, 0xa9, 0x7d, 0x49, 0xe5, 0x10, 0x4f, 0x4a, 0xc5, 0xdb, 0x74, 0x15, 0x0b,
-	0xbf, 0x6d, 0x93, 0x3a, 0x69, 0x18, 0x6c, 0x13, 0xa0, 0x4f, 0xc1, 0x48, 0xd7, 0x4b, 0x61, 0x6b,
-	0x75, 0xd2, 0x38, 0x3c, 0xb9, 0x77, 0xbc, 0x1c, 0x1f, 0xe7, 0x77, 0x3e, 0x1e, 0xad, 0x97, 0x82,
-	0x49, 0x1a, 0x37, 0xb9, 0x0e, 0x63, 0x71, 0x11, 0xf3, 0xb9, 0xb0, 0xf5, 0x3a, 0x69, 0x98, 0x6c,
-	0x13, 0xa0, 0xdf, 0x00, 0x5c, 0xcc, 0x78, 0x3a, 0x9c, 0xf2, 0x40, 0x04, 0x36, 0x48, 0x7a, 0x2b,
-	0x82, 0x87, 0x24, 0x61, 0x20, 0x6c, 0xe3, 0x3d, 0x87, 0x0c, 0xc3, 0x40, 0x30, 0x49, 0x3b, 0x0c,
-	0x0c, 0x3c, 0x92, 0xd6, 0xa0, 0xd2, 0x75, 0x7b, 0x4d, 0x8f, 0x8d, 0xac, 0x3d, 0x5a, 0x85, 0x52,
-	0xd3, 0x1d, 0xfa, 0x2d, 0x8b, 0xe0, 0xcf, 0xd3, 0xce, 0xa0, 0xff, 0xca, 0xd2, 0xe8, 0x3e, 0x98,
-	0xc3, 0x91, 0xdb, 0x6f, 0xbb, 0xac, 0x6d, 0xe9, 0xd4, 0x04, 0xa3, 0xeb, 0xf7, 0x3d, 0xcb, 0xa0,
-	0x47, 0x50, 0x6b, 0xbb, 0xc3, 0x8e, 0xd7, 0x3e, 0x97, 0x81, 0x92, 0xf3, 0x23, 0x18, 0x78, 0x02,
-	0x3d, 0x04, 0x78, 0xc9, 0x06, 0xfd, 0xd1, 0xf9, 0xd0, 0x6f, 0x7b, 0xd6, 0x1e, 0x3d, 0x80, 0x6a,
-	0xd3, 0x6d, 0xbd, 0xce, 0x20, 0x91, 0x79, 0x83, 0xb3, 0x66, 0xd7, 0xcb, 0x02, 0x9a, 0xf3, 0x87,
-	0x06, 0x46, 0x33, 0x0a, 0xd6, 0x94, 0x82, 0x11, 0xf0, 0x94, 0xdb, 0xa4, 0xae, 0x37, 0x08, 0x93,
-	0xbf, 0xd1, 0x88, 0xb9, 0xfa, 0x7e, 0x29, 0x9c, 0xc1, 0x0a, 0x4c, 0x1f, 0x29, 0x41, 0x75, 0x79,
-	0xd7, 0x03, 0xbc, 0x2b, 0xee, 0xb3, 0x2d, 0xe6, 0x77, 0x50, 0x8e, 0x2e, 0x2e, 0x12, 0x91, 0x4a,
-	0x41, 0x6a, 0x27, 0xf7, 0x71, 0x11, 0x13, 0x33, 0x9e, 0x86, 0x57, 0xa2, 0x1b, 0x4d, 0x78, 0x1a,
-	0x46, 0x0b, 0xa6, 0xd6, 0xd0, 0x06, 0x98, 0x71, 0x94, 0xca, 0x98, 0x2c, 0xbe, 0xda, 0xc9, 0xbe,
-	0x5c, 0xaf, 0x62, 0xac, 0x60, 0x69, 0x1d, 0x6a, 0x28, 0x7a, 0x3f, 0x8a, 0xe7, 0x7c, 0x96, 0xd5,
-	0x9c, 0xc9, 0xb6, 0x43, 0xf4, 0x2b, 0x28, 0xcf, 0x45, 0x32, 0xf5, 0xdb, 0xb2, 0xe4, 0x0c, 0xa6,
-	0x90, 0xf3, 0x54, 0x29, 0x6f, 0x82, 0xd1, 0xf3, 0x86, 0x1d, 0x6b, 0x8f, 0x56, 0x40, 0x6f, 0x0e,
-	0xde, 0x58, 0x84, 0x02, 0x94, 0x87, 0xa7, 0x1d, 0x8f, 0xa1, 0x28, 0x5f, 0x43, 0x65, 0x94, 0x55,
-	0xce, 0x96, 0x2c, 0xa4, 0xb1, 0x9f, 0xc9, 0xe2, 0x5c, 0x43, 0x85, 0x89, 0x5f, 0x56, 0x22, 0x49,
-	0xe9, 0x21, 0x68, 0x61, 0xa0, 0xb4, 0xd1, 0xc2, 0x80, 0x3e, 0x51, 0xaa, 0x10, 0xa9, 0x8a, 0x95,
-	0x5d, 0x58, 0x2e, 0xdd, 0x12, 0x06, 0xcd, 0xca, 0x0b, 0x60, 0xe4, 0xbd, 0x19, 0x9d, 0x31, 0x2f,
-	0x2b, 0x00, 0xf7, 0xac, 0xed, 0x0f, 0x2c, 0x82, 0xae, 0xf7, 0xdc, 0x91, 0xc7, 0x7c, 0xb7, 0x6b,
-	0x69, 0xc5, 0xc7, 0xea, 0xce, 0xef, 0x04, 0x8c, 0x9e, 0x48, 0xa6, 0x68, 0xcc, 0x95, 0x88, 0xd3,
-	0x70, 0x22, 0x12, 0x65, 0x58, 0x81, 0xe9, 0x63, 0x28, 0x5d, 0x70, 0x24, 0xb4, 0xba, 0xde, 0xa8,
-	0x65, 0xce, 0x60, 0xd2, 0xf1, 0x4b, 0x3e, 0x11, 0x2c, 0xe3, 0x1e, 0x34, 0xc1, 0x40, 0x48, 0xf7,
-	0x81, 0x70, 0xd5, 0x2c, 0x84, 0x23, 0x1a, 0xab, 0xcb, 0x90, 0x31, 0xa2, 0x89, 0xb4, 0xd7, 0x60,
-	0x64, 0x42, 0x2d, 0xd0, 0x57, 0x57, 0x89, 0x6d, 0xc8, 0xd3, 0xf0, 0xa7, 0xf3, 0xb7, 0x06, 0x26,
-	0x13, 0xc9, 0x32, 0x5a, 0x24, 0xa2, 0xe8, 0x2f, 0xb2, 0x29, 0xfd, 0x9c, 0xdb, 0x2e, 0x89, 0xdb,
-	0x7a, 0x3d, 0x85, 0x8a, 0xea, 0x51, 0x79, 0x52, 0xed, 0xa4, 0x86, 0x99, 0x4a, 0x7c, 0x96, 0x73,
-	0x38, 0x27, 0x96, 0x3c, 0x4e, 0x13, 0x59, 0x48, 0x06, 0xcb, 0x00, 0x7a, 0x83, 0x3f, 0x64, 0xb5,
-	0x18, 0x4c, 0xfe, 0xa6, 0xdf, 0x42, 0x69, 0x32, 0x5d, 0x2d, 0x7e, 0x96, 0x63, 0xaa, 0x76, 0x52,
-	0xc5, 0xed, 0x5a, 0x18, 0x60, 0x59, 0x1c, 0xcb, 0xac, 0xa8, 0xe9, 0xca, 0xa6, 0xcc, 0xf2, 0x3e,
-	0xdd, 0xaa, 0x70, 0xac, 0x7e, 0x91, 0x4c, 0xdb, 0x68, 0xbf, 0x29, 0xed, 0x2f, 0xb0, 0xe3, 0xfe,
-	0x8f, 0x83, 0x55, 0x28, 0xb5, 0x3a, 0x67, 0xfd, 0xd7, 0x59, 0x0b, 0x17, 0x66, 0xea, 0x85, 0x99,
-	0x86, 0xf3, 0x8f, 0x0e, 0xa5, 0x6e, 0x78, 0x39, 0x4d, 0xa9, 0xb3, 0xa3, 0xdd, 0x21, 0x7e, 0x8e,
-	0x24, 0xb6, 0x85, 0x2b, 0xe6, 0xb2, 0xb6, 0x3d, 0x97, 0x1f, 0x42, 0x35, 0x5c, 0xa4, 0x62, 0x91,
-	0x84, 0xe9, 0x5a, 0x0a, 0xa8, 0xb1, 0x4d, 0x80, 0x3e, 0x07, 0x73, 0x19, 0x25, 0xa1, 0xec, 0xa8,
-	0x8f, 0x75, 0x60, 0xb1, 0xea, 0x13, 0x7a, 0x10, 0x67, 0x74, 0x98, 0xa4, 0x7c, 0x31, 0xc9, 0x5e,
-	0x04, 0x9c, 0xd1, 0x0a, 0xe3, 0xb7, 0x06, 0x62, 0xc2, 0xd7, 0xea, 0x35, 0xc8, 0x00, 0x46, 0xf9,
-	0xe2, 0x72, 0x26, 0xd4, 0x2b, 0x90, 0x01, 0xdc, 0x67, 0x29, 0x16, 0xab, 0xf9, 0x38, 0xe6, 0xea,
-	0x11, 0x28, 0x30, 0x7d, 0x06, 0x87, 0x89, 0x98, 0x44, 0x8b, 0x80, 0xc7, 0xeb, 0x96, 0xbc, 0x3c,
-	0xc8, 0xcb, 0xdf, 0x8a, 0xe2, 0xce, 0xd7, 0x61, 0x90, 0x4e, 0xe5, 0x63, 0x71, 0xc0, 0x32, 0x80,
-	0x33, 0x60, 0x2a, 0x50, 0x46, 0xf9, 0x50, 0x1c, 0x30, 0x85, 0x9c, 0x5f, 0x95, 0x75, 0x47, 0x50,
-	0x3b, 0x1d, 0xf8, 0xfd, 0xd1, 0x79, 0xd7, 0x7f, 0xd5, 0xc1, 0x09, 0xfc, 0x05, 0x1c, 0x31, 0xaf,
-	0x35, 0x3a, 0x77, 0x99, 0xe7, 0xaa, 0x20, 0xc1, 0x79, 0x3a, 0x3c, 0x1d, 0xe4, 0x8b, 0x34, 0x7a,
-	0x0f, 0x0e, 0xdc, 0x5e, 0xd3, 0xf7, 0x8a, 0x3c, 0x9d, 0x7e, 0x09, 0xf7, 0xda, 0x3e, 0x66, 0xfa,
-	0x83, 0xbe, 0xdb, 0x55, 0x61, 0x83, 0xde, 0x07, 0xab, 0xe3, 0xf5, 0xfc, 0x6c, 0xa8, 0xa8, 0x68,
-	0xc9, 0xf9, 0x53, 0x83, 0xb2, 0xb7, 0x48, 0x95, 0x3d, 0x33, 0x65, 0x81, 0xb4, 0xfe, 0x83, 0xf6,
-	0xe4, 0xab, 0xde, 0xe9, 0x9e, 0x6d, 0xbb, 0xf4, 0x8f, 0xda, 0xd5, 0xc0, 0x81, 0x31, 0x8b, 0x26,
-	0x58, 0x27, 0xc6, 0x66, 0xe5, 0x4f, 0x2a, 0xc6, 0x0a, 0x96, 0xbe, 0x00, 0x9a, 0x67, 0xf1, 0x59,
-	0xce, 0x6f, 0x17, 0x43, 0x91, 0xf3, 0x9e, 0x75, 0xb4, 0x0e, 0xe5, 0x71, 0x14, 0x84, 0x22, 0xb1,
-	0xcb, 0x72, 0xfa, 0x98, 0xf9, 0xbb, 0xc0, 0x54, 0x9c, 0x3e, 0x82, 0xf2, 0x0c, 0x7d, 0xc0, 0xb9,
-	0xad, 0xe7, 0x1d, 0x2a, 0xcb, 0x9d, 0x29, 0xc2, 0xe1, 0x50, 0x92, 0x2d, 0xfb, 0x21, 0x85, 0xdc,
-	0x71, 0x12, 0xcd, 0x56, 0xe9, 0xfb, 0x14, 0x7a, 0x06, 0xa6, 0x40, 0x75, 0xc3, 0x62, 0xfe, 0x01,
-	0x66, 0x64, 0x8a, 0xb3, 0x82, 0x73, 0xba, 0x60, 0x66, 0x31, 0xbf, 0xfd, 0x19, 0xa7, 0xdc, 0xf2,
-	0xc1, 0x79, 0x01, 0xd6, 0x6d, 0xd7, 0x70, 0x7a, 0xbe, 0x95, 0xdb, 0x11, 0x46, 0xde, 0x22, 0x5a,
-	0xcb, 0x04, 0xc2, 0xc8, 0x1a, 0xd1, 0x8d, 0x34, 0x8c, 0x30, 0x72, 0xe3, 0x34, 0xc1, 0xcc, 0x1d,
-	0xdb, 0x64, 0x69, 0x3b, 0x59, 0xda, 0x4e, 0x96, 0xc6, 0xc8, 0x0d, 0xa2, 0x6b, 0x69, 0xa5, 0xc6,
-	0xc8, 0xb5, 0xf3, 0x03, 0x98, 0x85, 0x07, 0x77, 0xde, 0x03, 0xbf, 0xfb, 0xf6, 0x2d, 0x37, 0xd9,
-	0x74, 0x27, 0x9b, 0xee, 0x64, 0x53, 0xcc, 0xfe, 0x0d, 0xec, 0xfc, 0xd6, 0xef, 0xec, 0xf2, 0x1c,
-	0x4c, 0xae, 0x62, 0x1f, 0xd7, 0x34, 0x5f, 0x85, 0x19, 0xb1, 0xda, 0x4d, 0x1e, 0xf8, 0xc1, 0x6e,
-	0xc8, 0x57, 0x39, 0x7f, 0x69, 0x50, 0x3e, 0x5b, 0x06, 0x3c, 0x15, 0xf4, 0xf1, 0xce, 0x04, 0x3d,
-	0xc2, 0xc4, 0x8c, 0xd9, 0x1e, 0xa1, 0x4f, 0xa0, 0x2c, 0xfd, 0x5f, 0xab, 0xfd, 0xf7, 0x37, 0x95,
-	0xe1, 0xb7, 0x99, 0xe2, 0x76, 0x86, 0xa6, 0xfe, 0xc9, 0x43, 0xd3, 0xb8, 0x73, 0x17, 0x96, 0x3e,
-	0xa3, 0x0b, 0xcb, 0x77, 0xeb, 0x42, 0xe7, 0x48, 0x8d, 0xb8, 0x0a, 0xe8, 0xdd, 0x41, 0xcb, 0xda,
-	0x1b, 0x97, 0xe5, 0xbf, 0xfd, 0xef, 0xff, 0x0b, 0x00, 0x00, 0xff, 0xff, 0x6b, 0xcd, 0x94, 0xa6,
-	0xf9, 0x0b, 0x00, 0x00,
+	// 2005 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x58, 0x4d, 0x8f, 0xdb, 0xc8,
+	0xd1, 0x36, 0x3f, 0x24, 0x51, 0xa5, 0xf9, 0xa0, 0xfb, 0xf5, 0xeb, 0x10, 0xc6, 0x26, 0xd1, 0x72,
+	0xd7, 0x8e, 0xb2, 0x08, 0x06, 0xbb, 0xe3, 0x20, 0xa7, 0x3d, 0x44, 0x23, 0xd1, 0x1e, 0x62, 0x35,
+	0xd2, 0xa4, 0xa5, 0xd9, 0xec, 0xe6, 0x62, 0xf4, 0x88, 0x3d, 0x23, 0xc2, 0x1c, 0x52, 0x21, 0xa9,
+	0xf1, 0xc8, 0x08, 0xf2, 0x5f, 0x72, 0x08, 0x90, 0x4b, 0x7e, 0x46, 0x2e, 0x39, 0xe5, 0x7f, 0xe4,
+	0x96, 0x00, 0x39, 0xe4, 0x14, 0x54, 0xb1, 0x49, 0x51, 0x63, 0xef, 0x64, 0xd6, 0x40, 0x6e, 0x5d,
+	0x55, 0x5d, 0xdd, 0x5d, 0x4f, 0x55, 0x3f, 0x5d, 0x24, 0x58, 0xcb, 0xf3, 0x83, 0x65, 0x9a, 0xe4,
+	0x09, 0xd3, 0x97, 0xe7, 0xee, 0xbf, 0x0d, 0xb0, 0x4e, 0x44, 0x2e, 0xd3, 0x50, 0x44, 0xec, 0x11,
+	0x34, 0xe6, 0x49, 0x94, 0xa4, 0x4e, 0xa3, 0xab, 0xf5, 0xda, 0xbc, 0x10, 0xd8, 0x13, 0xb0, 0xe4,
+	0x55, 0x98, 0x65, 0xe1, 0xb5, 0x74, 0x9a, 0x64, 0xa8, 0x64, 0xf6, 0x11, 0xb4, 0xd3, 0x64, 0x75,
+	0xb9, 0x88, 0x65, 0x96, 0x39, 0xad, 0xae, 0xd6, 0xd3, 0xf9, 0x46, 0x81, 0xd6, 0x2b, 0x99, 0x8b,
+	0x88, 0xac, 0x56, 0x61, 0xad, 0x14, 0xb8, 0x5b, 0x36, 0x17, 0x91, 0x74, 0xda, 0x64, 0x29, 0x04,
+	0xdc, 0x2d, 0x10, 0xd9, 0x62, 0x1a, 0xbe, 0x95, 0x4e, 0x87, 0x0c, 0x95, 0xcc, 0x1c, 0x68, 0x5d,
+	0x8a, 0x25, 0x99, 0x76, 0xc8, 0x54, 0x8a, 0xb8, 0x53, 0x2e, 0x6f, 0xf2, 0x55, 0x2a, 0xfd, 0xa1,
+	0xa3, 0x75, 0xb5, 0x9e, 0xc9, 0x37, 0x0a, 0xf6, 0x14, 0xcc, 0x7c, 0xbd, 0x94, 0x8e, 0xde, 0xd5,
+	0x7a, 0x7b, 0x87, 0x0f, 0x0f, 0x96, 0xe7, 0x07, 0x65, 0xcc, 0x07, 0xb3, 0xf5, 0x52, 0x72, 0x32,
+	0xe3, 0x22, 0x6f, 0xc2, 0x54, 0x5e, 0xa4, 0xe2, 0x4a, 0x3a, 0x46, 0x57, 0xeb, 0x59, 0x7c, 0xa3,
+	0x60, 0x3f, 0x02, 0xb8, 0x88, 0x44, 0x3e, 0x5d, 0x88, 0x40, 0x06, 0x0e, 0x90, 0xb9, 0xa6, 0xc1,
+	0x4d, 0xb2, 0x30, 0x90, 0x8e, 0xf9, 0x9e, 0x4d, 0xa6, 0x61, 0x20, 0x39, 0x99, 0x5d, 0x0e, 0x26,
+	0x6e, 0xc9, 0x3a, 0xd0, 0x1a, 0xf5, 0x4f, 0x8e, 0x3c, 0x3e, 0xb3, 0x1f, 0xb0, 0x36, 0x34, 0x8e,
+	0xfa, 0x53, 0x7f, 0x60, 0x6b, 0x38, 0x3c, 0x3d, 0x9e, 0x8c, 0x5f, 0xda, 0x3a, 0xdb, 0x01, 0x6b,
+	0x3a, 0xeb, 0x8f, 0x87, 0x7d, 0x3e, 0xb4, 0x0d, 0x66, 0x81, 0x39, 0xf2, 0xc7, 0x9e, 0x6d, 0xb2,
+	0x7d, 0xe8, 0x0c, 0xfb, 0xd3, 0x63, 0x6f, 0xf8, 0x8a, 0x14, 0x0d, 0xf7, 0x17, 0x60, 0xe2, 0x0e,
+	0x6c, 0x0f, 0xe0, 0x05, 0x9f, 0x8c, 0x67, 0xaf, 0xa6, 0xfe, 0xd0, 0xb3, 0x1f, 0xb0, 0x5d, 0x68,
+	0x1f, 0xf5, 0x07, 0x5f, 0x15, 0xa2, 0x46, 0x7e, 0x93, 0xb3, 0xa3, 0x91, 0x57, 0x28, 0x74, 0xf7,
+	0xcf, 0x3a, 0x98, 0x47, 0x49, 0xb0, 0x66, 0x0c, 0xcc, 0x40, 0xe4, 0xc2, 0xd1, 0xba, 0x46, 0x4f,
+	0xe3, 0x34, 0xc6, 0x44, 0x5c, 0xa9, 0xf3, 0x13, 0x70, 0x26, 0xaf, 0x64, 0xf6, 0xb1, 0x02, 0xd4,
+	0xa0, 0x58, 0x77, 0x31, 0x56, 0x5c, 0xa7, 0x0e, 0xe6, 0xcf, 0xa0, 0x99, 0x5c, 0x5c, 0x64, 0x32,
+	0x27, 0x40, 0x3a, 0x87, 0x8f, 0x70, 0x12, 0x97, 0x91, 0xc8, 0xc3, 0x6b, 0x39, 0x4a, 0xe6, 0x22,
+	0x0f, 0x93, 0x98, 0xab, 0x39, 0xac, 0x07, 0x56, 0x9a, 0xe4, 0xa4, 0xa3, 0xe2, 0xeb, 0x1c, 0xee,
+	0xd0, 0x7c, 0xa5, 0xe3, 0x95, 0x95, 0x75, 0xa1, 0x83, 0xa0, 0x8f, 0x93, 0xf4, 0x4a, 0x44, 0x45,
+	0xcd, 0x59, 0xbc, 0xae, 0x62, 0x8f, 0xa1, 0x79, 0x25, 0xb3, 0x85, 0x3f, 0xa4, 0x92, 0x33, 0xb9,
+	0x92, 0xdc, 0x5f, 0x2a, 0xe4, 0x2d, 0x30, 0x4f, 0xbc, 0xe9, 0xb1, 0xfd, 0x80, 0xb5, 0xc0, 0x38,
+	0x9a, 0x7c, 0x63, 0x6b, 0x0c, 0xa0, 0x39, 0x3d, 0x3d, 0xf6, 0xb8, 0x67, 0xeb, 0x98, 0x98, 0x41,
+	0xff, 0x74, 0x7a, 0x36, 0xf2, 0x6c, 0x03, 0x53, 0x30, 0xf8, 0x76, 0xe4, 0x8f, 0x87, 0x1e, 0xb7,
+	0x4d, 0xf7, 0x87, 0xd0, 0x9a, 0x15, 0x45, 0x55, 0x43, 0x4c, 0xeb, 0xed, 0x14, 0x88, 0xb9, 0x7f,
+	0xd3, 0xa1, 0xc5, 0xe5, 0x6f, 0x57, 0x32, 0xcb, 0xd9, 0x1e, 0xe8, 0x61, 0xa0, 0x70, 0xd3, 0xc3,
+	0x80, 0x7d, 0xaa, 0x10, 0xd3, 0x08, 0x31, 0xbb, 0x00, 0x83, 0xa6, 0xd6, 0x41, 0x63, 0x60, 0x2e,
+	0x44, 0xb6, 0x20, 0x5c, 0xdb, 0x9c, 0xc6, 0xcc, 0x06, 0x23, 0x4a, 0x02, 0x42, 0x71, 0x97, 0xe3,
+	0x90, 0x3d, 0x07, 0x4b, 0xc6, 0xf3, 0x24, 0x08, 0xe3, 0x4b, 0x02, 0x6b, 0xef, 0xf0, 0x07, 0xb8,
+	0xde, 0xa9, 0x98, 0xbf, 0x96, 0xc1, 0x89, 0xcc, 0x16, 0x07, 0x9e, 0x32, 0xf3, 0x6a, 0x22, 0xde,
+	0x9d, 0x40, 0x22, 0x4c, 0xc5, 0x25, 0xb6, 0x78, 0x29, 0x62, 0x32, 0x65, 0x10, 0xe6, 0x04, 0x65,
+	0xa7, 0x48, 0xe6, 0xd7, 0xc9, 0x8d, 0x8c, 0xbc, 0x20, 0xcc, 0x39, 0x99, 0xd8, 0x4f, 0xc0, 0xca,
+	0x64, 0x9e, 0x87, 0xf1, 0x25, 0xde, 0x63, 0xa3, 0xd7, 0x39, 0xec, 0xe0, 0xb4, 0x69, 0xa1, 0xe3,
+	0x95, 0xd1, 0x1d, 0x6f, 0xaa, 0x7b, 0xe6, 0x7d, 0x33, 0x3b, 0xe3, 0x5e, 0x51, 0xdd, 0xfd, 0xb3,
+	0xa1, 0x3f, 0xb1, 0x35, 0xc4, 0xf3, 0xa4, 0x3f, 0xf3, 0xb8, 0xdf, 0x1f, 0xd9, 0x7a, 0x95, 0x09,
+	0x2a, 0x6e, 0x6f, 0xe8, 0xcf, 0x6c, 0x93, 0x8a, 0xde, 0x9b, 0xcd, 0xfc, 0xf1, 0xcb, 0xa9, 0xdd,
+	0x70, 0xff, 0xa8, 0x81, 0x89, 0x11, 0x61, 0x35, 0x5e, 0xcb, 0x34, 0x0f, 0xe7, 0x32, 0x53, 0x55,
+	0x5a, 0xc9, 0xec, 0x13, 0x68, 0x5c, 0x08, 0x34, 0xe8, 0x5d, 0xa3, 0x8c, 0x80, 0x60, 0x78, 0x21,
+	0xe6, 0x92, 0x17, 0x36, 0x8c, 0x3f, 0x56, 0x35, 0x63, 0x90, 0x7f, 0x29, 0x3e, 0x39, 0x02, 0x13,
+	0x27, 0xb2, 0x1d, 0xd0, 0x84, 0xe2, 0x0e, 0x4d, 0xa0, 0x74, 0xae, 0xf2, 0xa7, 0x9d, 0xa3, 0x34,
+	0xa7, 0xac, 0x98, 0x5c, 0x9b, 0x63, 0x4a, 0x56, 0xd7, 0x99, 0x63, 0xd2, 0x3a, 0x38, 0x74, 0xff,
+	0xaa, 0x03, 0x6c, 0xf0, 0xdf, 0xca, 0x90, 0x76, 0xdf, 0x0c, 0x75, 0xa1, 0x83, 0x21, 0xc9, 0x9b,
+	0x41, 0xb2, 0x8a, 0x73, 0xda, 0x7b, 0x97, 0xd7, 0x55, 0x48, 0x50, 0xcb, 0x24, 0x0b, 0xf1, 0x1e,
+	0x64, 0x74, 0x9a, 0x1d, 0xbe, 0x51, 0xe0, 0xa9, 0xae, 0xc2, 0x98, 0x4e, 0xa5, 0x73, 0x1c, 0x92,
+	0x46, 0xdc, 0x38, 0x0d, 0xa5, 0x11, 0x37, 0x75, 0x14, 0x9a, 0xe4, 0x5f, 0x8a, 0x68, 0x09, 0xe3,
+	0x80, 0xf0, 0x6d, 0x15, 0x16, 0x25, 0x96, 0xd1, 0x5a, 0xa4, 0xc5, 0x21, 0x32, 0xf7, 0xea, 0xfa,
+	0x24, 0x8c, 0x9d, 0x36, 0xad, 0x5c, 0x08, 0x4a, 0x2b, 0x6e, 0x1c, 0xa8, 0xb4, 0xe2, 0xc6, 0xfd,
+	0x02, 0xac, 0x32, 0x56, 0xe2, 0x36, 0x3e, 0x99, 0x4d, 0xec, 0x07, 0x58, 0x20, 0x2f, 0x46, 0x93,
+	0xfe, 0xec, 0xf9, 0xa1, 0xad, 0x21, 0x4f, 0xfd, 0xea, 0xac, 0x3f, 0x9e, 0xf9, 0xbf, 0xf1, 0x86,
+	0xb6, 0xee, 0xfe, 0xc3, 0x04, 0x8b, 0xcb, 0x6c, 0x99, 0xc4, 0x99, 0xac, 0xb8, 0x5b, 0xdb, 0xd0,
+	0x6a, 0x69, 0xab, 0xdf, 0x9c, 0xdb, 0xf7, 0xed, 0x29, 0xb4, 0x14, 0xff, 0x13, 0x50, 0xaa, 0x60,
+	0xd5, 0xed, 0xe5, 0xa5, 0x0d, 0xcf, 0xbc, 0x14, 0x69, 0x9e, 0xd1, 0xf5, 0x32, 0x79, 0x21, 0xe0,
+	0x35, 0xc4, 0x01, 0x5d, 0x2e, 0x93, 0xd3, 0x98, 0xfd, 0x18, 0x1a, 0xf3, 0xc5, 0x2a, 0x7e, 0x4d,
+	0xb8, 0x75, 0x0e, 0xdb, 0xb8, 0xdc, 0x00, 0x15, 0xbc, 0xd0, 0x23, 0x85, 0x55, 0x7c, 0xd9, 0xda,
+	0x50, 0x58, 0xf9, 0x06, 0xd4, 0xd8, 0x13, 0x99, 0x55, 0x66, 0x8b, 0x21, 0xf2, 0x47, 0x81, 0x6a,
+	0x25, 0xb3, 0x03, 0x68, 0x8b, 0x2c, 0x93, 0x39, 0x86, 0xe6, 0xb4, 0xbf, 0x83, 0x2c, 0x36, 0x53,
+	0x2a, 0xc6, 0x80, 0x1a, 0x63, 0x74, 0xa1, 0x13, 0x27, 0xf9, 0x49, 0x12, 0x84, 0x17, 0xa1, 0x0c,
+	0xe8, 0x15, 0xb5, 0x78, 0x5d, 0x55, 0x72, 0xca, 0xce, 0xfb, 0x39, 0x65, 0xf7, 0x03, 0x38, 0x65,
+	0x6f, 0x9b, 0x53, 0x1e, 0x43, 0x33, 0x92, 0xd7, 0x32, 0xca, 0x9c, 0x7d, 0xda, 0x43, 0x49, 0xac,
+	0x07, 0xcd, 0x65, 0x24, 0xd6, 0x32, 0x75, 0x6c, 0x82, 0x88, 0x62, 0x3b, 0x25, 0x0d, 0x97, 0xf3,
+	0x24, 0x0d, 0xb8, 0xb2, 0xbb, 0xf1, 0x7f, 0x61, 0x92, 0x36, 0x34, 0x06, 0xc7, 0x67, 0xe3, 0xaf,
+	0x6c, 0x7d, 0x8b, 0x54, 0x8c, 0x8a, 0x54, 0x4c, 0xf6, 0x10, 0x76, 0xfb, 0xd3, 0xa9, 0x37, 0x7b,
+	0x35, 0x38, 0xee, 0x8f, 0x5f, 0x7a, 0x43, 0xbb, 0x41, 0xec, 0x72, 0x7c, 0x36, 0x1b, 0x4e, 0x7e,
+	0x3d, 0xb6, 0x9b, 0x48, 0xfb, 0xa7, 0xa3, 0xfe, 0xb7, 0x1e, 0xb7, 0x5b, 0xee, 0x3f, 0x0d, 0x68,
+	0x8c, 0xc2, 0xcb, 0x45, 0xce, 0xdc, 0xad, 0x8a, 0xdb, 0xc3, 0x13, 0x92, 0xa1, 0x5e, 0x6e, 0x55,
+	0xa7, 0xa4, 0xd7, 0x3b, 0xa5, 0x8f, 0xa0, 0x1d, 0xc6, 0xb9, 0x8c, 0xb3, 0x30, 0x5f, 0x53, 0xd9,
+	0xe9, 0x7c, 0xa3, 0x60, 0x9f, 0x83, 0x55, 0x5e, 0xd6, 0x3b, 0xdf, 0xc4, 0x6a, 0xd6, 0xf7, 0x78,
+	0x15, 0xb1, 0x6b, 0x0a, 0xb3, 0x5c, 0xc4, 0xf3, 0x82, 0xde, 0x75, 0x5e, 0xc9, 0x78, 0xd6, 0x40,
+	0xce, 0xc5, 0x5a, 0xf5, 0x67, 0x85, 0x80, 0x5a, 0x11, 0x5f, 0x46, 0x52, 0xf5, 0x65, 0x85, 0x80,
+	0xeb, 0x2c, 0x65, 0xbc, 0xba, 0x3a, 0x4f, 0x85, 0x6a, 0xcb, 0x2a, 0x99, 0x3d, 0x83, 0xbd, 0x4c,
+	0xce, 0x93, 0x38, 0x10, 0xe9, 0x7a, 0x40, 0xc1, 0x17, 0x45, 0x77, 0x4b, 0x8b, 0x2b, 0xbf, 0x09,
+	0x83, 0x7c, 0x41, 0x85, 0xb7, 0xcb, 0x0b, 0x01, 0x2b, 0x62, 0x21, 0x11, 0x46, 0x55, 0x75, 0x4a,
+	0x72, 0x7f, 0xa7, 0xf2, 0xbc, 0x0f, 0x9d, 0xd3, 0x89, 0x3f, 0x9e, 0xbd, 0x1a, 0xf9, 0x2f, 0x8f,
+	0xb1, 0x27, 0xfa, 0x3f, 0xd8, 0xe7, 0xde, 0x60, 0xf6, 0xaa, 0xcf, 0xbd, 0xbe, 0x52, 0x6a, 0xd8,
+	0xe1, 0x4c, 0x4f, 0x27, 0xe5, 0x24, 0x9d, 0x52, 0x7c, 0x72, 0xe4, 0x7b, 0x95, 0x9f, 0xc1, 0xfe,
+	0x1f, 0x1e, 0x0e, 0x7d, 0xf4, 0xf4, 0x27, 0xe3, 0xfe, 0x48, 0xa9, 0x4d, 0xf6, 0x08, 0xec, 0x63,
+	0xef, 0xc4, 0x2f, 0x9e, 0x79, 0xa5, 0x6d, 0xb8, 0x7f, 0xd0, 0xa1, 0xe9, 0xc5, 0xb9, 0x4a, 0x4f,
+	0xa4, 0x52, 0xe0, 0x68, 0x77, 0xa5, 0xa7, 0x9c, 0xf5, 0x0e, 0xe7, 0xd4, 0xd3, 0x65, 0xdc, 0x99,
+	0xae, 0x1e, 0xbe, 0x66, 0x51, 0x32, 0xc7, 0x3a, 0x31, 0x37, 0x33, 0xbf, 0x56, 0x3a, 0x5e, 0x59,
+	0xd9, 0x97, 0xc0, 0x4a, 0x2f, 0x11, 0x95, 0xf6, 0x7a, 0x31, 0x54, 0x3e, 0xef, 0x99, 0xc7, 0xba,
+	0xd0, 0x3c, 0x4f, 0x82, 0x50, 0x22, 0xdb, 0xe3, 0xd3, 0x68, 0x95, 0x9d, 0x1a, 0x57, 0x7a, 0xf6,
+	0x31, 0x34, 0x23, 0xcc, 0x03, 0xb2, 0xbe, 0x51, 0xf2, 0x1a, 0x95, 0x3b, 0x57, 0x06, 0x57, 0x40,
+	0x83, 0x88, 0xee, 0xbb, 0x10, 0xea, 0x9f, 0x67, 0x49, 0xb4, 0xca, 0xdf, 0x87, 0xd0, 0x33, 0x64,
+	0x95, 0x3c, 0xcc, 0xc3, 0xea, 0x71, 0x06, 0xf4, 0x28, 0x10, 0xe7, 0x95, 0xcd, 0x1d, 0x81, 0x55,
+	0xe8, 0xfc, 0xe1, 0x07, 0xec, 0x72, 0x2b, 0x0f, 0xee, 0x97, 0x60, 0xdf, 0xce, 0x1a, 0x3e, 0xe0,
+	0x37, 0xb4, 0x9c, 0xc6, 0xb5, 0x1b, 0x94, 0xd6, 0xe4, 0xa0, 0x71, 0x6d, 0x8d, 0xd2, 0x5b, 0x4a,
+	0x98, 0xc6, 0xb5, 0xb7, 0xee, 0x11, 0x58, 0x65, 0xc6, 0x36, 0x5e, 0xfa, 0x96, 0x97, 0xbe, 0xe5,
+	0xa5, 0x73, 0xed, 0x2d, 0x4a, 0x6f, 0x28, 0x95, 0x3a, 0xd7, 0xde, 0xb8, 0x3f, 0x07, 0xab, 0xca,
+	0xc1, 0xbd, 0xd7, 0xc0, 0x73, 0xdf, 0x8e, 0x72, 0xe3, 0xcd, 0xb6, 0xbc, 0xd9, 0x96, 0x37, 0x43,
+	0xef, 0xdf, 0x83, 0x53, 0x46, 0xfd, 0xce, 0x2a, 0x9f, 0x83, 0x25, 0x94, 0xee, 0x6e, 0x4c, 0xcb,
+	0x59, 0xe8, 0x91, 0xaa, 0xd5, 0x1c, 0x7d, 0xe3, 0xf1, 0xee, 0x6d, 0x28, 0x67, 0xb9, 0x7f, 0xd2,
+	0xa1, 0x79, 0xb6, 0x0c, 0x90, 0xfd, 0x3f, 0xd9, 0x62, 0xd0, 0x7d, 0x74, 0x2c, 0x2c, 0x75, 0x0a,
+	0xfd, 0x14, 0x9a, 0x94, 0xff, 0xb5, 0xa3, 0x6f, 0xaa, 0xb9, 0xac, 0x02, 0xae, 0x6c, 0x5b, 0xa4,
+	0x69, 0x7c, 0x6f, 0xd2, 0x34, 0xef, 0x7d, 0x0b, 0x1b, 0x1f, 0x70, 0x0b, 0x9b, 0xf7, 0xbb, 0x85,
+	0xee, 0xbe, 0xa2, 0xb8, 0x16, 0x18, 0xa3, 0xc9, 0xc0, 0x7e, 0xe0, 0xfe, 0x45, 0x83, 0x76, 0xd5,
+	0x62, 0xb3, 0x67, 0x60, 0x5e, 0x25, 0x41, 0x89, 0x16, 0xdb, 0xea, 0xbf, 0x0f, 0x4e, 0x12, 0xfc,
+	0x72, 0x44, 0x3b, 0xfb, 0xac, 0xec, 0x40, 0xf4, 0x3b, 0x32, 0x58, 0x4c, 0xf9, 0x00, 0xd8, 0x1e,
+	0x43, 0x33, 0x15, 0x41, 0xb8, 0x2a, 0x5a, 0x21, 0x8d, 0x2b, 0xc9, 0x7d, 0x02, 0x26, 0x9e, 0x01,
+	0x0f, 0x3f, 0xf4, 0x5f, 0xaa, 0x6f, 0xd5, 0x33, 0x7f, 0x34, 0xb4, 0x35, 0xf7, 0x39, 0xec, 0xcc,
+	0x64, 0x9a, 0x8a, 0x30, 0xc6, 0xb3, 0x52, 0x23, 0x8e, 0x9f, 0x0b, 0x45, 0x87, 0xfe, 0xce, 0xa7,
+	0x44, 0x61, 0x73, 0xff, 0xae, 0x41, 0x67, 0x9a, 0x27, 0xa9, 0x0c, 0x0a, 0x56, 0x71, 0xa0, 0x75,
+	0x2d, 0xd3, 0xac, 0xbc, 0xee, 0xbb, 0xbc, 0x14, 0xb1, 0xb7, 0xc9, 0xa4, 0x2c, 0x6e, 0xb6, 0xc1,
+	0x69, 0xcc, 0x5c, 0xec, 0xeb, 0x68, 0x4b, 0x15, 0x97, 0x55, 0x76, 0xfb, 0xbc, 0x34, 0x20, 0xeb,
+	0xa9, 0xe6, 0xc3, 0xec, 0x1a, 0x5b, 0x53, 0x94, 0x9e, 0x7d, 0x06, 0xad, 0x65, 0x2a, 0x2f, 0xc4,
+	0x79, 0x46, 0xcd, 0xb1, 0xea, 0x43, 0x8a, 0x53, 0x9d, 0x92, 0x81, 0x97, 0x13, 0xf0, 0x51, 0x27,
+	0x4c, 0xe9, 0xb7, 0x43, 0x93, 0xb0, 0xd9, 0x28, 0xf0, 0xaf, 0x40, 0x2a, 0x29, 0x07, 0x18, 0x40,
+	0x8b, 0x02, 0xa8, 0x69, 0xdc, 0x0b, 0xd8, 0xa9, 0x2f, 0x8b, 0x31, 0xbd, 0x0e, 0xe3, 0x80, 0x42,
+	0x6d, 0x73, 0x1a, 0x6f, 0x25, 0x4b, 0xbf, 0x57, 0xb2, 0x6c, 0x30, 0xd6, 0xe2, 0x8d, 0xe2, 0x2c,
+	0x1c, 0xba, 0xff, 0xd2, 0x60, 0xa7, 0xde, 0x47, 0x29, 0x52, 0x2c, 0xb6, 0xc1, 0xc7, 0xe9, 0x7f,
+	0x5b, 0x3d, 0xf7, 0xbf, 0x74, 0xf5, 0x4f, 0xc9, 0xc6, 0x1d, 0x9f, 0x92, 0xec, 0x29, 0x34, 0xb2,
+	0xbc, 0xfc, 0x5c, 0xed, 0x14, 0x2c, 0x52, 0x44, 0x38, 0x45, 0x35, 0x2f, 0xac, 0xee, 0x4f, 0xa1,
+	0x53, 0xd3, 0x62, 0x03, 0x13, 0x89, 0x2c, 0xc7, 0x82, 0xa3, 0xe0, 0x0d, 0x5e, 0xc9, 0xee, 0x17,
+	0xd0, 0x52, 0xdb, 0x20, 0x80, 0xaf, 0xe5, 0x5a, 0xc1, 0x83, 0x43, 0xec, 0x5a, 0xae, 0x45, 0xb4,
+	0x92, 0x65, 0x47, 0x47, 0xc2, 0x79, 0x93, 0xfe, 0x94, 0x3d, 0xff, 0xcf, 0x00, 0x77, 0x86, 0xef,
+	0x43, 0x35, 0x13, 0x00, 0x00,
 }
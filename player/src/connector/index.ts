@@ -1,11 +1,30 @@
 import { Message } from 'protobufjs';
 
-export default class RTC {
+// Connection is what the world needs of a transport to the server
+export interface Connection {
+  sendMessage(message: Uint8Array): void;
+  sendUpdate(message: Uint8Array): void;
+  close(): void;
+  onMessage(callback: (message: MessageEvent) => void): void;
+  onUpdate(callback: (message: MessageEvent) => void): void;
+}
+
+// Link is a Connection that tells when it drops
+export interface Link extends Connection {
+  onDrop(callback: () => void): void;
+}
+
+export { default as Socket } from './socket';
+export { default as Reconnecting } from './reconnecting';
+
+export default class RTC implements Link {
   private pc: RTCPeerConnection;
   private sc: RTCDataChannel;
   private uc: RTCDataChannel;
-  private sd: string;
-  private readyCallback: (descriptor: string) => void;
+  private readyCallback: (offer: string) => void;
+  private candidates: RTCIceCandidateInit[];
+  private candidateCallback: ((candidate: RTCIceCandidateInit) => void) | null;
+  private dropCallback: (() => void) | null;
   constructor() {
     this.pc = new RTCPeerConnection({
       iceServers: [
@@ -14,39 +33,80 @@ export default class RTC {
         }
       ]
     });
-    this.sd = '';
-    this.readyCallback = (descriptor: string) => {};
+    this.readyCallback = (offer: string) => {};
+    this.candidates = [];
+    this.candidateCallback = null;
+    this.dropCallback = null;
     this.sc = this.pc.createDataChannel('data');
     this.uc = this.pc.createDataChannel('ud');
     this.sc.onmessage = (e) => {};
     this.uc.onmessage = (e) => {};
     this.pc.onicecandidate = (event) => {
-      if (event.candidate === null) {
-        this.sd = btoa(this.pc.localDescription!.sdp);
-        this.readyCallback(this.sd);
+      // an empty candidate tells the server there are no more
+      const candidate = event.candidate
+        ? event.candidate.toJSON()
+        : { candidate: '', sdpMid: '', sdpMLineIndex: 0 };
+      if (this.candidateCallback) {
+        this.candidateCallback(candidate);
+      } else {
+        this.candidates.push(candidate);
+      }
+    };
+    this.pc.oniceconnectionstatechange = () => {
+      const state = this.pc.iceConnectionState;
+      if (state === 'failed' || state === 'disconnected') {
+        this.drop();
       }
     };
     this.pc.onnegotiationneeded = (e) =>
-      this.pc.createOffer().then((d) => this.pc.setLocalDescription(d));
+      this.pc
+        .createOffer()
+        .then((d) => this.pc.setLocalDescription(d))
+        .then(() => this.readyCallback(this.pc.localDescription!.sdp));
   }
   public sendMessage = (message: Uint8Array) => {
-    this.sc.send(message);
+    if (this.sc.readyState === 'open') {
+      this.sc.send(message);
+    }
   }
   public sendUpdate = (message: Uint8Array) => {
-    this.uc.send(message);
+    if (this.uc.readyState === 'open') {
+      this.uc.send(message);
+    }
   }
-  public startSession = (descriptor: string) => {
+  public startSession = (answer: string) => {
     try {
       this.pc.setRemoteDescription(
-        new RTCSessionDescription({ type: 'answer', sdp: atob(descriptor) })
+        new RTCSessionDescription({ type: 'answer', sdp: answer })
       );
     } catch (e) {
       alert(e);
     }
   }
-  public onReady = (callback: (descriptor: string) => void) => {
+  public close = () => {
+    this.dropCallback = null;
+    this.pc.close();
+  }
+  public onDrop = (callback: () => void) => {
+    this.dropCallback = callback;
+  }
+  // drop tells, once, that the connection is lost or could not be made
+  public drop = () => {
+    const callback = this.dropCallback;
+    this.dropCallback = null;
+    if (callback) {
+      callback();
+    }
+  }
+  public onReady = (callback: (offer: string) => void) => {
     this.readyCallback = callback;
   }
+  // trickle hands the candidates gathered so far and every later one to a callback
+  public trickle = (callback: (candidate: RTCIceCandidateInit) => void) => {
+    this.candidateCallback = callback;
+    this.candidates.forEach(callback);
+    this.candidates = [];
+  }
   public onMessage = (callback: (message: MessageEvent) => void) => {
     this.sc.onmessage = callback;
   }
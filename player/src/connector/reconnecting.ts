@@ -0,0 +1,51 @@
+import { Connection, Link } from '.';
+
+// Reconnecting is a Connection that dials a new link whenever the current one drops,
+// so that the server resumes the session of the player if they are back within its
+// grace period
+export default class Reconnecting implements Connection {
+  private dial: () => Link;
+  private delay: number;
+  private current: Link;
+  private closed: boolean;
+  private messageCallback: (message: MessageEvent) => void;
+  private updateCallback: (message: MessageEvent) => void;
+  constructor(dial: () => Link, delay: number = 2000) {
+    this.dial = dial;
+    this.delay = delay;
+    this.closed = false;
+    this.messageCallback = (e) => {};
+    this.updateCallback = (e) => {};
+    this.current = this.open();
+  }
+  public sendMessage = (message: Uint8Array) => {
+    this.current.sendMessage(message);
+  }
+  public sendUpdate = (message: Uint8Array) => {
+    this.current.sendUpdate(message);
+  }
+  public close = () => {
+    this.closed = true;
+    this.current.close();
+  }
+  public onMessage = (callback: (message: MessageEvent) => void) => {
+    this.messageCallback = callback;
+  }
+  public onUpdate = (callback: (message: MessageEvent) => void) => {
+    this.updateCallback = callback;
+  }
+  private open = (): Link => {
+    const link = this.dial();
+    link.onMessage((m) => this.messageCallback(m));
+    link.onUpdate((m) => this.updateCallback(m));
+    link.onDrop(() => {
+      link.close();
+      setTimeout(() => {
+        if (!this.closed) {
+          this.current = this.open();
+        }
+      }, this.delay);
+    });
+    return link;
+  }
+}
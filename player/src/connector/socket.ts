@@ -0,0 +1,88 @@
+import { Link } from '.';
+
+// Each frame starts with a byte telling the stream it belongs to
+const messageFrame = 0;
+const updateFrame = 1;
+
+// Socket carries messages and updates over a WebSocket, for networks that block
+// WebRTC. Its first frame is the token of the player.
+export default class Socket implements Link {
+  private ws: WebSocket;
+  private pending: Uint8Array[];
+  private openCallback: () => void;
+  private closeCallback: (e: CloseEvent) => void;
+  private dropCallback: (() => void) | null;
+  private messageCallback: (message: MessageEvent) => void;
+  private updateCallback: (message: MessageEvent) => void;
+  constructor(url: string, token: string) {
+    this.messageCallback = (e) => {};
+    this.updateCallback = (e) => {};
+    this.openCallback = () => {};
+    this.closeCallback = (e) => {};
+    this.dropCallback = null;
+    this.pending = [];
+    this.ws = new WebSocket(url);
+    this.ws.binaryType = 'arraybuffer';
+    // frames sent before the socket opened are held until it does
+    this.ws.onopen = () => {
+      this.ws.send(token);
+      this.pending.forEach((frame) => this.ws.send(frame));
+      this.pending = [];
+      this.openCallback();
+    };
+    // closes the server turns the player away with are not drops
+    this.ws.onclose = (e) => {
+      this.closeCallback(e);
+      if (e.code < 4000 && this.dropCallback) {
+        this.dropCallback();
+      }
+    };
+    this.ws.onmessage = (e) => {
+      const frame = new Uint8Array(e.data);
+      const message = new MessageEvent('message', {
+        data: frame.slice(1).buffer
+      });
+      if (frame[0] === messageFrame) {
+        this.messageCallback(message);
+      } else if (frame[0] === updateFrame) {
+        this.updateCallback(message);
+      }
+    };
+  }
+  public sendMessage = (message: Uint8Array) => {
+    this.send(messageFrame, message);
+  }
+  public sendUpdate = (message: Uint8Array) => {
+    this.send(updateFrame, message);
+  }
+  public close = () => {
+    this.dropCallback = null;
+    this.ws.close();
+  }
+  public onOpen = (callback: () => void) => {
+    this.openCallback = callback;
+  }
+  // the server closes with 4000 plus an HTTP status when it turns the player away
+  public onClose = (callback: (e: CloseEvent) => void) => {
+    this.closeCallback = callback;
+  }
+  public onDrop = (callback: () => void) => {
+    this.dropCallback = callback;
+  }
+  public onMessage = (callback: (message: MessageEvent) => void) => {
+    this.messageCallback = callback;
+  }
+  public onUpdate = (callback: (message: MessageEvent) => void) => {
+    this.updateCallback = callback;
+  }
+  private send = (stream: number, message: Uint8Array) => {
+    const frame = new Uint8Array(message.length + 1);
+    frame[0] = stream;
+    frame.set(message, 1);
+    if (this.ws.readyState === WebSocket.CONNECTING) {
+      this.pending.push(frame);
+    } else if (this.ws.readyState === WebSocket.OPEN) {
+      this.ws.send(frame);
+    }
+  }
+}
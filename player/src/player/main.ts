@@ -1,6 +1,6 @@
 import * as THREE from 'three';
 
-import RTC from '../connector';
+import RTC, { Connection, Link, Reconnecting, Socket } from '../connector';
 
 import World from '../world';
 
@@ -17,6 +17,7 @@ export default class Player {
   private cameraYaw: THREE.Object3D;
   private locked: boolean;
   private lookchange: boolean;
+  private animating: boolean;
   private inputs: any;
   private atmoColor: THREE.Color;
   private clock: THREE.Clock;
@@ -26,7 +27,7 @@ export default class Player {
   private atmoFar: number;
   private atmo: boolean;
   private proto: Proto;
-  private connection: RTC | undefined;
+  private connection: Connection | undefined;
   constructor() {
     this.scene = new THREE.Scene();
     this.atmoColor = new THREE.Color(0xffffff);
@@ -50,6 +51,7 @@ export default class Player {
     this.cameraYaw.position.z = 5;
     this.locked = false;
     this.lookchange = false;
+    this.animating = false;
     this.scene.add(this.cameraYaw);
     this.renderer = new THREE.WebGLRenderer({
       antialias: true
@@ -69,8 +71,12 @@ export default class Player {
       document.addEventListener('pointerlockchange', this.pointerLockChange);
       document.addEventListener('mousemove', this.mouseMove);
     };
+    this.renderer.domElement.onmousedown = this.mouseDown;
+    this.renderer.domElement.oncontextmenu = (e) => e.preventDefault();
     this.proto = new Proto(() => {
-      this.connection = new RTC();
+      this.connection = new Reconnecting(this.dial);
+      this.connection.onMessage(this.recvData);
+      this.connection.onUpdate(this.recvUpdate);
       this.world = new World(
         this.scene,
         this.camera,
@@ -78,10 +84,25 @@ export default class Player {
         this.connection
       );
       this.world.setLocation(0, 0, 0);
-      this.connection.onReady(this.connect);
     });
   }
   public setAtmo = (e: boolean) => {
+    this.applyAtmo(e);
+    // the server keeps it with the record of the player
+    if (this.connection) {
+      this.connection.sendMessage(
+        this.proto
+          .Request!.encode(
+            this.proto.Request!.fromObject({
+              type: 5,
+              settings: [{ key: 'atmo', value: e ? 'on' : 'off' }]
+            })
+          )
+          .finish()
+      );
+    }
+  }
+  private applyAtmo = (e: boolean) => {
     this.atmo = e;
     if (e) {
       this.scene.fog = new THREE.Fog(
@@ -93,17 +114,86 @@ export default class Player {
       this.scene.fog = new THREE.Fog(this.atmoColor.getHex(), this.atmoNear, 0);
     }
   }
-  private connect = (descriptor: string) => {
-    fetch(`http://${window.location.hostname}:8081/${descriptor}`)
-      .then((rawdata) => {
-        return rawdata.text();
+  // dial opens a link to the server, over a WebSocket with ?transport=websocket where
+  // WebRTC is blocked
+  private dial = (): Link => {
+    const params = new URLSearchParams(window.location.search);
+    if (params.get('transport') === 'websocket') {
+      const socket = new Socket(
+        `ws://${window.location.hostname}:8081/socket`,
+        this.token()
+      );
+      socket.onClose((e) => {
+        if (e.code >= 4000) {
+          alert(`Could not connect: ${e.reason}`);
+        }
+      });
+      return socket;
+    }
+    const rtc = new RTC();
+    rtc.onReady((offer) => this.connect(rtc, offer));
+    return rtc;
+  }
+  private connect = (rtc: RTC, offer: string) => {
+    const api = `http://${window.location.hostname}:8081/sessions`;
+    this.signal(api, { offer })
+      .then((session) => {
+        rtc.startSession(session.answer);
+        rtc.trickle((candidate) =>
+          this.signal(`${api}/${session.id}/candidates`, candidate).catch(
+            (e) => console.warn(e)
+          )
+        );
       })
-      .then((data) => {
-        this.connection!.onMessage(this.recvData);
-        this.connection!.onUpdate(this.recvUpdate);
-        this.connection!.startSession(data);
+      .catch((e) => {
+        // a player turned away stays away, anything else is tried again
+        if (e.status === 401 || e.status === 403) {
+          rtc.close();
+          alert(`Could not connect: ${e.message}`);
+        } else {
+          rtc.drop();
+        }
       });
   }
+  // token returns the token the player connects with, taken from ?token= once and
+  // kept so that it stays out of the address bar
+  private token = (): string => {
+    const params = new URLSearchParams(window.location.search);
+    const token = params.get('token');
+    if (token) {
+      localStorage.setItem('token', token);
+      params.delete('token');
+      const query = params.toString();
+      window.history.replaceState(
+        null,
+        '',
+        window.location.pathname + (query ? `?${query}` : '')
+      );
+    }
+    return localStorage.getItem('token') || '';
+  }
+  // signal posts a request to the signaling API, rejecting with its error message
+  private signal = (url: string, body: object): Promise<any> =>
+    fetch(url, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+        Authorization: `Bearer ${this.token()}`
+      },
+      body: JSON.stringify(body)
+    }).then((response) => {
+      if (response.status === 204) {
+        return null;
+      }
+      return response.json().then((data) => {
+        if (!response.ok) {
+          const error: any = new Error(data.error.message);
+          error.status = response.status;
+          throw error;
+        }
+        return data;
+      });
+    })
   private pAnimate = () => {
     requestAnimationFrame(this.pAnimate);
     this.delta += this.clock.getDelta();
@@ -131,11 +221,14 @@ export default class Player {
     if (this.inputs[16]) {
       this.cameraYaw.translateY(-0.1);
     }
-    if (this.lookchange) {
+    const moved = [87, 83, 65, 68, 32, 16].some((k) => this.inputs[k]);
+    if (this.lookchange || moved) {
+      const position = this.cameraYaw.position;
       this.connection!.sendUpdate(
         this.proto
           .Update!.encode(
             this.proto.Update!.fromObject({
+              position: { x: position.x, y: position.y, z: position.z },
               rotation: {
                 x: this.cameraPitch.rotation.x,
                 y: this.cameraYaw.rotation.y,
@@ -155,12 +248,44 @@ export default class Player {
     switch (resp.type) {
       case 2:
         this.world!.assignChunk(resp.chunk);
-        this.pAnimate();
+        // the chunk comes again after every reconnection
+        if (!this.animating) {
+          this.animating = true;
+          this.pAnimate();
+        }
+        break;
+      case 6:
+        this.connection!.close();
+        alert('The server shut down');
+        break;
+      case 7:
+        this.restore(resp.player);
         break;
       default:
         this.world!.resources.handleRequestResponse(resp);
     }
   }
+  // restore puts the player back where the server last saw them
+  private restore = (record: any) => {
+    const chunk = record.chunk || {};
+    this.world!.setLocation(chunk.x || 0, chunk.y || 0, chunk.z || 0);
+    if (record.position) {
+      this.cameraYaw.position.set(
+        record.position.x || 0,
+        record.position.y || 0,
+        record.position.z || 0
+      );
+    }
+    if (record.rotation) {
+      this.cameraPitch.rotation.x = record.rotation.x || 0;
+      this.cameraYaw.rotation.y = record.rotation.y || 0;
+    }
+    (record.settings || []).forEach((s: any) => {
+      if (s.key === 'atmo') {
+        this.applyAtmo(s.value === 'on');
+      }
+    });
+  }
   private recvUpdate = (message: MessageEvent) => {
     const ud: any = this.proto.Update!.decode(new Uint8Array(message.data));
     this.world!.update(ud);
@@ -179,6 +304,17 @@ export default class Player {
       Math.min(PI_2, this.cameraPitch.rotation.x)
     );
   }
+  private mouseDown = (e: MouseEvent) => {
+    if (!this.locked) {
+      return;
+    }
+    const target = new THREE.Vector3();
+    this.camera.getWorldDirection(target);
+    target
+      .multiplyScalar(4)
+      .add(this.camera.getWorldPosition(new THREE.Vector3()));
+    this.world!.editTerrain(e.button === 2, target);
+  }
   private pointerLockChange = (e: any) => {
     this.locked =
       (document as any).pointerLockElement === this.renderer.domElement;
@@ -5,6 +5,8 @@ export default class Proto {
   public Update: protobuf.Type | undefined;
   public Response: protobuf.Type | undefined;
   public Mesh: protobuf.Type | undefined;
+  public Material: protobuf.Type | undefined;
+  public PackedMesh: protobuf.Type | undefined;
   private root: protobuf.Root | undefined;
   constructor(ready: () => void) {
     protobuf.load(require('../../../pb/pb.proto'), (err, root) => {
@@ -14,6 +16,8 @@ export default class Proto {
         this.Response = this.root!.lookupType('pb.Response');
         this.Update = this.root!.lookupType('pb.Update');
         this.Mesh = this.root!.lookupType('pb.Mesh');
+        this.Material = this.root!.lookupType('pb.Material');
+        this.PackedMesh = this.root!.lookupType('pb.PackedMesh');
         ready();
       }
     });
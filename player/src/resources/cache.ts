@@ -0,0 +1,49 @@
+// Entry is an asset as it was received, with the hash the server sent it with
+export interface Entry {
+  hash: string;
+  data: Uint8Array;
+  levels?: number;
+  encoding?: number;
+}
+
+const store = 'assets';
+
+// Cache keeps the assets received from the server in IndexedDB, so that later sessions
+// only download the ones that changed. Where IndexedDB is unavailable it keeps nothing
+// and every asset is downloaded again.
+export default class Cache {
+  private db: Promise<IDBDatabase | undefined>;
+  constructor(name: string = 'goworld') {
+    this.db = new Promise((resolve) => {
+      if (typeof indexedDB === 'undefined') {
+        resolve(undefined);
+        return;
+      }
+      const open = indexedDB.open(name, 1);
+      open.onupgradeneeded = () => open.result.createObjectStore(store);
+      open.onsuccess = () => resolve(open.result);
+      open.onerror = () => resolve(undefined);
+    });
+  }
+  public get = (key: string): Promise<Entry | undefined> => {
+    return this.db.then(
+      (db) =>
+        new Promise<Entry | undefined>((resolve) => {
+          if (!db) {
+            resolve(undefined);
+            return;
+          }
+          const request = db.transaction(store).objectStore(store).get(key);
+          request.onsuccess = () => resolve(request.result);
+          request.onerror = () => resolve(undefined);
+        })
+    );
+  }
+  public put = (key: string, entry: Entry) => {
+    this.db.then((db) => {
+      if (db) {
+        db.transaction(store, 'readwrite').objectStore(store).put(entry, key);
+      }
+    });
+  }
+}
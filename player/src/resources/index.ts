@@ -1,6 +1,7 @@
 import * as THREE from 'three';
 import Proto from '../proto';
-import RTC from '@/connector';
+import Cache from './cache';
+import { Connection } from '@/connector';
 import { BufferGeometry, MeshStandardMaterial } from 'three';
 export interface Material {
   color: string;
@@ -27,6 +28,12 @@ interface Response {
   material: Material;
   type: number;
   id: number;
+  assetType: number;
+  encoding: number;
+  lod: number;
+  levels: number;
+  hash: string;
+  notModified: boolean;
 }
 class IncompleteTexture {
   private buffer: ArrayBuffer[];
@@ -73,6 +80,7 @@ class IncompleteMesh {
 
 export interface Mesh {
   vertices: number[];
+  normals: number[];
   faces: Array<{ a: number; b: number; c: number }>;
 }
 
@@ -81,19 +89,45 @@ export interface MeshCallback {
   bd: any;
 }
 
+// Textures are first requested as a small mipmap and upgraded as the finer
+// levels arrive.
+const previewLevel = 2;
+
+// cacheKey names an asset in the cache by the type of request it was received for
+const cacheKey = (type: number, id: number, lod: number) =>
+  `${type}:${id}:${lod}`;
+
+const concat = (buffers: Uint8Array[]) => {
+  let tlen: number = 0;
+  buffers.forEach((buffer) => {
+    tlen = tlen + buffer.byteLength;
+  });
+  const tmp = new Uint8Array(tlen);
+  let offset: number = 0;
+  buffers.forEach((buffer) => {
+    tmp.set(new Uint8Array(buffer), offset);
+    offset = offset + buffer.byteLength;
+  });
+  return tmp;
+};
+
 export class Manager {
   private textures: Map<number, THREE.Texture>;
+  private textureLevels: Map<number, ImageBitmap[]>;
   private meshes: Map<number, Mesh>;
   private materials: Map<number, THREE.Material>;
-  private incompleteTextures: Map<number, IncompleteTexture>;
+  private incompleteTextures: Map<string, IncompleteTexture>;
   private incompleteMeshes: Map<number, IncompleteMesh>;
   private materialCallbacks: Map<number, Array<(m: THREE.Material) => void>>;
   private meshCallbacks: Map<number, MeshCallback[]>;
   private antiCallDuplicateMaterials: Map<number, boolean>;
   private antiCallDuplicateMeshes: Map<number, boolean>;
+  private meshChangedCallback: (id: number, m: Mesh) => void;
   private proto: Proto;
-  private rtc: RTC;
-  constructor(p: Proto, r: RTC) {
+  private rtc: Connection;
+  private cache: Cache;
+  constructor(p: Proto, r: Connection) {
+    this.cache = new Cache();
     this.materials = new Map<number, THREE.Material>();
     this.rtc = r;
     this.proto = p;
@@ -105,9 +139,14 @@ export class Manager {
     this.textures = new Map<number, THREE.Texture>();
     this.antiCallDuplicateMaterials = new Map<number, boolean>();
     this.antiCallDuplicateMeshes = new Map<number, boolean>();
-    this.incompleteTextures = new Map<number, IncompleteTexture>();
+    this.textureLevels = new Map<number, ImageBitmap[]>();
+    this.incompleteTextures = new Map<string, IncompleteTexture>();
     this.incompleteMeshes = new Map<number, IncompleteMesh>();
     this.meshes = new Map<number, Mesh>();
+    this.meshChangedCallback = (id: number, m: Mesh) => {};
+  }
+  public onMeshChanged = (callback: (id: number, m: Mesh) => void) => {
+    this.meshChangedCallback = callback;
   }
   public getTexture = (id: number) => {
     id = id || 0;
@@ -118,15 +157,7 @@ export class Manager {
     texture.minFilter = THREE.NearestFilter;
     texture.magFilter = THREE.NearestFilter;
     this.textures.set(id, texture);
-    this.rtc.sendMessage(
-      this.proto
-        .Response!.encode(
-          this.proto.Response!.fromObject({
-            id
-          })
-        )
-        .finish()
-    );
+    this.requestTexture(id, previewLevel);
     return texture;
   }
   public getMesh = (
@@ -144,16 +175,7 @@ export class Manager {
     }
     this.meshCallbacks.get(id)!.push({ cb: callback, bd: bodyData });
     if (!this.antiCallDuplicateMeshes.has(id)) {
-      this.rtc.sendMessage(
-        this.proto
-          .Response!.encode(
-            this.proto.Response!.fromObject({
-              type: 3,
-              id
-            })
-          )
-          .finish()
-      );
+      this.requestMesh(id);
     }
     this.antiCallDuplicateMeshes.set(id, true);
   }
@@ -168,29 +190,26 @@ export class Manager {
     }
     this.materialCallbacks.get(id)!.push(callback);
     if (!this.antiCallDuplicateMaterials.has(id)) {
-      this.rtc.sendMessage(
-        this.proto
-          .Response!.encode(
-            this.proto.Response!.fromObject({
-              type: 2,
-              id
-            })
-          )
-          .finish()
-      );
+      this.request(2, id);
     }
     this.antiCallDuplicateMaterials.set(id, true);
   }
   public handleRequestResponse = (message: Response) => {
+    if (message.notModified) {
+      this.notModified(message);
+      return;
+    }
     switch (message.type) {
+      case 5:
+        this.assetChanged(message.assetType || 0, message.id);
+        break;
       case 4:
         if (!message.parts) {
-          const mesh = (this.proto.Mesh!.decode(
-            message.meshData
-          ) as unknown) as Mesh;
-          this.meshes.set(message.id, mesh);
-          this.meshCallbacks.get(message.id)!.forEach((d) => d.cb(mesh, d.bd));
-          this.meshCallbacks.delete(message.id);
+          this.keep(3, message, message.meshData);
+          this.meshReceived(
+            message.id,
+            this.decodeMesh(message.meshData, message.encoding)
+          );
         } else {
           if (this.incompleteMeshes.has(message.id)) {
             this.incompleteMeshes.get(message.id)!.add(message);
@@ -198,101 +217,294 @@ export class Manager {
             this.incompleteMeshes.set(message.id, new IncompleteMesh(message));
           }
           if (this.incompleteMeshes.get(message.id)!.full()) {
-            const data = this.incompleteMeshes.get(message.id)!.get();
-            let tlen: number = 0;
-            data.forEach((buffer) => {
-              tlen = tlen + buffer.byteLength;
-            });
-            const tmp = new Uint8Array(tlen);
-            let offset: number = 0;
-            data.forEach((buffer) => {
-              tmp.set(new Uint8Array(buffer), offset);
-              offset = offset + buffer.byteLength;
-            });
-            const mesh = (this.proto.Mesh!.decode(tmp) as unknown) as Mesh;
-            this.meshes.set(message.id, mesh);
-            this.meshCallbacks.get(message.id)!.forEach((d) => d.cb(mesh, d.bd));
-            this.meshCallbacks.delete(message.id);
+            const tmp = concat(this.incompleteMeshes.get(message.id)!.get());
+            this.incompleteMeshes.delete(message.id);
+            this.keep(3, message, tmp);
+            this.meshReceived(
+              message.id,
+              this.decodeMesh(tmp, message.encoding)
+            );
           }
         }
         break;
       case 3:
-        const material = message.material;
-        const matconfig = {
-          map: material.textureID
-            ? this.getTexture(material.textureID)
-            : undefined,
-          color: material.color,
-          emissive: material.emissive,
-          roughness: material.roughness,
-          metalness: material.metalness,
-          wireframe: !!material.wireframe,
-          side: THREE.FrontSide,
-          dashSize: material.dashSize,
-          gapSize: material.gapSize,
-          scale: material.scale
-        };
-        switch (material.side) {
-          case 2:
-            matconfig.side = THREE.DoubleSide;
-            break;
-          case 1:
-            matconfig.side = THREE.BackSide;
-            break;
-        }
-        let m: THREE.Material;
-        switch (material.type) {
-          case 5:
-            m = new THREE.LineDashedMaterial(matconfig);
-          case 4:
-            m = new THREE.LineBasicMaterial(matconfig);
-            break;
-          case 3:
-            m = new THREE.MeshStandardMaterial(matconfig);
-            break;
-          case 1:
-            m = new THREE.MeshBasicMaterial(matconfig);
-            break;
-          default:
-            m = new THREE.MeshLambertMaterial(matconfig);
-        }
-        m.flatShading = !!material.flatShaded;
-        this.materials.set(message.id, m);
-        this.materialCallbacks.get(message.id)!.forEach((callback) => {
-          callback(m);
-        });
-        this.materialCallbacks.delete(message.id);
+        this.keep(
+          2,
+          message,
+          this.proto.Material!.encode(message.material).finish()
+        );
+        this.materialReceived(message.id, message.material);
         break;
       default:
         if (!message.parts) {
-          const imageBlob = new Blob([message.texture.data], {
-            type: 'image/png'
-          });
-          createImageBitmap(imageBlob).then((imageBitmap) => {
-            this.textures.get(message.id)!.image = imageBitmap;
-            this.textures.get(message.id)!.needsUpdate = true;
-          });
+          this.keep(0, message, message.texture.data as any);
+          this.textureReceived(message, [message.texture.data]);
         } else {
-          if (this.incompleteTextures.has(message.id)) {
-            this.incompleteTextures.get(message.id)!.add(message);
+          const key = `${message.id}:${message.lod || 0}`;
+          if (this.incompleteTextures.has(key)) {
+            this.incompleteTextures.get(key)!.add(message);
           } else {
-            this.incompleteTextures.set(
-              message.id,
-              new IncompleteTexture(message)
-            );
+            this.incompleteTextures.set(key, new IncompleteTexture(message));
           }
-          if (this.incompleteTextures.get(message.id)!.full()) {
-            const data = this.incompleteTextures.get(message.id)!.get();
-            const imageBlob = new Blob(data, {
-              type: 'image/png'
-            });
-            createImageBitmap(imageBlob).then((imageBitmap) => {
-              this.textures.get(message.id)!.image = imageBitmap;
-              this.textures.get(message.id)!.needsUpdate = true;
-              this.incompleteTextures.delete(message.id);
-            });
+          if (this.incompleteTextures.get(key)!.full()) {
+            const data = this.incompleteTextures.get(key)!.get();
+            this.incompleteTextures.delete(key);
+            this.keep(0, message, concat(data as any));
+            this.textureReceived(message, data);
+          }
+        }
+    }
+  }
+  // keep caches an asset with its hash, for the server to tell later that it is
+  // unchanged
+  private keep = (type: number, message: Response, data: Uint8Array) => {
+    if (!message.hash) {
+      return;
+    }
+    this.cache.put(cacheKey(type, message.id, message.lod || 0), {
+      hash: message.hash,
+      data: concat([data]),
+      levels: message.levels,
+      encoding: message.encoding
+    });
+  }
+  // notModified serves an asset from the cache, or downloads it again if the browser
+  // dropped it meanwhile
+  private notModified = (message: Response) => {
+    const type = ({ 0: 0, 3: 2, 4: 3 } as any)[message.type || 0];
+    const lod = message.lod || 0;
+    this.cache.get(cacheKey(type, message.id, lod)).then((entry) => {
+      if (!entry || entry.hash !== message.hash) {
+        this.send(type, message.id, lod, '');
+        return;
+      }
+      switch (type) {
+        case 3:
+          this.meshReceived(
+            message.id,
+            this.decodeMesh(entry.data, entry.encoding || 0)
+          );
+          break;
+        case 2:
+          this.materialReceived(
+            message.id,
+            (this.proto.Material!.decode(entry.data) as unknown) as Material
+          );
+          break;
+        default:
+          message.levels = entry.levels || 1;
+          this.textureReceived(message, [entry.data as any]);
+      }
+    });
+  }
+  private materialReceived = (id: number, material: Material) => {
+    const matconfig = {
+      map: material.textureID ? this.getTexture(material.textureID) : undefined,
+      color: material.color,
+      emissive: material.emissive,
+      roughness: material.roughness,
+      metalness: material.metalness,
+      wireframe: !!material.wireframe,
+      side: THREE.FrontSide,
+      dashSize: material.dashSize,
+      gapSize: material.gapSize,
+      scale: material.scale
+    };
+    switch (material.side) {
+      case 2:
+        matconfig.side = THREE.DoubleSide;
+        break;
+      case 1:
+        matconfig.side = THREE.BackSide;
+        break;
+    }
+    let m: THREE.Material;
+    switch (material.type) {
+      case 5:
+        m = new THREE.LineDashedMaterial(matconfig);
+      case 4:
+        m = new THREE.LineBasicMaterial(matconfig);
+        break;
+      case 3:
+        m = new THREE.MeshStandardMaterial(matconfig);
+        break;
+      case 1:
+        m = new THREE.MeshBasicMaterial(matconfig);
+        break;
+      default:
+        m = new THREE.MeshLambertMaterial(matconfig);
+    }
+    if (this.materials.has(id)) {
+      const existing = this.materials.get(id)!;
+      existing.setValues(matconfig);
+      existing.flatShading = !!material.flatShaded;
+      existing.needsUpdate = true;
+      return;
+    }
+    m.flatShading = !!material.flatShaded;
+    this.materials.set(id, m);
+    (this.materialCallbacks.get(id) || []).forEach((callback) => {
+      callback(m);
+    });
+    this.materialCallbacks.delete(id);
+  }
+  private textureReceived = (message: Response, data: ArrayBuffer[]) => {
+    const id = message.id;
+    const lod = message.lod || 0;
+    const levels = message.levels || 1;
+    const imageBlob = new Blob(data, {
+      type: 'image/png'
+    });
+    createImageBitmap(imageBlob).then((imageBitmap) => {
+      const texture = this.textures.get(id);
+      if (!texture) {
+        return;
+      }
+      const first = !this.textureLevels.has(id);
+      if (first) {
+        this.textureLevels.set(id, new Array(levels));
+      }
+      const received = this.textureLevels.get(id)!;
+      received[lod] = imageBitmap;
+      if (first) {
+        for (let l = levels - 1; l >= 0; l--) {
+          if (l !== lod) {
+            this.requestTexture(id, l);
           }
         }
+      }
+      const finest = received.findIndex((b) => b !== undefined);
+      const complete = received.filter((b) => b).length === levels;
+      if (finest !== lod && !complete) {
+        return;
+      }
+      texture.image = received[finest];
+      if (complete) {
+        texture.mipmaps = received;
+        texture.generateMipmaps = false;
+        texture.minFilter = THREE.NearestMipMapNearestFilter;
+      }
+      texture.needsUpdate = true;
+    });
+  }
+  private meshReceived = (id: number, mesh: Mesh) => {
+    this.meshes.set(id, mesh);
+    this.antiCallDuplicateMeshes.set(id, true);
+    // a reload may have left both entities showing the old mesh and new callers waiting
+    this.meshChangedCallback(id, mesh);
+    (this.meshCallbacks.get(id) || []).forEach((d) => d.cb(mesh, d.bd));
+    this.meshCallbacks.delete(id);
+  }
+  private assetChanged = (type: number, id: number) => {
+    switch (type) {
+      case 3:
+        // entities showing the mesh get the new one through meshChangedCallback
+        if (this.meshes.has(id)) {
+          this.meshes.delete(id);
+          this.requestMesh(id);
+        } else {
+          this.antiCallDuplicateMeshes.delete(id);
+        }
+        break;
+      case 2:
+        if (this.materials.has(id)) {
+          this.request(type, id);
+        }
+        break;
+      case 0:
+        if (this.textures.has(id)) {
+          this.textureLevels.delete(id);
+          this.requestTexture(id, previewLevel);
+        }
+        break;
+    }
+  }
+  private decodeMesh = (data: Uint8Array, encoding: number): Mesh => {
+    if (!encoding) {
+      return (this.proto.Mesh!.decode(data) as unknown) as Mesh;
     }
+    const p: any = this.proto.PackedMesh!.decode(data);
+    const quantized = encoding === 2;
+    const floats = (b: Uint8Array) => {
+      const v = new DataView(b.buffer, b.byteOffset, b.byteLength);
+      const out: number[] = [];
+      for (let i = 0; i + 4 <= b.byteLength; i += 4) {
+        out.push(v.getFloat32(i, true));
+      }
+      return out;
+    };
+    const unorm = (b: Uint8Array, min: number[], max: number[]) => {
+      const v = new DataView(b.buffer, b.byteOffset, b.byteLength);
+      const out: number[] = [];
+      for (let i = 0; i + 2 <= b.byteLength; i += 2) {
+        const k = (i / 2) % min.length;
+        out.push(min[k] + (v.getUint16(i, true) / 65535) * (max[k] - min[k]));
+      }
+      return out;
+    };
+    const mesh: Mesh = { vertices: [], normals: [], faces: [] };
+    let uvs: number[];
+    if (quantized) {
+      mesh.vertices = unorm(p.positions, p.min, p.max);
+      (p.normals as Uint8Array).forEach((b) => {
+        mesh.normals.push(Math.max(((b << 24) >> 24) / 127, -1));
+      });
+      uvs = p.uvMin.length ? unorm(p.uvs, p.uvMin, p.uvMax) : [];
+    } else {
+      mesh.vertices = floats(p.positions);
+      mesh.normals = floats(p.normals);
+      uvs = floats(p.uvs);
+    }
+    const indices: number[] = [];
+    let prev = 0;
+    let shift = 0;
+    let value = 0;
+    (p.indices as Uint8Array).forEach((b) => {
+      value += (b & 0x7f) * Math.pow(2, shift);
+      shift += 7;
+      if (b & 0x80) {
+        return;
+      }
+      prev += value % 2 ? -(value + 1) / 2 : value / 2;
+      indices.push(prev);
+      shift = 0;
+      value = 0;
+    });
+    for (let i = 0; i + 3 <= indices.length; i += 3) {
+      const face: any = { a: indices[i], b: indices[i + 1], c: indices[i + 2] };
+      const o = (i / 3) * 6;
+      if (o + 6 <= uvs.length) {
+        face.uvs = uvs.slice(o, o + 6);
+      }
+      mesh.faces.push(face);
+    }
+    return mesh;
+  }
+  private requestTexture = (id: number, lod: number) => {
+    this.request(0, id, lod);
+  }
+  private requestMesh = (id: number) => {
+    this.request(3, id);
+  }
+  // request asks for an asset, with the hash of the cached copy if there is one
+  private request = (type: number, id: number, lod: number = 0) => {
+    this.cache
+      .get(cacheKey(type, id, lod))
+      .then((entry) => this.send(type, id, lod, entry ? entry.hash : ''));
+  }
+  private send = (type: number, id: number, lod: number, hash: string) => {
+    this.rtc.sendMessage(
+      this.proto
+        .Request!.encode(
+          this.proto.Request!.fromObject({
+            type,
+            id,
+            lod,
+            hash,
+            encoding: type === 3 ? 2 : 0
+          })
+        )
+        .finish()
+    );
   }
 }
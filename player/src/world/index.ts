@@ -1,6 +1,6 @@
 import * as resources from '../resources';
 import Proto from '../proto';
-import RTC from '../connector';
+import { Connection } from '../connector';
 import * as THREE from 'three';
 import { assignUVs, Update } from './util';
 
@@ -61,8 +61,9 @@ export default class World {
   private camera: THREE.Camera;
   private currentLocation: [number, number, number];
   private proto: Proto;
-  private rtc: RTC;
-  constructor(s: THREE.Scene, c: THREE.Camera, p: Proto, r: RTC) {
+  private rtc: Connection;
+  private meshObjects: Map<number, Array<[THREE.Mesh, any]>>;
+  constructor(s: THREE.Scene, c: THREE.Camera, p: Proto, r: Connection) {
     this.chunks = new Map<
       number,
       Map<number, Map<number, [Chunk, Map<number, [Entity, EData]>]>>
@@ -73,6 +74,8 @@ export default class World {
     this.rtc = r;
     this.camera = c;
     this.resources = new resources.Manager(this.proto, this.rtc);
+    this.meshObjects = new Map<number, Array<[THREE.Mesh, any]>>();
+    this.resources.onMeshChanged(this.meshChanged);
   }
   public animate(delta: number) {
     const ents = this.retrieveCurrentChunk()![1];
@@ -135,6 +138,27 @@ export default class World {
       this.currentLocation[2]
     );
   }
+  public editTerrain = (build: boolean, position: THREE.Vector3) => {
+    this.rtc.sendMessage(
+      this.proto
+        .Request!.encode(
+          this.proto.Request!.fromObject({
+            type: 4,
+            edit: {
+              mode: build ? 1 : 0,
+              chunk: {
+                x: this.currentLocation[0],
+                y: this.currentLocation[1],
+                z: this.currentLocation[2]
+              },
+              position: { x: position.x, y: position.y, z: position.z },
+              radius: 2
+            }
+          })
+        )
+        .finish()
+    );
+  }
   public update = (u: Update) => {
     const ent = this.retrieveCurrentChunk()![1].get(
       u.entity.id || 0
@@ -158,6 +182,65 @@ export default class World {
     data.angularVelocity.y = u.rotationalVelocity.y;
     data.angularVelocity.z = u.rotationalVelocity.z;
   }
+  private meshChanged = (id: number, m: resources.Mesh) => {
+    (this.meshObjects.get(id) || []).forEach(([mesh, body]) => {
+      mesh.geometry.dispose();
+      mesh.geometry = this.buildGeometry(m, body);
+    });
+  }
+  private buildGeometry = (m: resources.Mesh, body: any) => {
+    const pregeo = new THREE.Geometry();
+    for (let i = 0; i < m.vertices.length; i += 3) {
+      pregeo.vertices.push(
+        new THREE.Vector3(m.vertices[i], m.vertices[i + 1], m.vertices[i + 2])
+      );
+    }
+    const uvs: any[][] = [[]];
+    let hasUvs = true;
+    const hasNormals = !!m.normals && m.normals.length === m.vertices.length;
+    const normal = (i: number) =>
+      new THREE.Vector3(
+        m.normals[i * 3],
+        m.normals[i * 3 + 1],
+        m.normals[i * 3 + 2]
+      );
+    if (m.faces && m.faces.length > 0) {
+      m.faces.forEach((face: any) => {
+        const a = face.a || 0;
+        const b = face.b || 0;
+        const c = face.c || 0;
+        pregeo.faces.push(
+          hasNormals
+            ? new THREE.Face3(a, b, c, [normal(a), normal(b), normal(c)])
+            : new THREE.Face3(a, b, c)
+        );
+        if (face.uvs && face.uvs.length > 0) {
+          const k = [];
+          for (let i = 0; i < face.uvs.length; i += 2) {
+            k.push(new THREE.Vector2(face.uvs[i], face.uvs[i + 1]));
+          }
+          uvs[0].push(k);
+        } else {
+          hasUvs = false;
+        }
+      });
+    } else {
+      return new THREE.BufferGeometry();
+    }
+    if (hasUvs) {
+      pregeo.faceVertexUvs = uvs;
+      pregeo.uvsNeedUpdate = true;
+    } else {
+      assignUVs(pregeo);
+    }
+    pregeo.computeFaceNormals();
+    if (body.flatNormals) {
+      pregeo.computeFlatVertexNormals();
+    } else if (!hasNormals) {
+      pregeo.computeVertexNormals();
+    }
+    return new THREE.BufferGeometry().fromGeometry(pregeo);
+  }
   private updateScene = () => {
     const c = this.retrieveCurrentChunk();
     c![0].entities.forEach((entity) => {
@@ -176,47 +259,7 @@ export default class World {
               body.meshID,
               body,
               (m: resources.Mesh, body: any) => {
-                const pregeo = new THREE.Geometry();
-                for (let i = 0; i < m.vertices.length; i += 3) {
-                  pregeo.vertices.push(
-                    new THREE.Vector3(
-                      m.vertices[i],
-                      m.vertices[i + 1],
-                      m.vertices[i + 2]
-                    )
-                  );
-                }
-                const uvs: any[][] = [[]];
-                let hasUvs = true;
-                if (m.faces && m.faces.length > 0) {
-                  m.faces.forEach((face: any) => {
-                    pregeo.faces.push(new THREE.Face3(face.a, face.b, face.c));
-                    if (face.uvs && face.uvs.length > 0) {
-                      const k = [];
-                      for (let i = 0; i < face.uvs.length; i += 2) {
-                        k.push(new THREE.Vector2(face.uvs[i], face.uvs[i + 1]));
-                      }
-                      uvs[0].push(k);
-                    } else {
-                      hasUvs = false;
-                    }
-                  });
-                } else {
-                  return;
-                }
-                if (hasUvs) {
-                  pregeo.faceVertexUvs = uvs;
-                  pregeo.uvsNeedUpdate = true;
-                } else {
-                  assignUVs(pregeo);
-                }
-                pregeo.computeFaceNormals();
-                if (body.flatNormals) {
-                  pregeo.computeFlatVertexNormals();
-                } else {
-                  pregeo.computeVertexNormals();
-                }
-                geometry = new THREE.BufferGeometry().fromGeometry(pregeo);
+                geometry = this.buildGeometry(m, body);
                 const mesh = new THREE.Mesh(
                   geometry,
                   new THREE.MeshBasicMaterial({ color: '#fff' })
@@ -243,6 +286,10 @@ export default class World {
                   }
                 );
                 e.add(mesh);
+                if (!this.meshObjects.has(body.meshID || 0)) {
+                  this.meshObjects.set(body.meshID || 0, []);
+                }
+                this.meshObjects.get(body.meshID || 0)!.push([mesh, body]);
               }
             );
             return;
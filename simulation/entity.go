@@ -7,24 +7,72 @@ import (
 )
 
 type entity struct {
-	VEnt     *pb.Entity
-	Body     ode.Body
-	Collider ode.Geom
+	VEnt      *pb.Entity
+	Body      ode.Body
+	Colliders []ode.Geom
+	//origin is where the chunk of the entity lies in the simulation, which is shared by all chunks
+	origin [3]float64
 }
 
-//AddFromVEnt creates a new Entity from a visual entity
-func (s *Simulation) AddFromVEnt(pe *pb.Entity) {
+//AddFromVEnt creates a new Entity from a visual entity, with a collider for each of its bodies.
+//The location of the entity is relative to origin, the origin of its chunk.
+func (s *Simulation) AddFromVEnt(pe *pb.Entity, origin [3]float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.add(pe, origin)
+}
+
+//AddStatic adds an entity that collides with others but is never moved by them, such as terrain
+func (s *Simulation) AddStatic(pe *pb.Entity, origin [3]float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.add(pe, origin).Body.SetKinematic(true)
+}
+
+//Remove destroys the body and colliders of an entity
+func (s *Simulation) Remove(pe *pb.Entity) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, e := range s.ents {
+		if e.VEnt != pe {
+			continue
+		}
+		for _, g := range e.Colliders {
+			g.Destroy()
+		}
+		e.Body.Destroy()
+		s.ents = append(s.ents[:i], s.ents[i+1:]...)
+		return
+	}
+}
+
+func (s *Simulation) add(pe *pb.Entity, origin [3]float64) *entity {
 	e := new(entity)
 	e.Body = s.world.NewBody()
 	e.VEnt = pe
-	e.Body.SetPosition(ode.V3(pe.Location.X, pe.Location.Y, pe.Location.Z))
+	e.origin = origin
+	e.Body.SetPosition(ode.V3(pe.Location.X+origin[0], pe.Location.Y+origin[1], pe.Location.Z+origin[2]))
+	q := rotation(pe.Rotation)
+	e.Body.SetQuaternion(ode.Quaternion{q[0], q[1], q[2], q[3]})
 	mass := ode.NewMass()
-	mass.SetBox(1, ode.V3(1, 1, 1))
-	mass.Adjust(1)
+	for _, body := range pe.Bodies {
+		for _, b := range s.colliders(body) {
+			g, m := s.newGeom(b, 1)
+			if g == nil {
+				continue
+			}
+			attach(g, e.Body, b)
+			mass.Add(m)
+			e.Colliders = append(e.Colliders, g)
+		}
+	}
+	if len(e.Colliders) == 0 {
+		mass.SetBox(1, ode.V3(1, 1, 1))
+		mass.Adjust(1)
+	}
 	e.Body.SetMass(mass)
-	e.Collider = s.space.NewBox(ode.V3(1, 1, 1))
-	e.Collider.SetBody(e.Body)
 	s.ents = append(s.ents, e)
 	e.Body.SetLinearVelocity(ode.V3(float64(pe.Velocity.X), float64(pe.Velocity.Y), float64(pe.Velocity.Z)))
 	e.Body.SetAngularVelocity(ode.V3(float64(pe.RotationalVelocity.X), float64(pe.RotationalVelocity.Y), float64(pe.RotationalVelocity.Z)))
+	return e
 }
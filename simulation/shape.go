@@ -0,0 +1,141 @@
+package simulation
+
+import (
+	"goworld/gen"
+	"goworld/pb"
+	"math"
+
+	"github.com/nobonobo/ode"
+)
+
+//MeshSource looks up the mesh and collision shape registered under a mesh ID
+type MeshSource func(id uint64) (*pb.Mesh, model.Shape)
+
+//yToZ turns ODE capsules and cylinders, which are built along Z, onto the Y axis
+var yToZ = [4]float64{math.Sqrt2 / 2, math.Sqrt2 / 2, 0, 0}
+
+func mulQuaternion(a, b [4]float64) [4]float64 {
+	return [4]float64{
+		a[0]*b[0] - a[1]*b[1] - a[2]*b[2] - a[3]*b[3],
+		a[0]*b[1] + a[1]*b[0] + a[2]*b[3] - a[3]*b[2],
+		a[0]*b[2] - a[1]*b[3] + a[2]*b[0] + a[3]*b[1],
+		a[0]*b[3] + a[1]*b[2] - a[2]*b[1] + a[3]*b[0],
+	}
+}
+
+//colliders returns the bodies b collides as: b itself, or the parts of the compound
+//shape of its mesh placed relative to b
+func (s *Simulation) colliders(b *pb.Body) []*pb.Body {
+	if b.Type != pb.Body_MESH || s.meshes == nil {
+		return []*pb.Body{b}
+	}
+	_, shape := s.meshes(b.MeshID)
+	if len(shape.Parts) == 0 {
+		return []*pb.Body{b}
+	}
+	q := rotation(b.Rotation)
+	var o [3]float64
+	if b.Offset != nil {
+		o = [3]float64{b.Offset.X, b.Offset.Y, b.Offset.Z}
+	}
+	parts := make([]*pb.Body, len(shape.Parts))
+	for i, p := range shape.Parts {
+		var po [3]float64
+		if p.Offset != nil {
+			po = [3]float64{p.Offset.X, p.Offset.Y, p.Offset.Z}
+		}
+		po = rotateVector(q, po)
+		r := mulQuaternion(q, rotation(p.Rotation))
+		parts[i] = &pb.Body{
+			Type:     p.Type,
+			Data:     p.Data,
+			Offset:   &pb.RelativeLocation{X: o[0] + po[0], Y: o[1] + po[1], Z: o[2] + po[2]},
+			Rotation: &pb.Rotation{W: float32(r[0]), X: float32(r[1]), Y: float32(r[2]), Z: float32(r[3])},
+		}
+	}
+	return parts
+}
+
+//rotation returns a rotation as a quaternion (w, x, y, z), the identity when it is unset
+func rotation(r *pb.Rotation) [4]float64 {
+	if r == nil || (r.X == 0 && r.Y == 0 && r.Z == 0 && r.W == 0) {
+		return [4]float64{1, 0, 0, 0}
+	}
+	return [4]float64{float64(r.W), float64(r.X), float64(r.Y), float64(r.Z)}
+}
+
+//rotateVector turns v by the quaternion q (w, x, y, z)
+func rotateVector(q [4]float64, v [3]float64) [3]float64 {
+	p := mulQuaternion(mulQuaternion(q, [4]float64{0, v[0], v[1], v[2]}), [4]float64{q[0], -q[1], -q[2], -q[3]})
+	return [3]float64{p[1], p[2], p[3]}
+}
+
+//newGeom creates the collider of a body together with its mass
+func (s *Simulation) newGeom(b *pb.Body, density float64) (ode.Geom, *ode.Mass) {
+	shape := model.Shape{Type: b.Type, Data: b.Data}
+	var mesh *pb.Mesh
+	if b.Type == pb.Body_MESH && s.meshes != nil {
+		mesh, shape = s.meshes(b.MeshID)
+	}
+	mass := ode.NewMass()
+	var g ode.Geom
+	d := shape.Data
+	switch {
+	case shape.Type == pb.Body_BOX && len(d) == 3:
+		g = s.space.NewBox(ode.V3(d[0], d[1], d[2]))
+		mass.SetBox(density, ode.V3(d[0], d[1], d[2]))
+	case shape.Type == pb.Body_SPHERE && len(d) == 1:
+		g = s.space.NewSphere(d[0])
+		mass.SetSphere(density, d[0])
+	case shape.Type == pb.Body_CAPSULE && len(d) == 2:
+		g = s.space.NewCapsule(d[0], d[1])
+		mass.SetCapsule(density, 2, d[0], d[1])
+	case shape.Type == pb.Body_CYLINDER && len(d) == 2:
+		g = s.space.NewCylinder(d[0], d[1])
+		mass.SetCylinder(density, 2, d[0], d[1])
+	case mesh != nil && len(mesh.Faces) > 0:
+		g = s.newTriMesh(mesh)
+		min, max := bounds(mesh)
+		mass.SetBox(density, ode.V3(math.Max(max[0]-min[0], 0.01), math.Max(max[1]-min[1], 0.01), math.Max(max[2]-min[2], 0.01)))
+	default:
+		return nil, nil
+	}
+	return g, mass
+}
+
+func (s *Simulation) newTriMesh(m *pb.Mesh) ode.Geom {
+	indices := make([]uint32, 0, len(m.Faces)*3)
+	for _, f := range m.Faces {
+		indices = append(indices, uint32(f.A), uint32(f.B), uint32(f.C))
+	}
+	data := ode.NewTriMeshData()
+	data.Build(ode.NewVertexList(len(m.Vertices)/3, m.Vertices...), ode.NewTriVertexIndexList(len(m.Faces), indices...))
+	return s.space.NewTriMesh(data)
+}
+
+func bounds(m *pb.Mesh) (min, max [3]float64) {
+	for i := 0; i < 3; i++ {
+		min[i], max[i] = math.Inf(1), math.Inf(-1)
+	}
+	for i, v := range m.Vertices {
+		min[i%3] = math.Min(min[i%3], v)
+		max[i%3] = math.Max(max[i%3], v)
+	}
+	return
+}
+
+//attach fixes a collider to the entity body at the offset and rotation of b
+func attach(g ode.Geom, body ode.Body, b *pb.Body) {
+	g.SetBody(body)
+	if b.Offset != nil {
+		g.SetOffsetPosition(ode.V3(b.Offset.X, b.Offset.Y, b.Offset.Z))
+	}
+	q := rotation(b.Rotation)
+	switch g.(type) {
+	case ode.Capsule, ode.Cylinder:
+		q = mulQuaternion(q, yToZ)
+	}
+	if q != [4]float64{1, 0, 0, 0} {
+		g.SetOffsetQuaternion(ode.Quaternion{q[0], q[1], q[2], q[3]})
+	}
+}
@@ -1,23 +1,31 @@
 package simulation
 
 import (
+	"sync"
+
 	"github.com/nobonobo/ode"
 )
 
-var stepSize = float64(1) / float64(60)
-
 //Simulation represents the simulation
 type Simulation struct {
-	world ode.World
-	space ode.Space
-	cgrp  ode.JointGroup
-	cb    func(data interface{}, obj1, obj2 ode.Geom)
-	ents  []*entity
+	world  ode.World
+	space  ode.Space
+	cgrp   ode.JointGroup
+	cb     func(data interface{}, obj1, obj2 ode.Geom)
+	ents   []*entity
+	meshes MeshSource
+	mutex  *sync.Mutex
+	//stepSize is the time in seconds each step advances the simulation by
+	stepSize float64
 }
 
-//InitializeSimulation initializes the simulation
-func InitializeSimulation() *Simulation {
+//InitializeSimulation initializes the simulation, resolving mesh bodies through meshes
+//and advancing by stepSize seconds each step
+func InitializeSimulation(meshes MeshSource, stepSize float64) *Simulation {
 	s := new(Simulation)
+	s.meshes = meshes
+	s.stepSize = stepSize
+	s.mutex = new(sync.Mutex)
 	ode.Init(0, ode.AllAFlag)
 	s.world = ode.NewWorld()
 	s.space = ode.NilSpace().NewSimpleSpace()
@@ -26,21 +34,33 @@ func InitializeSimulation() *Simulation {
 	return s
 }
 
-//Destroy destroys a simulation
+//Destroy destroys a simulation along with its bodies and shuts ODE down. The simulation
+//must not be stepped or changed afterwards.
 func (s *Simulation) Destroy() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cgrp.Destroy()
+	s.space.Destroy()
 	s.world.Destroy()
+	ode.Close()
 }
 
 //Step steps the simulation
 func (s *Simulation) Step() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	s.space.Collide(0, s.cb)
-	s.world.QuickStep(stepSize)
+	s.world.QuickStep(s.stepSize)
 	s.cgrp.Empty()
 	for _, e := range s.ents {
+		//kinematic entities such as terrain and prefabs never move, their state is left as given
+		if e.Body.Kinematic() {
+			continue
+		}
 		p := e.Body.Position()
-		e.VEnt.Location.X = p[0]
-		e.VEnt.Location.Y = p[1]
-		e.VEnt.Location.Z = p[2]
+		e.VEnt.Location.X = p[0] - e.origin[0]
+		e.VEnt.Location.Y = p[1] - e.origin[1]
+		e.VEnt.Location.Z = p[2] - e.origin[2]
 		lv := e.Body.LinearVelocity()
 		e.VEnt.Velocity.X = float32(lv[0])
 		e.VEnt.Velocity.Y = float32(lv[1])
@@ -49,10 +69,11 @@ func (s *Simulation) Step() {
 		e.VEnt.RotationalVelocity.X = float32(av[0])
 		e.VEnt.RotationalVelocity.Y = float32(av[1])
 		e.VEnt.RotationalVelocity.Z = float32(av[2])
+		//ODE orders quaternions w, x, y, z
 		q := e.Body.Quaternion()
-		e.VEnt.Rotation.X = float32(q[0])
-		e.VEnt.Rotation.Y = float32(q[1])
-		e.VEnt.Rotation.Z = float32(q[2])
-		e.VEnt.Rotation.W = float32(q[3])
+		e.VEnt.Rotation.W = float32(q[0])
+		e.VEnt.Rotation.X = float32(q[1])
+		e.VEnt.Rotation.Y = float32(q[2])
+		e.VEnt.Rotation.Z = float32(q[3])
 	}
 }
@@ -0,0 +1,219 @@
+package world
+
+import (
+	"fmt"
+	"goworld/assets"
+	"goworld/gen"
+	"goworld/logging"
+	"goworld/pb"
+	"math"
+
+	"github.com/go-errors/errors"
+)
+
+//biomeMaterials are the surface and subsurface materials of each biome
+var biomeMaterials = map[model.Biome][2]string{
+	model.Plains:    {"grass", "dirt"},
+	model.Forest:    {"grass", "dirt"},
+	model.Desert:    {"sand", "sand"},
+	model.Mountains: {"stone", "stone"},
+}
+
+//placementRules populate chunks with objects, each a prefab of the same name
+var placementRules = []model.PlacementRule{
+	{Kind: "tree", Biomes: []model.Biome{model.Forest}, Spacing: 6, Footprint: 1.5, MaxSlope: 0.8, Chance: 0.9},
+	{Kind: "tree", Biomes: []model.Biome{model.Plains}, Spacing: 12, Footprint: 1.5, MaxSlope: 0.8, Chance: 0.3},
+	{Kind: "bush", Biomes: []model.Biome{model.Plains, model.Forest}, Spacing: 7, Footprint: 0.8, MaxSlope: 1, Chance: 0.3},
+	{Kind: "pine", Biomes: []model.Biome{model.Mountains}, Spacing: 7, Footprint: 1.2, MaxSlope: 1.2, Chance: 0.5},
+	{Kind: "rock", Biomes: []model.Biome{model.Plains, model.Desert, model.Mountains}, Spacing: 9, Footprint: 1.2, MaxSlope: 2, Chance: 0.4},
+	{Kind: "cactus", Biomes: []model.Biome{model.Desert}, Spacing: 10, Footprint: 0.8, MaxSlope: 0.5, Chance: 0.5},
+	{Kind: "cabin", Biomes: []model.Biome{model.Plains, model.Forest}, Spacing: 48, Footprint: 6, MaxSlope: 0.3, Chance: 0.3},
+}
+
+//prefabPart is a static body of a prefab, placed relative to the ground where the prefab stands.
+//Its mesh is either one from the assets or one of Variants generated from a plant or building preset.
+type prefabPart struct {
+	Mesh     string
+	Plant    string
+	Building string
+	Variants int
+	Material string
+	Offset   [3]float64
+}
+
+//prefabLODs is the number of levels of detail generated for the plants and buildings of prefabs
+const prefabLODs = 2
+
+//prefabs are built on points of interest, named after their kind, and along roads
+var prefabs = map[string][]prefabPart{
+	"village": {
+		{Building: "house", Variants: 4, Material: "facade", Offset: [3]float64{-7, 0, -5}},
+		{Building: "house", Variants: 4, Material: "facade", Offset: [3]float64{6, 0, -6}},
+		{Building: "cottage", Variants: 3, Material: "facade", Offset: [3]float64{0, 0, 7}},
+	},
+	"camp": {
+		{Mesh: "tent", Material: "sand", Offset: [3]float64{-3, 1.5, 0}},
+		{Mesh: "tent", Material: "sand", Offset: [3]float64{3, 1.5, 1}},
+	},
+	"ruin": {
+		{Mesh: "pillar", Material: "stone", Offset: [3]float64{-3, 2, -3}},
+		{Mesh: "pillar", Material: "stone", Offset: [3]float64{3, 2, -3}},
+		{Mesh: "pillar", Material: "stone", Offset: [3]float64{-3, 2, 3}},
+	},
+	"tower": {
+		{Building: "tower", Variants: 2, Material: "facade"},
+	},
+	"road": {
+		{Mesh: "paving", Material: "stone", Offset: [3]float64{0, 0.15, 0}},
+	},
+	"tree": {
+		{Plant: "oak", Variants: 4, Material: "foliage"},
+	},
+	"pine": {
+		{Plant: "pine", Variants: 4, Material: "foliage"},
+	},
+	"bush": {
+		{Plant: "bush", Variants: 3, Material: "foliage"},
+	},
+	"rock": {
+		{Mesh: "rock", Material: "stone", Offset: [3]float64{0, 0.4, 0}},
+	},
+	"cactus": {
+		{Mesh: "cactus", Material: "leaves", Offset: [3]float64{0, 1.25, 0}},
+	},
+	"cabin": {
+		{Building: "cottage", Variants: 3, Material: "facade"},
+		{Mesh: "paving", Material: "stone", Offset: [3]float64{0, 0.15, -5}},
+	},
+}
+
+//surfaceChunk returns the vertical index of the chunk holding a height
+func (w *World) surfaceChunk(h float64) int64 {
+	return int64(math.Floor(h/w.Config.ChunkSize + 0.5))
+}
+
+//chunkPrefabs lists the prefabs standing on the ground of a chunk: the point of interest or
+//road the layout puts in its column and the objects placed in it, relative to the chunk
+func (w *World) chunkPrefabs(x int64, y int64, z int64) []*pb.StoredPrefab {
+	l := w.Layout
+	col := l.Column(x, z)
+	r := l.Region(l.RegionOf(x, z))
+	o := model.ChunkOrigin(x, y, z, l.ChunkSize)
+	ps := []*pb.StoredPrefab{}
+	add := func(kind string, at [3]float64, yaw float64) {
+		if w.surfaceChunk(at[1]) != y {
+			return
+		}
+		ps = append(ps, &pb.StoredPrefab{
+			Kind:     kind,
+			Position: &pb.RelativeLocation{X: at[0] - o[0], Y: at[1] - o[1], Z: at[2] - o[2]},
+			Yaw:      yaw,
+		})
+	}
+	switch {
+	case r.Point != nil && r.Point.Column == [2]int64{x, z}:
+		add(r.Point.Kind, r.Point.Position, 0)
+	case col.Road && !col.River:
+		add("road", [3]float64{o[0], col.Surface, o[2]}, 0)
+	}
+	for _, p := range w.Placer.Column(x, z) {
+		add(p.Kind, p.Position, p.Yaw)
+	}
+	return ps
+}
+
+//createPrefabs builds the prefabs of a chunk
+func (w *World) createPrefabs(c *Chunk, ps []*pb.StoredPrefab) {
+	for _, p := range ps {
+		if err := w.placePrefab(p, c); err != nil {
+			logging.Error(err)
+		}
+	}
+}
+
+//placePrefab adds the parts of a prefab to a chunk as entities, which are static once loaded
+func (w *World) placePrefab(s *pb.StoredPrefab, c *Chunk) error {
+	es, err := w.prefabEntities(s)
+	if err != nil {
+		return err
+	}
+	for _, e := range es {
+		c.addEntity(e)
+	}
+	return nil
+}
+
+//prefabEntities returns an entity for each part of a prefab, turned by its yaw about the vertical
+func (w *World) prefabEntities(s *pb.StoredPrefab) ([]*pb.Entity, error) {
+	kind, at, yaw := s.Kind, s.Position, s.Yaw
+	parts, ok := prefabs[kind]
+	if !ok {
+		return nil, errors.Errorf("unknown prefab %q", kind)
+	}
+	es := make([]*pb.Entity, 0, len(parts))
+	sin, cos := math.Sin(yaw), math.Cos(yaw)
+	variant := int(yaw / (2 * math.Pi) * 1024)
+	for i, p := range parts {
+		mesh, err := w.partMesh(p, variant+i)
+		if err != nil {
+			return nil, errors.Errorf("prefab %s: %v", kind, err)
+		}
+		material := w.Assets.MaterialByName(p.Material)
+		if material == nil {
+			return nil, errors.Errorf("prefab %s: unknown material %q", kind, p.Material)
+		}
+		e := &pb.Entity{
+			Location: &pb.RelativeLocation{
+				X: at.X + p.Offset[0]*cos + p.Offset[2]*sin,
+				Y: at.Y + p.Offset[1],
+				Z: at.Z - p.Offset[0]*sin + p.Offset[2]*cos,
+			},
+			Velocity:           &pb.Velocity{},
+			Rotation:           &pb.Rotation{Y: float32(math.Sin(yaw / 2)), W: float32(math.Cos(yaw / 2))},
+			RotationalVelocity: &pb.Velocity{},
+			Bodies: []*pb.Body{{
+				MeshID:   mesh.ID,
+				Material: material.ID,
+			}},
+		}
+		es = append(es, e)
+	}
+	return es, nil
+}
+
+//partMesh returns the mesh of a part of a prefab, generating the variant of its plant or building on first use
+func (w *World) partMesh(p prefabPart, variant int) (*assets.MeshAsset, error) {
+	if p.Plant == "" && p.Building == "" {
+		if m := w.Assets.MeshByName(p.Mesh); m != nil {
+			return m, nil
+		}
+		return nil, errors.Errorf("unknown mesh %q", p.Mesh)
+	}
+	v := variant % int(math.Max(1, float64(p.Variants)))
+	seed := w.Config.Seed + int64(v)
+	var name string
+	var mesh *pb.Mesh
+	var shape model.Shape
+	if p.Plant != "" {
+		name = fmt.Sprintf("plant/%s/%d", p.Plant, v)
+		if m := w.Assets.MeshByName(name); m != nil {
+			return m, nil
+		}
+		l, ok := model.Plants[p.Plant]
+		if !ok {
+			return nil, errors.Errorf("unknown plant %q", p.Plant)
+		}
+		mesh, shape = model.Plant(l, seed)
+	} else {
+		name = fmt.Sprintf("building/%s/%d", p.Building, v)
+		if m := w.Assets.MeshByName(name); m != nil {
+			return m, nil
+		}
+		b, ok := model.Buildings[p.Building]
+		if !ok {
+			return nil, errors.Errorf("unknown building %q", p.Building)
+		}
+		mesh, shape = model.Building(b, seed)
+	}
+	return w.Assets.Generate(name, mesh, shape, prefabLODs)
+}
@@ -1,18 +1,39 @@
 package world
 
-import "goworld/connector"
+import (
+	"goworld/connector"
+	"goworld/pb"
+	"time"
+)
 
 //Player represents a Player
 type Player struct {
-	Peer      *connector.Peer
+	//ID identifies the player across connections
+	ID        string
+	Peer      connector.Peer
 	AbsoluteX int64
 	AbsoluteY int64
 	AbsoluteZ int64
+	//Position and Rotation are where the player last said they were in their chunk
+	Position *pb.RelativeLocation
+	Rotation *pb.Rotation
+	Settings []*pb.Setting
+	//lastEdit is part of the state kept in the record of the player, so that reconnecting
+	//does not skip the interval between edits. The game has no items yet; their inventory
+	//will be kept along with it.
+	lastEdit time.Time
+	//moveBudget is how far the player may still move, refilled over time up to maxPlayerSpeed
+	moveBudget      float64
+	lastMove        time.Time
+	lastChunkChange time.Time
+	//grace ends the session of a player who disconnected unless they come back first
+	grace *time.Timer
 }
 
 //NewPlayer returns a new Player
-func NewPlayer(c *connector.Peer) *Player {
+func NewPlayer(c connector.Peer) *Player {
 	p := new(Player)
+	p.ID = c.ID()
 	p.Peer = c
 	return p
 }
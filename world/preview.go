@@ -0,0 +1,87 @@
+package world
+
+import (
+	"goworld/assets"
+	"goworld/config"
+	"goworld/gen"
+	"goworld/pb"
+	"image"
+	"math"
+
+	"github.com/go-errors/errors"
+)
+
+//previewLight is the direction previews are lit from
+var previewLight = [3]float64{0.4, 1, 0.25}
+
+//RenderPreview renders the chunks between two corners, both included, with the materials
+//and textures of the assets, either straight from above as a map, north up, or in perspective.
+//Chunks are taken from the chunk store, generating the missing ones, and edits are left out.
+func RenderPreview(a *assets.Registry, c *config.Config, from *pb.AbsoluteLocation, to *pb.AbsoluteLocation, top bool, width int) (image.Image, error) {
+	w := offline(a, c)
+	size := c.ChunkSize
+	lo, hi := bounds(from, to)
+	instances := []model.Instance{}
+	textures := map[uint64]image.Image{}
+	add := func(m *pb.Mesh, at [3]float64, r *pb.Rotation, material *assets.MaterialAsset) error {
+		in := model.Instance{Mesh: m, Position: at, Color: material.Material.Color}
+		if r != nil {
+			in.Rotation = [4]float64{float64(r.X), float64(r.Y), float64(r.Z), float64(r.W)}
+		}
+		if id := material.Material.TextureID; id != 0 {
+			if _, ok := textures[id]; !ok {
+				t := a.Texture(id)
+				if t == nil {
+					return errors.Errorf("material %s: unknown texture %d", material.Name, id)
+				}
+				img, err := t.Image()
+				if err != nil {
+					return err
+				}
+				textures[id] = img
+			}
+			in.Texture = textures[id]
+		}
+		instances = append(instances, in)
+		return nil
+	}
+	for x := lo[0]; x <= hi[0]; x++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+			for z := lo[2]; z <= hi[2]; z++ {
+				s := w.storedChunk(x, y, z)
+				o := model.ChunkOrigin(x, y, z, size)
+				name := w.terrainMaterial(x, y, z)
+				material := a.MaterialByName(name)
+				if material == nil {
+					return nil, errors.Errorf("unknown material %q", name)
+				}
+				if err := add(s.Terrain, o, nil, material); err != nil {
+					return nil, err
+				}
+				for _, p := range s.Prefabs {
+					es, err := w.prefabEntities(p)
+					if err != nil {
+						return nil, err
+					}
+					for _, e := range es {
+						l := e.Location
+						at := [3]float64{o[0] + l.X, o[1] + l.Y, o[2] + l.Z}
+						b := e.Bodies[0]
+						if err := add(a.Mesh(b.MeshID).Mesh, at, e.Rotation, a.Material(b.Material)); err != nil {
+							return nil, err
+						}
+					}
+				}
+			}
+		}
+	}
+	boxLo := model.ChunkOrigin(lo[0], lo[1], lo[2], size)
+	boxHi := model.ChunkOrigin(hi[0], hi[1], hi[2], size)
+	for i := range boxLo {
+		boxLo[i] -= size / 2
+		boxHi[i] += size / 2
+	}
+	camera, aspect := model.FitCamera(boxLo, boxHi, top)
+	height := int(math.Max(1, math.Round(float64(width)/aspect)))
+	return model.Render(instances, camera, width, height, previewLight)
+}
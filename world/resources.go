@@ -4,10 +4,10 @@ import (
 	"fmt"
 	"goworld/assets"
 	"goworld/connector"
+	"goworld/gen"
 	"goworld/logging"
 	"goworld/pb"
-	"io/ioutil"
-	"path"
+	"sort"
 	"sync"
 	"time"
 
@@ -27,11 +27,18 @@ func byteCountBinary(b int64) string {
 	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
 }
 
-var unloadAge = float64(30)
-
+//cacheData holds a texture followed by its mipmaps
 type cacheData struct {
 	LastRead time.Time
-	Data     []byte
+	Levels   [][]byte
+}
+
+func (c *cacheData) size() int64 {
+	n := int64(0)
+	for _, l := range c.Levels {
+		n += int64(len(l))
+	}
+	return n
 }
 
 var cacheSize = int64(0)
@@ -39,21 +46,89 @@ var cacheSize = int64(0)
 var pathCache = map[string]*cacheData{}
 var cacheMutex = new(sync.Mutex)
 
-func invalidateCache() {
+//invalidateCache prunes resources unread for longer than maxAge, then the least recently
+//read ones until the cache holds at most maxSize bytes, unless maxSize is 0
+func invalidateCache(maxAge time.Duration, maxSize int64) {
 	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
 	clen := len(pathCache)
+	total := cacheSize
 	mSize := int64(0)
-	defer cacheMutex.Unlock()
 	cleaned := 0
+	prune := func(k string, v *cacheData) {
+		mSize += v.size()
+		cacheSize -= v.size()
+		delete(pathCache, k)
+		cleaned++
+	}
 	for k, v := range pathCache {
-		if time.Since(v.LastRead).Minutes() > unloadAge {
-			mSize += int64(len(v.Data))
-			delete(pathCache, k)
-			cleaned++
+		if time.Since(v.LastRead) > maxAge {
+			prune(k, v)
+		}
+	}
+	if maxSize > 0 && cacheSize > maxSize {
+		keys := make([]string, 0, len(pathCache))
+		for k := range pathCache {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return pathCache[keys[i]].LastRead.Before(pathCache[keys[j]].LastRead)
+		})
+		for _, k := range keys {
+			if cacheSize <= maxSize {
+				break
+			}
+			prune(k, pathCache[k])
+		}
+	}
+	logging.L(fmt.Sprintf("Cache invalidation pruned %d (%s) of %d (%s) resources", cleaned, byteCountBinary(mSize), clen, byteCountBinary(total)))
+}
+
+func uncache(paths []string) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	for _, p := range paths {
+		if v, ok := pathCache[p]; ok {
+			cacheSize -= v.size()
+			delete(pathCache, p)
 		}
 	}
-	logging.L(fmt.Sprintf("Cache invalidation pruned %d (%s) of %d (%s) resources", cleaned, byteCountBinary(mSize), clen, byteCountBinary(cacheSize)))
-	cacheSize -= mSize
+}
+
+func (w *World) assetsChanged(paths []string, changes []assets.Change) {
+	uncache(paths)
+	for _, c := range changes {
+		logging.L(fmt.Sprintf("Reloaded %s %d", c.Kind, c.ID))
+		b, err := proto.Marshal(&pb.Response{
+			Type:      pb.Response_ASSET_CHANGED,
+			Id:        c.ID,
+			AssetType: c.Kind,
+		})
+		if err != nil {
+			continue
+		}
+		for _, p := range w.peers(nil) {
+			p.SendMessage(b)
+		}
+	}
+}
+
+var responseTypes = map[pb.Request_Type]pb.Response_Type{
+	pb.Request_TEXTURE:  pb.Response_TEXTURE,
+	pb.Request_AUDIO:    pb.Response_AUDIO,
+	pb.Request_MATERIAL: pb.Response_MATERIAL,
+	pb.Request_MESH:     pb.Response_MESH,
+}
+
+func (w *World) streamNotModified(m *pb.Request) []byte {
+	k, _ := proto.Marshal(&pb.Response{
+		Type:        responseTypes[m.Type],
+		Id:          m.Id,
+		Hash:        m.Hash,
+		NotModified: true,
+		Lod:         m.Lod,
+	})
+	return k
 }
 
 func min(a, b int) int {
@@ -63,26 +138,39 @@ func min(a, b int) int {
 	return b
 }
 
-func (w *World) streamTexture(id uint64) [][]byte {
-	var b []byte
-	var e error
-	p := path.Join("./assets/textures", fmt.Sprintf("%s.png", assets.Texture.ByID(id)))
+//streamTexture sends the requested level of a texture, level 0 being the full
+//texture and each next one a mipmap of half its size. Requests past the last
+//mipmap get the smallest one; the response tells which level was sent and how many there are.
+func (w *World) streamTexture(r *pb.Request) [][]byte {
+	id := r.Id
+	t := w.Assets.Texture(id)
+	if t == nil {
+		return [][]byte{}
+	}
+	p := t.Path
 	cacheMutex.Lock()
-	if v, ok := pathCache[p]; ok {
-		b = v.Data
+	v, ok := pathCache[p]
+	if ok {
 		v.LastRead = time.Now()
 	} else {
-		b, e = ioutil.ReadFile(p)
+		levels, e := t.Levels()
 		if e != nil {
+			logging.Error(e)
+			cacheMutex.Unlock()
 			return [][]byte{}
 		}
-		pathCache[p] = &cacheData{
-			Data:     b,
+		v = &cacheData{
+			Levels:   levels,
 			LastRead: time.Now(),
 		}
-		cacheSize += int64(len(b))
+		pathCache[p] = v
+		cacheSize += v.size()
 	}
 	cacheMutex.Unlock()
+	lod := uint32(min(int(r.Lod), len(v.Levels)-1))
+	levels := uint32(len(v.Levels))
+	b := v.Levels[lod]
+	h := t.LevelHash(lod)
 	if len(b) > connector.MaxStreamChunkSize {
 		ks := [][]byte{}
 		kt := uint64(len(b) / connector.MaxStreamChunkSize)
@@ -96,9 +184,12 @@ func (w *World) streamTexture(id uint64) [][]byte {
 				Texture: &pb.Texture{
 					Data: batch,
 				},
-				Parts: kt,
-				Part:  uint64(i / connector.MaxStreamChunkSize),
-				Id:    id,
+				Parts:  kt,
+				Part:   uint64(i / connector.MaxStreamChunkSize),
+				Id:     id,
+				Hash:   h,
+				Lod:    lod,
+				Levels: levels,
 			})
 			if e == nil {
 				ks = append(ks, p)
@@ -111,18 +202,37 @@ func (w *World) streamTexture(id uint64) [][]byte {
 		Texture: &pb.Texture{
 			Data: b,
 		},
-		Id: id,
+		Id:     id,
+		Hash:   h,
+		Lod:    lod,
+		Levels: levels,
 	})
 	return [][]byte{k}
 }
 
 func (w *World) streamMaterial(id uint64) []byte {
-	k, _ := proto.Marshal(&pb.Response{Id: id, Type: pb.Response_MATERIAL, Material: assets.Material.ByID(id)})
+	var m *pb.Material
+	var h string
+	if a := w.Assets.Material(id); a != nil {
+		m = a.Material
+		h = a.Hash
+	}
+	k, _ := proto.Marshal(&pb.Response{Id: id, Type: pb.Response_MATERIAL, Material: m, Hash: h})
 	return k
 }
 
-func (w *World) streamMesh(id uint64, meshes map[uint64]*pb.Mesh) [][]byte {
-	m, _ := proto.Marshal(meshes[id])
+func (w *World) streamMesh(r *pb.Request) [][]byte {
+	id, lod := r.Id, r.Lod
+	a := w.Assets.Mesh(id)
+	if a == nil {
+		return [][]byte{}
+	}
+	mesh, h := a.Level(lod)
+	m, err := model.Encode(mesh, r.Encoding, r.Deflate)
+	if err != nil {
+		logging.Error(err)
+		return [][]byte{}
+	}
 	if len(m) > connector.MaxStreamChunkSize {
 		ks := [][]byte{}
 		kt := uint64(len(m) / connector.MaxStreamChunkSize)
@@ -137,6 +247,10 @@ func (w *World) streamMesh(id uint64, meshes map[uint64]*pb.Mesh) [][]byte {
 				Parts:    kt,
 				Part:     uint64(i / connector.MaxStreamChunkSize),
 				Id:       id,
+				Hash:     h,
+				Lod:      lod,
+				Encoding: r.Encoding,
+				Deflate:  r.Deflate,
 			})
 			if e == nil {
 				ks = append(ks, p)
@@ -144,6 +258,6 @@ func (w *World) streamMesh(id uint64, meshes map[uint64]*pb.Mesh) [][]byte {
 		}
 		return ks
 	}
-	k, _ := proto.Marshal(&pb.Response{Id: id, Type: pb.Response_MESH, MeshData: m})
+	k, _ := proto.Marshal(&pb.Response{Id: id, Type: pb.Response_MESH, MeshData: m, Hash: h, Lod: lod, Encoding: r.Encoding, Deflate: r.Deflate})
 	return [][]byte{k}
 }
@@ -0,0 +1,283 @@
+package world
+
+import (
+	"encoding/base64"
+	"goworld/connector"
+	"goworld/gen"
+	"goworld/logging"
+	"goworld/pb"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	maxSettings     = 64
+	maxSettingKey   = 64
+	maxSettingValue = 1024
+	//maxPlayerSpeed is how far a player may move in a second, above what the client moves
+	//at 144 frames a second
+	maxPlayerSpeed = 25
+	//chunkChangeInterval is how long a player stays in a chunk at least
+	chunkChangeInterval = 500 * time.Millisecond
+)
+
+//recordPath names the file the record of a player is kept in
+func (w *World) recordPath(id string) string {
+	return filepath.Join(w.Config.Save, "players", base64.RawURLEncoding.EncodeToString([]byte(id))+".pb")
+}
+
+//persistent tells whether the record of a player outlives their session. Anonymous
+//players cannot come back as themselves, so theirs are not kept.
+func persistent(id string) bool {
+	return !strings.HasPrefix(id, connector.AnonymousPrefix)
+}
+
+//record returns what is kept of a player between sessions. playersMutex must be held.
+func (p *Player) record() *pb.PlayerRecord {
+	r := &pb.PlayerRecord{
+		Id:       p.ID,
+		Chunk:    &pb.AbsoluteLocation{X: p.AbsoluteX, Y: p.AbsoluteY, Z: p.AbsoluteZ},
+		Position: p.Position,
+		Rotation: p.Rotation,
+		Settings: p.Settings,
+		State:    &pb.PlayerState{},
+	}
+	if !p.lastEdit.IsZero() {
+		r.State.LastEdit = p.lastEdit.UnixNano() / int64(time.Millisecond)
+	}
+	return r
+}
+
+//restore puts a player back where their record left them
+func (p *Player) restore(r *pb.PlayerRecord) {
+	if r.Chunk != nil {
+		p.AbsoluteX, p.AbsoluteY, p.AbsoluteZ = r.Chunk.X, r.Chunk.Y, r.Chunk.Z
+	}
+	p.Position = r.Position
+	p.Rotation = r.Rotation
+	p.Settings = r.Settings
+	if ms := r.GetState().GetLastEdit(); ms != 0 {
+		p.lastEdit = time.Unix(0, ms*int64(time.Millisecond))
+	}
+}
+
+//readRecord returns the record of a player, or nil for a player never seen before
+func (w *World) readRecord(id string) (*pb.PlayerRecord, error) {
+	r := new(pb.PlayerRecord)
+	ok, err := readMessage(w.recordPath(id), r)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (w *World) writeRecord(r *pb.PlayerRecord) error {
+	if !persistent(r.Id) {
+		return nil
+	}
+	return writeMessage(w.recordPath(r.Id), r)
+}
+
+//resumeSession returns the session of a player who is still connected or left less than
+//the reconnection grace period ago, handing it over to a new peer, or starts one where
+//their record left them
+func (w *World) resumeSession(p connector.Peer) (k *Player, old connector.Peer, resumed bool) {
+	//the record is read before taking playersMutex so that other players do not wait on
+	//the disk, and left unused if the player turns out to have a session
+	var r *pb.PlayerRecord
+	if persistent(p.ID()) {
+		var err error
+		if r, err = w.readRecord(p.ID()); err != nil {
+			logging.Error(err)
+		}
+	}
+	playersMutex.Lock()
+	defer playersMutex.Unlock()
+	k, resumed = w.sessions[p.ID()]
+	if resumed {
+		if k.grace != nil {
+			k.grace.Stop()
+			k.grace = nil
+		}
+		delete(w.Players, k.Peer)
+	} else {
+		k = NewPlayer(p)
+		if r != nil {
+			k.restore(r)
+		}
+		w.sessions[k.ID] = k
+	}
+	old = k.Peer
+	k.Peer = p
+	w.Players[p] = k
+	return k, old, resumed
+}
+
+//leaveSession takes a peer that disconnected off its session, saving the record of the
+//player and ending the session unless they come back within the grace period. It returns
+//nil when a newer connection had already taken the session over.
+func (w *World) leaveSession(p connector.Peer) *Player {
+	playersMutex.Lock()
+	k, ok := w.Players[p]
+	if !ok {
+		playersMutex.Unlock()
+		return nil
+	}
+	delete(w.Players, p)
+	r := k.record()
+	k.grace = time.AfterFunc(time.Duration(w.Config.ReconnectGrace*float64(time.Second)), func() {
+		w.endSession(k)
+	})
+	playersMutex.Unlock()
+	if err := w.writeRecord(r); err != nil {
+		logging.Error(err)
+	}
+	return k
+}
+
+//endSession forgets a player whose grace period ran out, unless they came back meanwhile
+func (w *World) endSession(k *Player) {
+	playersMutex.Lock()
+	defer playersMutex.Unlock()
+	if _, connected := w.Players[k.Peer]; connected || w.sessions[k.ID] != k {
+		return
+	}
+	delete(w.sessions, k.ID)
+}
+
+//connected tells whether a peer still holds the session of a player, which a newer
+//connection of the same player takes over
+func (w *World) connected(k *Player, p connector.Peer) bool {
+	playersMutex.Lock()
+	defer playersMutex.Unlock()
+	return k.Peer == p
+}
+
+//playerChunk returns the chunk a player is in
+func (w *World) playerChunk(k *Player) [3]int64 {
+	playersMutex.Lock()
+	defer playersMutex.Unlock()
+	return [3]int64{k.AbsoluteX, k.AbsoluteY, k.AbsoluteZ}
+}
+
+//movePlayer records where a player says they are: in their chunk or, when the update
+//names one, a neighbouring chunk. Players are not simulated, so their word is only taken
+//for moves within maxPlayerSpeed, and for chunk changes no more often than chunkChangeInterval,
+//which bounds how fast they make the server generate chunks.
+func (w *World) movePlayer(k *Player, u *pb.Update) {
+	playersMutex.Lock()
+	from := [3]int64{k.AbsoluteX, k.AbsoluteY, k.AbsoluteZ}
+	to := from
+	if l := u.GetEntity().GetLocation(); l != nil {
+		to = [3]int64{l.X, l.Y, l.Z}
+	}
+	now := time.Now()
+	if !within(to, from, 1) || to != from && (u.Position == nil || now.Sub(k.lastChunkChange) < chunkChangeInterval) {
+		playersMutex.Unlock()
+		return
+	}
+	if u.Position != nil {
+		k.moveBudget = math.Min(k.moveBudget+maxPlayerSpeed*now.Sub(k.lastMove).Seconds(), maxPlayerSpeed)
+		k.lastMove = now
+		d := distance(w.absolute(from, k.Position), w.absolute(to, u.Position))
+		if d > k.moveBudget {
+			playersMutex.Unlock()
+			return
+		}
+		k.moveBudget -= d
+		k.Position = u.Position
+	}
+	if u.Rotation != nil {
+		k.Rotation = u.Rotation
+	}
+	if to != from {
+		k.lastChunkChange = now
+	}
+	k.AbsoluteX, k.AbsoluteY, k.AbsoluteZ = to[0], to[1], to[2]
+	p := k.Peer
+	playersMutex.Unlock()
+	if to == from {
+		return
+	}
+	w.leaveChunk(from, p)
+	b := w.loadChunk(to[0], to[1], to[2])
+	b.PlayersMutex.Lock()
+	b.Players[p] = k
+	b.PlayersMutex.Unlock()
+	//the interval runs from when the chunk is ready, so that players cannot queue up chunks
+	playersMutex.Lock()
+	k.lastChunkChange = time.Now()
+	playersMutex.Unlock()
+}
+
+//absolute returns where a position in a chunk is in the world
+func (w *World) absolute(k [3]int64, p *pb.RelativeLocation) [3]float64 {
+	o := model.ChunkOrigin(k[0], k[1], k[2], w.Config.ChunkSize)
+	return [3]float64{o[0] + p.GetX(), o[1] + p.GetY(), o[2] + p.GetZ()}
+}
+
+func distance(a [3]float64, b [3]float64) float64 {
+	return math.Sqrt((a[0]-b[0])*(a[0]-b[0]) + (a[1]-b[1])*(a[1]-b[1]) + (a[2]-b[2])*(a[2]-b[2]))
+}
+
+//leaveChunk takes a peer out of the players of a chunk, if it is loaded
+func (w *World) leaveChunk(k [3]int64, p connector.Peer) {
+	c := w.chunk(k)
+	if c == nil {
+		return
+	}
+	c.PlayersMutex.Lock()
+	delete(c.Players, p)
+	c.PlayersMutex.Unlock()
+}
+
+//updateSettings stores settings of a player, an empty value removing one
+func (w *World) updateSettings(k *Player, settings []*pb.Setting) {
+	playersMutex.Lock()
+	defer playersMutex.Unlock()
+	for _, s := range settings {
+		if s.Key == "" || len(s.Key) > maxSettingKey || len(s.Value) > maxSettingValue {
+			continue
+		}
+		kept := make([]*pb.Setting, 0, len(k.Settings)+1)
+		for _, o := range k.Settings {
+			if o.Key != s.Key {
+				kept = append(kept, o)
+			}
+		}
+		if s.Value != "" && len(kept) < maxSettings {
+			kept = append(kept, &pb.Setting{Key: s.Key, Value: s.Value})
+		}
+		k.Settings = kept
+	}
+}
+
+//sendRecord tells a player where they are and what their settings are, on every login
+func sendRecord(p connector.Peer, r *pb.PlayerRecord) {
+	b, err := proto.Marshal(&pb.Response{Type: pb.Response_PLAYER, Player: r})
+	if err != nil {
+		logging.Error(errors.Wrap(err, 0))
+		return
+	}
+	p.SendMessage(b)
+}
+
+//saveRecords saves the record of every player with a session, connected or not
+func (w *World) saveRecords() {
+	playersMutex.Lock()
+	rs := make([]*pb.PlayerRecord, 0, len(w.sessions))
+	for _, k := range w.sessions {
+		rs = append(rs, k.record())
+	}
+	playersMutex.Unlock()
+	for _, r := range rs {
+		if err := w.writeRecord(r); err != nil {
+			logging.Error(err)
+		}
+	}
+}
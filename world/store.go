@@ -0,0 +1,258 @@
+package world
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"goworld/assets"
+	"goworld/config"
+	"goworld/gen"
+	"goworld/logging"
+	"goworld/pb"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	//generatorVersion must be bumped whenever generation changes so that chunks stored before are generated again
+	generatorVersion = 1
+	progressInterval = 5 * time.Second
+)
+
+//storedPath names the file a generated chunk is kept in so that it is only generated once
+func (w *World) storedPath(x int64, y int64, z int64) string {
+	return filepath.Join(w.Config.Save, "chunks", fmt.Sprintf("%d_%d_%d.pb", x, y, z))
+}
+
+//readMessage reads a message from a file, telling whether the file exists
+func readMessage(p string, m proto.Message) (bool, error) {
+	b, err := ioutil.ReadFile(p)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, 0)
+	}
+	if err := proto.Unmarshal(b, m); err != nil {
+		return false, errors.Wrap(err, 0)
+	}
+	return true, nil
+}
+
+//writeMessage replaces a file with a message through a temporary file so a crash never leaves half of it
+func writeMessage(p string, m proto.Message) error {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return writeFile(p, b)
+}
+
+func writeFile(p string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return errors.Wrap(err, 0)
+	}
+	if err := ioutil.WriteFile(p+".tmp", b, 0644); err != nil {
+		return errors.Wrap(err, 0)
+	}
+	if err := os.Rename(p+".tmp", p); err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return nil
+}
+
+//readStored returns a chunk from the store, or nil when it is missing or was stored by
+//another version of the generator or for another seed or chunk size. Stored chunks are
+//gzipped as terrain meshes take megabytes.
+func (w *World) readStored(x int64, y int64, z int64) (*pb.StoredChunk, error) {
+	f, err := os.Open(w.storedPath(x, y, z))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	s := new(pb.StoredChunk)
+	if err := proto.Unmarshal(b, s); err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	c := w.Config
+	if s.Version != generatorVersion || s.Seed != c.Seed || s.ChunkSize != c.ChunkSize || s.Resolution != uint32(c.ChunkResolution) || s.Terrain == nil {
+		return nil, nil
+	}
+	return s, nil
+}
+
+func (w *World) writeStored(x int64, y int64, z int64, s *pb.StoredChunk) error {
+	b, err := proto.Marshal(s)
+	if err != nil {
+		return errors.Wrap(err, 0)
+	}
+	var buf bytes.Buffer
+	g := gzip.NewWriter(&buf)
+	if _, err := g.Write(b); err != nil {
+		return errors.Wrap(err, 0)
+	}
+	if err := g.Close(); err != nil {
+		return errors.Wrap(err, 0)
+	}
+	return writeFile(w.storedPath(x, y, z), buf.Bytes())
+}
+
+//newGenerator returns the layout, terrain and placer chunks are generated with
+func newGenerator(c *config.Config) (*model.Layout, model.Density, *model.Placer) {
+	l := model.NewLayout(c.Seed, c.ChunkSize, regionSize)
+	t := l.Terrain()
+	return l, t, model.NewPlacer(l, t, placementRules)
+}
+
+//offline returns a world that generates chunks without simulating them or serving players
+func offline(a *assets.Registry, c *config.Config) *World {
+	w := &World{Assets: a, Config: c}
+	w.Layout, w.Terrain, w.Placer = newGenerator(c)
+	return w
+}
+
+//generateChunk generates the unedited contents of a chunk: its terrain mesh with its
+//levels of detail and the prefabs standing on its ground
+func (w *World) generateChunk(x int64, y int64, z int64) *pb.StoredChunk {
+	c := w.Config
+	m, _ := model.Voxels(w.Terrain, x, y, z, c.ChunkSize, c.ChunkResolution)
+	return &pb.StoredChunk{
+		Version:    generatorVersion,
+		Seed:       c.Seed,
+		ChunkSize:  c.ChunkSize,
+		Resolution: uint32(c.ChunkResolution),
+		Terrain:    m,
+		Levels:     model.LODs(m, terrainLODs),
+		Prefabs:    w.chunkPrefabs(x, y, z),
+	}
+}
+
+//storedChunk returns the generated contents of a chunk from the store, generating and
+//storing them when they are missing or stale
+func (w *World) storedChunk(x int64, y int64, z int64) *pb.StoredChunk {
+	s, err := w.readStored(x, y, z)
+	if err != nil {
+		logging.Error(err)
+	}
+	if s != nil {
+		return s
+	}
+	s = w.generateChunk(x, y, z)
+	if err := w.writeStored(x, y, z, s); err != nil {
+		logging.Error(err)
+	}
+	return s
+}
+
+//Pregenerate generates every chunk between two corners, both included, into the chunk
+//store with a number of workers, logging progress as it goes. Chunks the current generator
+//already stored are skipped, so an interrupted run picks up where it stopped.
+func Pregenerate(c *config.Config, from *pb.AbsoluteLocation, to *pb.AbsoluteLocation, workers int) error {
+	lo, hi := bounds(from, to)
+	if workers < 1 {
+		workers = 1
+	}
+	w := offline(nil, c)
+	total := (hi[0] - lo[0] + 1) * (hi[1] - lo[1] + 1) * (hi[2] - lo[2] + 1)
+	var generated, skipped, failed int64
+	//columns are handed out whole so that workers do not lay out and place the same column at once
+	columns := make(chan [2]int64, workers)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k := range columns {
+				for y := lo[1]; y <= hi[1]; y++ {
+					if s, err := w.readStored(k[0], y, k[1]); err == nil && s != nil {
+						atomic.AddInt64(&skipped, 1)
+						continue
+					}
+					s := w.generateChunk(k[0], y, k[1])
+					if err := w.writeStored(k[0], y, k[1], s); err != nil {
+						logging.Error(err)
+						atomic.AddInt64(&failed, 1)
+						continue
+					}
+					atomic.AddInt64(&generated, 1)
+				}
+			}
+		}()
+	}
+	start := time.Now()
+	report := func() {
+		g, s, f := atomic.LoadInt64(&generated), atomic.LoadInt64(&skipped), atomic.LoadInt64(&failed)
+		rate := float64(g) / time.Since(start).Seconds()
+		eta := "unknown"
+		if rate > 0 {
+			eta = (time.Duration(float64(total-g-s-f)/rate) * time.Second).String()
+		}
+		logging.L(fmt.Sprintf("Pregenerated %d/%d chunks (%d already stored, %d failed), %.1f chunks/s, %s left",
+			g+s+f, total, s, f, rate, eta))
+	}
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				report()
+			case <-done:
+				return
+			}
+		}
+	}()
+	for x := lo[0]; x <= hi[0]; x++ {
+		for z := lo[2]; z <= hi[2]; z++ {
+			columns <- [2]int64{x, z}
+		}
+	}
+	close(columns)
+	wg.Wait()
+	close(done)
+	report()
+	if failed > 0 {
+		return errors.Errorf("%d chunks could not be stored", failed)
+	}
+	return nil
+}
+
+//bounds returns the lowest and highest chunk coordinates of a box between two corners
+func bounds(from *pb.AbsoluteLocation, to *pb.AbsoluteLocation) ([3]int64, [3]int64) {
+	lo := [3]int64{min64(from.X, to.X), min64(from.Y, to.Y), min64(from.Z, to.Z)}
+	hi := [3]int64{max64(from.X, to.X), max64(from.Y, to.Y), max64(from.Z, to.Z)}
+	return lo, hi
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,252 @@
+package world
+
+import (
+	"fmt"
+	"goworld/assets"
+	"goworld/gen"
+	"goworld/logging"
+	"goworld/pb"
+	"math"
+	"path/filepath"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	maxEditRadius = 4
+	//editRange is how many chunks away from their own a player can edit and still sees edits
+	editRange    = 1
+	editInterval = time.Second / 10
+)
+
+//editsPath names the file the edits made to a chunk are kept in, as a diff over the generated terrain
+func (w *World) editsPath(x int64, y int64, z int64) string {
+	return filepath.Join(w.Config.Save, "terrain", fmt.Sprintf("%d_%d_%d.pb", x, y, z))
+}
+
+func (w *World) readEdits(x int64, y int64, z int64) ([]*pb.VoxelEdit, error) {
+	t := new(pb.TerrainEdits)
+	if _, err := readMessage(w.editsPath(x, y, z), t); err != nil {
+		return nil, err
+	}
+	return t.Edits, nil
+}
+
+func (w *World) writeEdits(x int64, y int64, z int64, edits []*pb.VoxelEdit) error {
+	return writeMessage(w.editsPath(x, y, z), &pb.TerrainEdits{Edits: edits})
+}
+
+func (w *World) sculpt(e *pb.VoxelEdit) model.Sculpt {
+	o := model.ChunkOrigin(e.Chunk.X, e.Chunk.Y, e.Chunk.Z, w.Config.ChunkSize)
+	return model.Sculpt{
+		Centre: [3]float64{o[0] + e.Position.X, o[1] + e.Position.Y, o[2] + e.Position.Z},
+		Radius: e.Radius,
+		Build:  e.Mode == pb.VoxelEdit_BUILD,
+	}
+}
+
+//density returns the terrain of a chunk with its edits applied
+func (w *World) density(c *Chunk) model.Density {
+	s := make([]model.Sculpt, len(c.Edits))
+	for i, e := range c.Edits {
+		s[i] = w.sculpt(e)
+	}
+	return model.Sculpted(w.Terrain, s)
+}
+
+//createTerrain adds the voxel terrain of a chunk as a static entity, taking the stored
+//mesh unless the chunk was edited, in which case it is meshed again with its edits.
+//The entity is added even when the chunk is empty so that it can later be built into.
+func (w *World) createTerrain(x int64, y int64, z int64, c *Chunk, stored *pb.StoredChunk) {
+	edits, err := w.readEdits(x, y, z)
+	if err != nil {
+		logging.Error(err)
+	}
+	c.Edits = edits
+	var a *assets.MeshAsset
+	if len(edits) == 0 {
+		a, err = w.Assets.Restore(terrainName(x, y, z), stored.Terrain, model.Shape{Type: pb.Body_MESH}, stored.Levels)
+	} else {
+		m, s := model.Voxels(w.density(c), x, y, z, w.Config.ChunkSize, w.Config.ChunkResolution)
+		a, err = w.Assets.Generate(terrainName(x, y, z), m, s, terrainLODs)
+	}
+	if err != nil {
+		logging.Error(err)
+		return
+	}
+	c.Terrain = &pb.Entity{
+		Location:           &pb.RelativeLocation{},
+		Velocity:           &pb.Velocity{},
+		Rotation:           &pb.Rotation{W: 1},
+		RotationalVelocity: &pb.Velocity{},
+		Bodies: []*pb.Body{{
+			Type:     pb.Body_MESH,
+			MeshID:   a.ID,
+			Material: w.materialID(w.terrainMaterial(x, y, z)),
+		}},
+	}
+	c.addEntity(c.Terrain)
+}
+
+//materialID returns the ID of a material by name, falling back to stone when the manifest
+//lacks it, as edits and reloads may drop materials
+func (w *World) materialID(name string) uint64 {
+	if m := w.Assets.MaterialByName(name); m != nil {
+		return m.ID
+	}
+	logging.Error(errors.Errorf("unknown material %q", name))
+	if m := w.Assets.MaterialByName("stone"); m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+//terrainMaterial picks the material of a chunk by its biome and depth, the surface
+//material of the biome down to the chunk holding the ground, then its subsurface and then stone
+func (w *World) terrainMaterial(x int64, y int64, z int64) string {
+	c := w.Layout.Column(x, z)
+	m := biomeMaterials[c.Biome]
+	top := w.surfaceChunk(c.Surface)
+	switch {
+	case y >= top:
+		return m[0]
+	case y == top-1:
+		return m[1]
+	}
+	return "stone"
+}
+
+func terrainName(x int64, y int64, z int64) string {
+	return fmt.Sprintf("terrain/%d/%d/%d", x, y, z)
+}
+
+func within(a [3]int64, b [3]int64, r int64) bool {
+	for i := range a {
+		if a[i]-b[i] > r || b[i]-a[i] > r {
+			return false
+		}
+	}
+	return true
+}
+
+func (w *World) validateEdit(e *pb.VoxelEdit, p *Player) error {
+	if e == nil || e.Chunk == nil || e.Position == nil {
+		return errors.Errorf("edit without a location")
+	}
+	if !(e.Radius > 0 && e.Radius <= maxEditRadius) {
+		return errors.Errorf("edit radius %v out of range", e.Radius)
+	}
+	for _, v := range []float64{e.Position.X, e.Position.Y, e.Position.Z} {
+		if math.IsNaN(v) || math.Abs(v) > w.Config.ChunkSize/2 {
+			return errors.Errorf("edit position %v outside its chunk", e.Position)
+		}
+	}
+	if !within([3]int64{e.Chunk.X, e.Chunk.Y, e.Chunk.Z}, w.playerChunk(p), editRange) {
+		return errors.Errorf("edit in chunk %v out of reach", e.Chunk)
+	}
+	if !w.claimEdit(p) {
+		return errors.Errorf("edits too frequent")
+	}
+	return nil
+}
+
+//claimEdit starts the interval between edits of a player, unless their last edit is too recent
+func (w *World) claimEdit(p *Player) bool {
+	playersMutex.Lock()
+	defer playersMutex.Unlock()
+	if time.Since(p.lastEdit) < editInterval {
+		return false
+	}
+	p.lastEdit = time.Now()
+	return true
+}
+
+//affectedChunks returns every chunk whose mesh samples the density within reach of a sculpt
+func (w *World) affectedChunks(s model.Sculpt) [][3]int64 {
+	size := w.Config.ChunkSize
+	r := s.Reach() + model.ChunkMargin(size, w.Config.ChunkResolution)
+	var lo, hi [3]int64
+	for i := range s.Centre {
+		lo[i] = int64(math.Floor((s.Centre[i] - r + size/2) / size))
+		hi[i] = int64(math.Floor((s.Centre[i] + r + size/2) / size))
+	}
+	ks := [][3]int64{}
+	for x := lo[0]; x <= hi[0]; x++ {
+		for y := lo[1]; y <= hi[1]; y++ {
+			for z := lo[2]; z <= hi[2]; z++ {
+				ks = append(ks, [3]int64{x, y, z})
+			}
+		}
+	}
+	return ks
+}
+
+//editTerrain validates a voxel edit from a player, records it in every chunk it
+//reaches and remeshes the loaded ones. Unloaded chunks only have the edit saved
+//and pick it up when they are generated.
+func (w *World) editTerrain(e *pb.VoxelEdit, p *Player) {
+	if err := w.validateEdit(e, p); err != nil {
+		logging.L("Rejected terrain edit: " + err.Error())
+		return
+	}
+	w.terrainMutex.Lock()
+	defer w.terrainMutex.Unlock()
+	if w.isClosed() {
+		return
+	}
+	for _, k := range w.affectedChunks(w.sculpt(e)) {
+		c := w.chunk(k)
+		if c == nil {
+			edits, err := w.readEdits(k[0], k[1], k[2])
+			if err == nil {
+				err = w.writeEdits(k[0], k[1], k[2], append(edits, e))
+			}
+			if err != nil {
+				logging.Error(err)
+			}
+			continue
+		}
+		c.Edits = append(c.Edits, e)
+		if err := w.writeEdits(k[0], k[1], k[2], c.Edits); err != nil {
+			logging.Error(err)
+		}
+		w.remeshTerrain(k, c)
+	}
+}
+
+//remeshTerrain regenerates the terrain mesh of a chunk under its existing mesh ID,
+//swaps its collider and sends the new mesh to the players in range
+func (w *World) remeshTerrain(k [3]int64, c *Chunk) {
+	if c.Terrain == nil {
+		return
+	}
+	m, s := model.Voxels(w.density(c), k[0], k[1], k[2], w.Config.ChunkSize, w.Config.ChunkResolution)
+	a, err := w.Assets.Generate(terrainName(k[0], k[1], k[2]), m, s, terrainLODs)
+	if err != nil {
+		logging.Error(err)
+		return
+	}
+	w.onSimulation(func() {
+		w.Simulation.Remove(c.Terrain)
+		w.Simulation.AddStatic(c.Terrain, model.ChunkOrigin(k[0], k[1], k[2], w.Config.ChunkSize))
+	})
+	changed, err := proto.Marshal(&pb.Response{
+		Type:      pb.Response_ASSET_CHANGED,
+		Id:        a.ID,
+		AssetType: pb.Request_MESH,
+	})
+	if err != nil {
+		return
+	}
+	mesh := w.streamMesh(&pb.Request{Type: pb.Request_MESH, Id: a.ID, Encoding: pb.PackedMesh_QUANTIZED})
+	for _, p := range w.peers(func(p *Player) bool {
+		return within(k, [3]int64{p.AbsoluteX, p.AbsoluteY, p.AbsoluteZ}, editRange)
+	}) {
+		p.SendMessage(changed)
+		for _, b := range mesh {
+			p.SendMessage(b)
+		}
+	}
+}
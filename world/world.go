@@ -1,88 +1,232 @@
 package world
 
 import (
+	"fmt"
+	"goworld/assets"
+	"goworld/config"
 	"goworld/connector"
 	"goworld/gen"
 	"goworld/logging"
 	"goworld/pb"
 	"goworld/simulation"
+	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/go-errors/errors"
 	"github.com/golang/protobuf/proto"
 )
 
 //World represents a world
 type World struct {
+	//Chunks and LoadedChunks are guarded by chunksMutex and only changed on the simulation loop
 	Chunks       map[int64]map[int64]map[int64]*Chunk
 	LoadedChunks [][3]int64
-	Players      map[*connector.Peer]*Player
+	Players      map[connector.Peer]*Player
 	Simulation   *simulation.Simulation
-	Meshes       map[uint64]*pb.Mesh
+	Assets       *assets.Registry
+	Terrain      model.Density
+	Layout       *model.Layout
+	Placer       *model.Placer
+	Config       *config.Config
+	terrainMutex *sync.Mutex
+	chunksMutex  *sync.RWMutex
+	//jobs carries changes to the simulation and the chunks to the simulation loop
+	jobs chan func()
+	//sessions holds the players by ID, including those who disconnected within the grace period
+	sessions map[string]*Player
+	watcher  io.Closer
+	//stop ends the loops of the world, which loops waits on, and closed is set once it shuts down
+	stop   chan struct{}
+	loops  *sync.WaitGroup
+	closed int32
 }
 
+const (
+	terrainLODs = 3
+	regionSize  = 8
+)
+
 //Chunk represents a Chunk
 type Chunk struct {
 	Entities     []*pb.Entity
-	Players      map[*connector.Peer]*Player
+	Players      map[connector.Peer]*Player
 	PlayersMutex *sync.Mutex
 	Size         [2]float64
+	Terrain      *pb.Entity
+	Edits        []*pb.VoxelEdit
+	//moving holds the IDs of the entities the simulation moves, the only ones whose updates
+	//are sent as the rest are sent once with the chunk
+	moving []uint64
 }
 
 var playersMutex = new(sync.Mutex)
 
+//peers returns the peers of the connected players a filter accepts, or of all of them for a
+//nil filter, for sending to them without holding playersMutex as sends may block
+func (w *World) peers(filter func(*Player) bool) []connector.Peer {
+	playersMutex.Lock()
+	defer playersMutex.Unlock()
+	ps := make([]connector.Peer, 0, len(w.Players))
+	for p, k := range w.Players {
+		if filter == nil || filter(k) {
+			ps = append(ps, p)
+		}
+	}
+	return ps
+}
+
 func (w *World) streamChunk(x int64, y int64, z int64) ([]byte, error) {
 	c := w.loadChunk(x, y, z)
-	return proto.Marshal(&pb.Response{
-		Chunk: &pb.Chunk{
-			Location: &pb.AbsoluteLocation{
-				X: x,
-				Y: y,
-				Z: z,
+	var b []byte
+	var err error = errors.Errorf("the world shut down")
+	//the simulation moves the entities, they are marshalled between steps
+	w.onSimulation(func() {
+		b, err = proto.Marshal(&pb.Response{
+			Chunk: &pb.Chunk{
+				Location: &pb.AbsoluteLocation{
+					X: x,
+					Y: y,
+					Z: z,
+				},
+				Entities: c.Entities,
 			},
-			Entities: c.Entities,
-		},
-		Type: pb.Response_CHUNK,
+			Type: pb.Response_CHUNK,
+		})
 	})
+	if err != nil {
+		return nil, errors.Wrap(err, 0)
+	}
+	return b, nil
 }
 
 //New returns a new World
-func New() *World {
+func New(a *assets.Registry, c *config.Config) *World {
 	w := new(World)
+	w.Config = c
 	w.Chunks = make(map[int64]map[int64]map[int64]*Chunk)
-	w.Players = make(map[*connector.Peer]*Player)
-	w.Meshes = make(map[uint64]*pb.Mesh)
-	boxv, boxf := model.Box(1, 1, 1)
-	w.Meshes[1] = &pb.Mesh{
-		Vertices: boxv,
-		Faces:    boxf,
+	w.Players = make(map[connector.Peer]*Player)
+	w.sessions = make(map[string]*Player)
+	w.Assets = a
+	if watcher, err := w.Assets.Watch(w.assetsChanged); err != nil {
+		logging.Error(err)
+	} else {
+		w.watcher = watcher
 	}
-	w.loadChunk(0, 0, 0)
-	ticker := time.NewTicker(time.Minute * 5)
+	w.Layout, w.Terrain, w.Placer = newGenerator(c)
+	w.terrainMutex = new(sync.Mutex)
+	w.chunksMutex = new(sync.RWMutex)
+	w.jobs = make(chan func())
+	w.Simulation = simulation.InitializeSimulation(w.meshShape, 1/c.TickRate)
+	w.stop = make(chan struct{})
+	w.loops = new(sync.WaitGroup)
+	w.loops.Add(2)
+	ticker := time.NewTicker(time.Duration(c.CacheInterval * float64(time.Minute)))
 	go func() {
+		defer w.loops.Done()
+		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
-				invalidateCache()
+				invalidateCache(time.Duration(c.CacheAge*float64(time.Minute)), c.CacheSize)
+				w.unloadChunks()
+			case <-w.stop:
+				return
 			}
 		}
 	}()
-	w.Simulation = simulation.InitializeSimulation()
-	simtick := time.NewTicker(time.Second / 60)
-	w.CreateEntity()
-	w.CreateEntity2()
+	simtick := time.NewTicker(time.Duration(float64(time.Second) / c.TickRate))
+	sendtick := time.NewTicker(time.Duration(float64(time.Second) / c.SendRate))
 	go func() {
+		defer w.loops.Done()
+		defer simtick.Stop()
+		defer sendtick.Stop()
 		for {
 			select {
 			case <-simtick.C:
 				w.Simulation.Step()
+			case <-sendtick.C:
 				w.sendUpdates()
+			case f := <-w.jobs:
+				f()
+			case <-w.stop:
+				return
 			}
 		}
 	}()
+	w.loadChunk(0, 0, 0)
+	w.CreateEntity()
+	w.CreateEntity2()
 	return w
 }
 
+//onSimulation runs a change to the simulation or the chunks on the simulation loop,
+//between steps, and waits for it. Changes made after the world shut down are dropped.
+func (w *World) onSimulation(f func()) {
+	done := make(chan struct{})
+	select {
+	case w.jobs <- func() {
+		f()
+		close(done)
+	}:
+		<-done
+	case <-w.stop:
+	}
+}
+
+//Shutdown tells the players the server is going down, stops the loops of the world,
+//saves the edits of the loaded chunks and destroys the simulation. Requests arriving
+//afterwards are ignored.
+func (w *World) Shutdown() {
+	if !atomic.CompareAndSwapInt32(&w.closed, 0, 1) {
+		return
+	}
+	if b, err := proto.Marshal(&pb.Response{Type: pb.Response_SHUTDOWN}); err == nil {
+		for _, p := range w.peers(nil) {
+			p.SendMessage(b)
+		}
+	}
+	close(w.stop)
+	w.loops.Wait()
+	if w.watcher != nil {
+		w.watcher.Close()
+	}
+	w.flush()
+	w.saveRecords()
+	w.Simulation.Destroy()
+}
+
+//flush writes the edits of every loaded chunk again, waiting for edits being made to finish,
+//so that none is lost to a write that failed earlier
+func (w *World) flush() {
+	w.terrainMutex.Lock()
+	defer w.terrainMutex.Unlock()
+	w.chunksMutex.RLock()
+	defer w.chunksMutex.RUnlock()
+	saved := 0
+	for x, xs := range w.Chunks {
+		for y, ys := range xs {
+			for z, c := range ys {
+				if c == nil || len(c.Edits) == 0 {
+					continue
+				}
+				if err := w.writeEdits(x, y, z, c.Edits); err != nil {
+					logging.Error(err)
+					continue
+				}
+				saved++
+			}
+		}
+	}
+	logging.L(fmt.Sprintf("Saved the edits of %d chunks", saved))
+}
+
+//isClosed tells whether the world has shut down
+func (w *World) isClosed() bool {
+	return atomic.LoadInt32(&w.closed) != 0
+}
+
 func (w *World) assignChunk(x int64, y int64, z int64, c *Chunk) {
 	if w.Chunks[x] == nil {
 		w.Chunks[x] = make(map[int64]map[int64]*Chunk)
@@ -93,26 +237,99 @@ func (w *World) assignChunk(x int64, y int64, z int64, c *Chunk) {
 	w.Chunks[x][y][z] = c
 }
 
-func (w *World) loadChunk(x int64, y int64, z int64) *Chunk {
-	if w.Chunks[x] == nil {
-		w.Chunks[x] = make(map[int64]map[int64]*Chunk)
+func (w *World) unassignChunk(x int64, y int64, z int64) {
+	delete(w.Chunks[x][y], z)
+	if len(w.Chunks[x][y]) == 0 {
+		delete(w.Chunks[x], y)
 	}
-	if w.Chunks[x][y] == nil {
-		w.Chunks[x][y] = make(map[int64]*Chunk)
+	if len(w.Chunks[x]) == 0 {
+		delete(w.Chunks, x)
 	}
-	if w.Chunks[x][y][z] == nil {
-		w.createChunk(x, y, z)
+}
+
+//chunk returns a loaded chunk, or nil
+func (w *World) chunk(k [3]int64) *Chunk {
+	w.chunksMutex.RLock()
+	defer w.chunksMutex.RUnlock()
+	return w.Chunks[k[0]][k[1]][k[2]]
+}
+
+//loadChunk returns a chunk, loading it first if needed. Chunks are built one at a time,
+//and not while terrain is edited so that they miss no edit, then the simulation loop
+//adds their entities to the simulation and the chunk to the world between steps.
+func (w *World) loadChunk(x int64, y int64, z int64) *Chunk {
+	k := [3]int64{x, y, z}
+	if c := w.chunk(k); c != nil {
+		return c
 	}
-	a := true
-	for _, c := range w.LoadedChunks {
-		if c[0] != x || c[1] != y || c[2] != z {
-			a = false
+	w.terrainMutex.Lock()
+	defer w.terrainMutex.Unlock()
+	if c := w.chunk(k); c != nil {
+		return c
+	}
+	c := w.createChunk(x, y, z)
+	w.onSimulation(func() {
+		o := model.ChunkOrigin(x, y, z, w.Config.ChunkSize)
+		for _, e := range c.Entities {
+			w.Simulation.AddStatic(e, o)
 		}
+		w.chunksMutex.Lock()
+		w.assignChunk(x, y, z, c)
+		w.LoadedChunks = append(w.LoadedChunks, k)
+		w.chunksMutex.Unlock()
+	})
+	return c
+}
+
+//unloadChunks drops the loaded chunks no player is within editRange of, along with their
+//bodies and generated meshes. Their edits are saved as they are made and they are built
+//again when a player comes near. Chunks holding moving entities are kept, as those only
+//live in memory.
+func (w *World) unloadChunks() {
+	w.terrainMutex.Lock()
+	defer w.terrainMutex.Unlock()
+	unloaded := [][3]int64{}
+	w.onSimulation(func() {
+		//players are placed in their chunk before joining it, so none can join those dropped here
+		playersMutex.Lock()
+		near := make([][3]int64, 0, len(w.Players))
+		for _, p := range w.Players {
+			near = append(near, [3]int64{p.AbsoluteX, p.AbsoluteY, p.AbsoluteZ})
+		}
+		playersMutex.Unlock()
+		w.chunksMutex.Lock()
+		defer w.chunksMutex.Unlock()
+		loaded := w.LoadedChunks[:0]
+		for _, k := range w.LoadedChunks {
+			c := w.Chunks[k[0]][k[1]][k[2]]
+			if len(c.moving) > 0 || nearby(k, near) {
+				loaded = append(loaded, k)
+				continue
+			}
+			for _, e := range c.Entities {
+				w.Simulation.Remove(e)
+			}
+			w.unassignChunk(k[0], k[1], k[2])
+			unloaded = append(unloaded, k)
+		}
+		w.LoadedChunks = loaded
+	})
+	for _, k := range unloaded {
+		w.Assets.Evict(terrainName(k[0], k[1], k[2]))
 	}
-	if a {
-		w.LoadedChunks = append(w.LoadedChunks, [3]int64{x, y, z})
+	if len(unloaded) > 0 {
+		logging.L(fmt.Sprintf("Unloaded %d chunks", len(unloaded)))
 	}
-	return w.Chunks[x][y][z]
+}
+
+//nearby tells whether a chunk is within editRange of any of others
+func nearby(k [3]int64, others [][3]int64) bool {
+	for _, o := range others {
+		if within(k, o, editRange) {
+			return true
+		}
+	}
+	return false
 }
 
 func (c *Chunk) addEntity(e *pb.Entity) {
@@ -120,27 +337,57 @@ func (c *Chunk) addEntity(e *pb.Entity) {
 	c.Entities = append(c.Entities, e)
 }
 
-func (w *World) createChunk(x int64, y int64, z int64) {
+//addMoving adds an entity the simulation moves to a chunk
+func (c *Chunk) addMoving(e *pb.Entity) {
+	c.addEntity(e)
+	c.moving = append(c.moving, e.Id)
+}
+
+//createChunk builds a chunk from the store, leaving it out of the world and the simulation
+func (w *World) createChunk(x int64, y int64, z int64) *Chunk {
 	c := new(Chunk)
 	c.PlayersMutex = &sync.Mutex{}
-	c.Players = make(map[*connector.Peer]*Player)
-	w.assignChunk(x, y, z, c)
+	c.Players = make(map[connector.Peer]*Player)
+	s := w.storedChunk(x, y, z)
+	w.createTerrain(x, y, z, c, s)
+	w.createPrefabs(c, s.Prefabs)
+	return c
 }
 
 func (w *World) parseUpdate(d []byte, p *Player) {
 	u := new(pb.Update)
-	proto.Unmarshal(d, u)
+	if err := proto.Unmarshal(d, u); err != nil {
+		return
+	}
+	if u.Type == pb.Update_LOC {
+		w.movePlayer(p, u)
+	}
 }
 
 func (w *World) parseRequest(d []byte, p *Player) {
+	if w.isClosed() {
+		return
+	}
 	m := new(pb.Request)
 	err := proto.Unmarshal(d, m)
 	if err != nil {
-		logging.Error(err)
+		logging.Error(errors.Wrap(err, 0))
+		return
+	}
+	if m.Type == pb.Request_EDIT {
+		w.editTerrain(m.Edit, p)
+		return
+	}
+	if m.Type == pb.Request_SETTINGS {
+		w.updateSettings(p, m.Settings)
+		return
+	}
+	if m.Hash != "" && m.Hash == w.Assets.Hash(m.Type, m.Id, m.Lod) {
+		p.Peer.SendMessage(w.streamNotModified(m))
 		return
 	}
 	if m.Type == pb.Request_TEXTURE {
-		for _, m := range w.streamTexture(m.Id) {
+		for _, m := range w.streamTexture(m) {
 			p.Peer.SendMessage(m)
 		}
 		return
@@ -150,71 +397,94 @@ func (w *World) parseRequest(d []byte, p *Player) {
 		return
 	}
 	if m.Type == pb.Request_MESH {
-		for _, m := range w.streamMesh(m.Id, w.Meshes) {
+		for _, m := range w.streamMesh(m) {
 			p.Peer.SendMessage(m)
 		}
 		return
 	}
 }
 
-//AddPlayer adds a new player to the world
-func (w *World) AddPlayer(p *connector.Peer) {
-	logging.L("Player connected")
-	k := NewPlayer(p)
-	k.Peer = p
-	go func() {
-		playersMutex.Lock()
-		defer playersMutex.Unlock()
-		w.Players[p] = k
-	}()
-	k.AbsoluteX = 0
-	k.AbsoluteY = 0
-	k.AbsoluteZ = 0
-	c := w.loadChunk(0, 0, 0)
+//AddPlayer adds a player to the world where their record left them, or resumes their
+//session when they are still connected or left less than the reconnection grace period ago
+func (w *World) AddPlayer(p connector.Peer) {
+	if w.isClosed() {
+		return
+	}
+	k, old, resumed := w.resumeSession(p)
+	if resumed {
+		logging.L("Player " + k.ID + " reconnected")
+	} else {
+		logging.L("Player " + k.ID + " connected")
+	}
+	playersMutex.Lock()
+	r := k.record()
+	playersMutex.Unlock()
+	c := w.loadChunk(r.Chunk.X, r.Chunk.Y, r.Chunk.Z)
 	c.PlayersMutex.Lock()
-	defer c.PlayersMutex.Unlock()
-	c.Players[k.Peer] = k
-	l, err := w.streamChunk(0, 0, 0)
+	delete(c.Players, old)
+	c.Players[p] = k
+	c.PlayersMutex.Unlock()
+	sendRecord(p, r)
+	l, err := w.streamChunk(r.Chunk.X, r.Chunk.Y, r.Chunk.Z)
 	if err == nil {
 		p.SendMessage(l)
 	} else {
 		logging.Error(err)
 	}
 	p.OnMessage(func(d []byte) {
-		w.parseRequest(d, k)
+		if w.connected(k, p) {
+			w.parseRequest(d, k)
+		}
 	})
 	p.OnUpdate(func(d []byte) {
-		w.parseUpdate(d, k)
+		if w.connected(k, p) {
+			w.parseUpdate(d, k)
+		}
 	})
 }
 
-//RemovePlayer adds a new player to the world
-func (w *World) RemovePlayer(p *connector.Peer) {
-	logging.L("Player disconnected")
-	go func() {
-		playersMutex.Lock()
-		defer playersMutex.Unlock()
-		if _, ok := w.Chunks[w.Players[p].AbsoluteX][w.Players[p].AbsoluteY][w.Players[p].AbsoluteZ].Players[w.Players[p].Peer]; ok {
-			delete(w.Chunks[w.Players[p].AbsoluteX][w.Players[p].AbsoluteY][w.Players[p].AbsoluteZ].Players, w.Players[p].Peer)
-		}
-		if _, ok := w.Players[p]; ok {
-			delete(w.Players, p)
-		}
-	}()
+//RemovePlayer takes a player who disconnected out of the world, keeping their session
+//for the reconnection grace period
+func (w *World) RemovePlayer(p connector.Peer) {
+	k := w.leaveSession(p)
+	if k == nil {
+		return
+	}
+	logging.L("Player " + k.ID + " disconnected")
+	w.leaveChunk(w.playerChunk(k), p)
+}
+
+func (w *World) meshShape(id uint64) (*pb.Mesh, model.Shape) {
+	a := w.Assets.Mesh(id)
+	if a == nil {
+		return nil, model.Shape{}
+	}
+	return a.Mesh, a.Shape
+}
+
+//stoneBox returns the body of the entities created at startup, or nil if the manifest
+//lacks the box mesh or the stone material
+func (w *World) stoneBox() *pb.Body {
+	mesh, material := w.Assets.MeshByName("box"), w.Assets.MaterialByName("stone")
+	if mesh == nil || material == nil {
+		logging.Error(errors.Errorf("the box mesh and the stone material are needed for the startup entities"))
+		return nil
+	}
+	return &pb.Body{MeshID: mesh.ID, Material: material.ID, FlatNormals: true}
 }
 
 //CreateEntity creates an entity
 func (w *World) CreateEntity() {
+	b := w.stoneBox()
+	if b == nil {
+		return
+	}
 	p := &pb.Entity{
 		Location:           &pb.RelativeLocation{X: 0, Y: 0, Z: 0},
 		Velocity:           &pb.Velocity{X: 0.1, Y: 0, Z: 0},
 		Rotation:           &pb.Rotation{X: 0, Y: 0, Z: 0, W: 0},
 		RotationalVelocity: &pb.Velocity{X: 0.2, Y: 0, Z: -0.5},
-		Bodies: []*pb.Body{{
-			MeshID:      1,
-			Material:    1,
-			FlatNormals: true,
-		}},
+		Bodies:             []*pb.Body{b},
 		Lights: []*pb.Light{{
 			Position: &pb.RelativeLocation{
 				X: 2,
@@ -236,32 +506,50 @@ func (w *World) CreateEntity() {
 			Intensity: 0.25,
 		}},
 	}
-	w.Chunks[0][0][0].addEntity(p)
-	w.Simulation.AddFromVEnt(p)
+	c := w.loadChunk(0, 0, 0)
+	w.onSimulation(func() {
+		c.addMoving(p)
+		w.Simulation.AddFromVEnt(p, model.ChunkOrigin(0, 0, 0, w.Config.ChunkSize))
+	})
 }
 
 //CreateEntity2 creates an entity
 func (w *World) CreateEntity2() {
+	b := w.stoneBox()
+	if b == nil {
+		return
+	}
 	p := &pb.Entity{
 		Location:           &pb.RelativeLocation{X: 10, Y: 0, Z: 0},
 		Velocity:           &pb.Velocity{X: -2, Y: 0, Z: 0},
 		Rotation:           &pb.Rotation{X: 0, Y: 0, Z: 0, W: 0},
 		RotationalVelocity: &pb.Velocity{X: 0, Y: 1, Z: 0},
-		Bodies: []*pb.Body{{
-			MeshID:      1,
-			Material:    1,
-			FlatNormals: true,
-		}},
+		Bodies:             []*pb.Body{b},
 	}
-	w.Chunks[0][0][0].addEntity(p)
-	w.Simulation.AddFromVEnt(p)
+	c := w.loadChunk(0, 0, 0)
+	w.onSimulation(func() {
+		c.addMoving(p)
+		w.Simulation.AddFromVEnt(p, model.ChunkOrigin(0, 0, 0, w.Config.ChunkSize))
+	})
 }
 
+//sendUpdates sends the state of the moving entities of each loaded chunk to its players,
+//after releasing the locks as sends may block
 func (w *World) sendUpdates() {
+	type batch struct {
+		peers   []connector.Peer
+		updates [][]byte
+	}
+	batches := []batch{}
+	w.chunksMutex.RLock()
 	for _, c := range w.LoadedChunks {
 		chunk := w.Chunks[c[0]][c[1]][c[2]]
+		if len(chunk.moving) == 0 {
+			continue
+		}
 		updates := [][]byte{}
-		for i, e := range chunk.Entities {
+		for _, i := range chunk.moving {
+			e := chunk.Entities[i]
 			b, _ := proto.Marshal(&pb.Update{
 				Position: e.Location,
 				Rotation: e.Rotation,
@@ -271,7 +559,7 @@ func (w *World) sendUpdates() {
 						Y: c[1],
 						Z: c[2],
 					},
-					Id: uint64(i),
+					Id: i,
 				},
 				Velocity: &pb.Velocity{
 					X: e.Velocity.X,
@@ -286,11 +574,19 @@ func (w *World) sendUpdates() {
 			})
 			updates = append(updates, b)
 		}
-		for _, u := range updates {
-			for _, p := range chunk.Players {
-				go func(l *Player, k []byte) {
-					l.Peer.SendUpdate(k)
-				}(p, u)
+		chunk.PlayersMutex.Lock()
+		peers := make([]connector.Peer, 0, len(chunk.Players))
+		for p := range chunk.Players {
+			peers = append(peers, p)
+		}
+		chunk.PlayersMutex.Unlock()
+		batches = append(batches, batch{peers, updates})
+	}
+	w.chunksMutex.RUnlock()
+	for _, b := range batches {
+		for _, u := range b.updates {
+			for _, p := range b.peers {
+				p.SendUpdate(u)
 			}
 		}
 	}
@@ -0,0 +1,156 @@
+package world
+
+import (
+	"goworld/assets"
+	"goworld/config"
+	"goworld/connector"
+	"goworld/pb"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+func newTestWorld(t *testing.T) *World {
+	c := config.Default()
+	c.Assets = filepath.Join("..", "assets")
+	c.Save = t.TempDir()
+	a, err := assets.Load(c.Assets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := New(a, c)
+	t.Cleanup(w.Shutdown)
+	return w
+}
+
+//connect adds a player to a world over a loopback, returning the responses the client receives
+func connect(t *testing.T, w *World, player string) (*connector.Loopback, chan *pb.Response) {
+	l := connector.NewLoopback(player)
+	t.Cleanup(l.Close)
+	responses := make(chan *pb.Response, 256)
+	l.Client.OnMessage(func(d []byte) {
+		r := new(pb.Response)
+		if err := proto.Unmarshal(d, r); err != nil {
+			t.Error(err)
+			return
+		}
+		responses <- r
+	})
+	w.AddPlayer(l.Server)
+	return l, responses
+}
+
+//expect returns the next response, which must be of a type
+func expect(t *testing.T, responses chan *pb.Response, typ pb.Response_Type) *pb.Response {
+	t.Helper()
+	select {
+	case r := <-responses:
+		if r.Type != typ {
+			t.Fatalf("expected a %s response, got %s", typ, r.Type)
+		}
+		return r
+	case <-time.After(30 * time.Second):
+		t.Fatalf("no %s response", typ)
+	}
+	return nil
+}
+
+func send(t *testing.T, p connector.Peer, r *pb.Request) {
+	b, err := proto.Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SendMessage(b)
+}
+
+func TestAddPlayer(t *testing.T) {
+	w := newTestWorld(t)
+	l, responses := connect(t, w, "alice")
+	r := expect(t, responses, pb.Response_PLAYER)
+	if r.Player.Id != "alice" {
+		t.Fatalf("record of %q", r.Player.Id)
+	}
+	c := expect(t, responses, pb.Response_CHUNK).Chunk
+	if l := r.Player.Chunk; c.Location.X != l.GetX() || c.Location.Y != l.GetY() || c.Location.Z != l.GetZ() {
+		t.Fatalf("chunk %v streamed for a player in %v", c.Location, l)
+	}
+	if len(c.Entities) == 0 {
+		t.Fatal("empty chunk")
+	}
+	stone := w.Assets.MaterialByName("stone")
+	send(t, l.Client, &pb.Request{Type: pb.Request_MATERIAL, Id: stone.ID})
+	m := expect(t, responses, pb.Response_MATERIAL)
+	if m.Id != stone.ID || m.Material == nil {
+		t.Fatalf("material %d for %d", m.Id, stone.ID)
+	}
+	send(t, l.Client, &pb.Request{Type: pb.Request_MATERIAL, Id: stone.ID, Hash: m.Hash})
+	if r := expect(t, responses, pb.Response_MATERIAL); !r.NotModified {
+		t.Fatal("unchanged material sent again")
+	}
+}
+
+func TestMalformedRequest(t *testing.T) {
+	w := newTestWorld(t)
+	l, responses := connect(t, w, "alice")
+	expect(t, responses, pb.Response_PLAYER)
+	expect(t, responses, pb.Response_CHUNK)
+	l.Client.SendMessage([]byte{0xff, 0xff, 0xff})
+	stone := w.Assets.MaterialByName("stone")
+	send(t, l.Client, &pb.Request{Type: pb.Request_MATERIAL, Id: stone.ID})
+	expect(t, responses, pb.Response_MATERIAL)
+}
+
+func TestMovePlayer(t *testing.T) {
+	w := newTestWorld(t)
+	_, responses := connect(t, w, "alice")
+	expect(t, responses, pb.Response_PLAYER)
+	playersMutex.Lock()
+	k := w.sessions["alice"]
+	playersMutex.Unlock()
+	at := func(x, y, z int64) *pb.EntityID {
+		return &pb.EntityID{Location: &pb.AbsoluteLocation{X: x, Y: y, Z: z}}
+	}
+	w.movePlayer(k, &pb.Update{Position: &pb.RelativeLocation{X: 2 * maxPlayerSpeed}})
+	if k.Position.GetX() != 0 {
+		t.Fatal("moved faster than allowed")
+	}
+	w.movePlayer(k, &pb.Update{Position: &pb.RelativeLocation{X: 1}})
+	if k.Position.GetX() != 1 {
+		t.Fatal("move refused")
+	}
+	edge := w.Config.ChunkSize / 2
+	w.movePlayer(k, &pb.Update{Entity: at(3, 0, 0), Position: &pb.RelativeLocation{X: -edge}})
+	if w.playerChunk(k) != [3]int64{} {
+		t.Fatal("moved to a chunk that is not a neighbour")
+	}
+	k.Position = &pb.RelativeLocation{X: edge}
+	w.movePlayer(k, &pb.Update{Entity: at(1, 0, 0), Position: &pb.RelativeLocation{X: -edge + 1}})
+	if w.playerChunk(k) != [3]int64{1, 0, 0} {
+		t.Fatal("chunk change refused")
+	}
+	w.movePlayer(k, &pb.Update{Entity: at(0, 0, 0), Position: &pb.RelativeLocation{X: edge}})
+	if w.playerChunk(k) != [3]int64{1, 0, 0} {
+		t.Fatal("changed chunks faster than allowed")
+	}
+}
+
+func TestUnloadChunks(t *testing.T) {
+	w := newTestWorld(t)
+	_, responses := connect(t, w, "alice")
+	r := expect(t, responses, pb.Response_PLAYER)
+	at := [3]int64{r.Player.Chunk.GetX(), r.Player.Chunk.GetY(), r.Player.Chunk.GetZ()}
+	far := [3]int64{at[0] + 4, at[1], at[2]}
+	w.loadChunk(far[0], far[1], far[2])
+	w.unloadChunks()
+	if w.chunk(far) != nil {
+		t.Fatal("chunk no player is near still loaded")
+	}
+	if w.Assets.MeshByName(terrainName(far[0], far[1], far[2])) != nil {
+		t.Fatal("terrain of an unloaded chunk still registered")
+	}
+	if w.chunk(at) == nil || w.Assets.MeshByName(terrainName(at[0], at[1], at[2])) == nil {
+		t.Fatal("chunk of a player unloaded")
+	}
+}